@@ -0,0 +1,308 @@
+package bca
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+//maxAmountScale is the largest number of decimals an Amount can hold
+const maxAmountScale = 9
+
+//zeroDecimalCurrencies are the currencies BCA formats without decimals
+var zeroDecimalCurrencies = map[string]bool{
+	"JPY": true,
+	"KRW": true,
+	"VND": true,
+}
+
+//Amount represents an exact decimal amount of money, BCA sends and receives it as a JSON string such as "100000.10"
+type Amount struct {
+	units int64
+	scale int
+}
+
+//NewAmount returns the Amount units * 10^-scale, e.g. NewAmount(10000010, 2) is 100000.10
+func NewAmount(units int64, scale int) Amount {
+	if scale < 0 || scale > maxAmountScale {
+		panic(fmt.Sprintf("bca: amount scale %d out of range", scale))
+	}
+	return Amount{units: units, scale: scale}
+}
+
+//ParseAmount parses a decimal string such as "100000.10" or "-5" exactly, keeping its number of decimals
+func ParseAmount(s string) (Amount, error) {
+	str := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	if (intPart == "" && fracPart == "") || len(fracPart) > maxAmountScale {
+		return Amount{}, fmt.Errorf("bca: invalid amount %q", s)
+	}
+
+	var units int64
+	for _, ch := range intPart + fracPart {
+		if ch < '0' || ch > '9' {
+			return Amount{}, fmt.Errorf("bca: invalid amount %q", s)
+		}
+		if units > (math.MaxInt64-int64(ch-'0'))/10 {
+			return Amount{}, fmt.Errorf("bca: amount %q out of range", s)
+		}
+		units = units*10 + int64(ch-'0')
+	}
+	if negative {
+		units = -units
+	}
+	return Amount{units: units, scale: len(fracPart)}, nil
+}
+
+//MustParseAmount is like ParseAmount but panics when s is not a valid amount
+func MustParseAmount(s string) Amount {
+	amount, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+//CurrencyScale returns the number of decimals BCA uses for a currency, 0 for JPY and 2 for IDR and most others
+func CurrencyScale(currencyCode string) int {
+	if zeroDecimalCurrencies[strings.ToUpper(currencyCode)] {
+		return 0
+	}
+	return 2
+}
+
+//ParseCurrencyAmount parses s and formats it with the number of decimals of currencyCode,
+//it fails when s has more decimals than the currency allows
+func ParseCurrencyAmount(s, currencyCode string) (Amount, error) {
+	amount, err := ParseAmount(s)
+	if err != nil {
+		return Amount{}, err
+	}
+	return amount.ForCurrency(currencyCode)
+}
+
+//ForCurrency returns the amount with the number of decimals of currencyCode, it fails when that would lose precision
+func (a Amount) ForCurrency(currencyCode string) (Amount, error) {
+	return a.Rescale(CurrencyScale(currencyCode))
+}
+
+//Rescale returns the same amount with scale decimals, it fails when that would lose precision
+func (a Amount) Rescale(scale int) (Amount, error) {
+	if scale < 0 || scale > maxAmountScale {
+		return Amount{}, fmt.Errorf("bca: amount scale %d out of range", scale)
+	}
+	if scale >= a.scale {
+		units, ok := mulPow10(a.units, scale-a.scale)
+		if !ok {
+			return Amount{}, fmt.Errorf("bca: amount %s out of range with %d decimals", a, scale)
+		}
+		return Amount{units: units, scale: scale}, nil
+	}
+
+	pow := pow10(a.scale - scale)
+	if a.units%pow != 0 {
+		return Amount{}, fmt.Errorf("bca: amount %s has more than %d decimals", a, scale)
+	}
+	return Amount{units: a.units / pow, scale: scale}, nil
+}
+
+//Round returns the amount rounded half away from zero to scale decimals
+func (a Amount) Round(scale int) Amount {
+	if scale >= a.scale {
+		amount, err := a.Rescale(scale)
+		if err != nil {
+			panic(err)
+		}
+		return amount
+	}
+
+	pow := pow10(a.scale - scale)
+	units, rem := a.units/pow, a.units%pow
+	if rem >= pow/2+pow%2 {
+		units++
+	} else if -rem >= pow/2+pow%2 {
+		units--
+	}
+	return Amount{units: units, scale: scale}
+}
+
+//Add returns a + b, with the larger number of decimals of both
+func (a Amount) Add(b Amount) Amount {
+	a, b = align(a, b)
+	units := a.units + b.units
+	if (units > a.units) != (b.units > 0) {
+		panic("bca: amount overflow")
+	}
+	return Amount{units: units, scale: a.scale}
+}
+
+//Sub returns a - b, with the larger number of decimals of both
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(b.Neg())
+}
+
+//Mul returns a * n
+func (a Amount) Mul(n int64) Amount {
+	units := a.units * n
+	if a.units != 0 && (units/a.units != n || (a.units == -1 && n == math.MinInt64)) {
+		panic("bca: amount overflow")
+	}
+	return Amount{units: units, scale: a.scale}
+}
+
+//Neg returns -a
+func (a Amount) Neg() Amount {
+	return Amount{units: -a.units, scale: a.scale}
+}
+
+//Abs returns the absolute value of a
+func (a Amount) Abs() Amount {
+	if a.units < 0 {
+		return a.Neg()
+	}
+	return a
+}
+
+//Sign returns -1, 0 or 1 depending on the sign of a
+func (a Amount) Sign() int {
+	switch {
+	case a.units < 0:
+		return -1
+	case a.units > 0:
+		return 1
+	}
+	return 0
+}
+
+//Cmp returns -1, 0 or 1 when a is less than, equal to or greater than b, regardless of their number of decimals
+func (a Amount) Cmp(b Amount) int {
+	a, b = align(a, b)
+	switch {
+	case a.units < b.units:
+		return -1
+	case a.units > b.units:
+		return 1
+	}
+	return 0
+}
+
+//Equal reports whether a and b are the same amount, e.g. 5 and 5.00 are equal
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+//Float64 returns the nearest float64 of the amount, only meant for display and statistics
+func (a Amount) Float64() float64 {
+	return float64(a.units) / math.Pow10(a.scale)
+}
+
+//Units returns the amount in its smallest unit, e.g. 10000010 for 100000.10
+func (a Amount) Units() int64 {
+	return a.units
+}
+
+//Scale returns the number of decimals of the amount
+func (a Amount) Scale() int {
+	return a.scale
+}
+
+//IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a.units == 0
+}
+
+//String returns the amount with all of its decimals, e.g. "100000.10"
+func (a Amount) String() string {
+	units := a.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+
+	digits := fmt.Sprintf("%d", units)
+	digits = strings.TrimPrefix(digits, "-")
+	if a.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= a.scale {
+		digits = strings.Repeat("0", a.scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-a.scale] + "." + digits[len(digits)-a.scale:]
+}
+
+//MarshalJSON encodes the amount as a JSON string with all of its decimals. Requests sent to BCA encode their
+//amounts with the decimals of their currency instead, see ForCurrency
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+//UnmarshalJSON decodes the amount from a JSON string or number, an empty string or null is zero
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	str := string(data)
+	if strings.HasPrefix(str, `"`) {
+		if len(str) < 2 || !strings.HasSuffix(str, `"`) {
+			return errors.New("bca: invalid amount " + str)
+		}
+		str = str[1 : len(str)-1]
+	}
+	if strings.TrimSpace(str) == "" {
+		*a = Amount{}
+		return nil
+	}
+
+	amount, err := ParseAmount(str)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+//align returns a and b with the same number of decimals
+func align(a, b Amount) (Amount, Amount) {
+	var err error
+	switch {
+	case a.scale < b.scale:
+		a, err = a.Rescale(b.scale)
+	case b.scale < a.scale:
+		b, err = b.Rescale(a.scale)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return a, b
+}
+
+func pow10(n int) int64 {
+	pow := int64(1)
+	for i := 0; i < n; i++ {
+		pow *= 10
+	}
+	return pow
+}
+
+func mulPow10(units int64, n int) (int64, bool) {
+	for i := 0; i < n; i++ {
+		if units > math.MaxInt64/10 || units < math.MinInt64/10 {
+			return 0, false
+		}
+		units *= 10
+	}
+	return units, true
+}
@@ -0,0 +1,137 @@
+package bca
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantUnits int64
+		wantScale int
+		wantErr   bool
+	}{
+		{in: "100000.10", wantUnits: 10000010, wantScale: 2},
+		{in: "-5", wantUnits: -5},
+		{in: "+0.5", wantUnits: 5, wantScale: 1},
+		{in: " 12 ", wantUnits: 12},
+		{in: ".25", wantUnits: 25, wantScale: 2},
+		{in: "7.", wantUnits: 7},
+		{in: "0.000000001", wantUnits: 1, wantScale: 9},
+		{in: "0.0000000001", wantErr: true},
+		{in: "", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "9223372036854775808", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got.Units() != tt.wantUnits || got.Scale() != tt.wantScale {
+			t.Errorf("ParseAmount(%q) = %d scale %d, want %d scale %d", tt.in, got.Units(), got.Scale(), tt.wantUnits, tt.wantScale)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: `"100000.10"`, want: `"100000.10"`},
+		{in: `100000.1`, want: `"100000.1"`},
+		{in: `"-0.05"`, want: `"-0.05"`},
+		{in: `""`, want: `"0"`},
+		{in: `null`, want: `"0"`},
+		{in: `"abc"`, wantErr: true},
+		{in: `"12`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var amount Amount
+		err := json.Unmarshal([]byte(tt.in), &amount)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+
+		got, err := json.Marshal(amount)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Marshal(Unmarshal(%s)) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestAmountScale(t *testing.T) {
+	tests := []struct {
+		name    string
+		got     func() (Amount, error)
+		want    string
+		wantErr bool
+	}{
+		{name: "IDR adds decimals", got: func() (Amount, error) { return MustParseAmount("150000").ForCurrency("IDR") }, want: "150000.00"},
+		{name: "IDR keeps decimals", got: func() (Amount, error) { return MustParseAmount("1.5").ForCurrency("idr") }, want: "1.50"},
+		{name: "IDR drops zero decimals", got: func() (Amount, error) { return MustParseAmount("1.500").ForCurrency("IDR") }, want: "1.50"},
+		{name: "IDR rejects precision loss", got: func() (Amount, error) { return MustParseAmount("1.005").ForCurrency("IDR") }, wantErr: true},
+		{name: "JPY has no decimals", got: func() (Amount, error) { return MustParseAmount("1500.00").ForCurrency("JPY") }, want: "1500"},
+		{name: "JPY rejects precision loss", got: func() (Amount, error) { return MustParseAmount("1500.5").ForCurrency("JPY") }, wantErr: true},
+		{name: "rescale out of range", got: func() (Amount, error) { return MustParseAmount("1").Rescale(10) }, wantErr: true},
+		{name: "rescale overflow", got: func() (Amount, error) { return NewAmount(1<<62, 0).Rescale(2) }, wantErr: true},
+		{name: "round half up", got: func() (Amount, error) { return MustParseAmount("2.345").Round(2), nil }, want: "2.35"},
+		{name: "round half away from zero", got: func() (Amount, error) { return MustParseAmount("-2.345").Round(2), nil }, want: "-2.35"},
+		{name: "round down", got: func() (Amount, error) { return MustParseAmount("2.344").Round(2), nil }, want: "2.34"},
+		{name: "add aligns decimals", got: func() (Amount, error) { return MustParseAmount("1.5").Add(MustParseAmount("0.25")), nil }, want: "1.75"},
+		{name: "small amount", got: func() (Amount, error) { return NewAmount(5, 3), nil }, want: "0.005"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestAmountJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		request interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "fund transfer", request: FundTransferRequest{CurrencyCode: "IDR", Amount: MustParseAmount("100000")}, want: `"Amount":"100000.00"`},
+		{name: "domestic fund transfer", request: DomesticFundTransferRequest{CurrencyCode: "IDR", Amount: MustParseAmount("1.5")}, want: `"Amount":"1.50"`},
+		{name: "domestic fund transfer precision loss", request: DomesticFundTransferRequest{CurrencyCode: "IDR", Amount: MustParseAmount("1.005")}, wantErr: true},
+		{name: "FIRe to account", request: TeleTransferAccountRequest{TransactionDetails: TransactionAccountRequest{CurrencyID: "JPY", Amount: MustParseAmount("1500.00")}}, want: `"Amount":"1500"`},
+		{name: "FIRe cash transfer", request: TransactionTeleTransferCashTransferRequest{CurrencyID: "IDR", Amount: MustParseAmount("25000")}, want: `"Amount":"25000.00"`},
+		{name: "FIRe cancel", request: TransactionTeleTransferCancelCashTransferRequest{CurrencyID: "IDR", Amount: MustParseAmount("25000.1")}, want: `"Amount":"25000.10"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !strings.Contains(string(got), tt.want) {
+				t.Errorf("got %s, want it to contain %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package bca
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -19,32 +20,39 @@ import (
 	"github.com/juju/errors"
 )
 
-//API is an interface for making call to BCA API
+//API is the transport used by the service clients to invoke BCA API, it is implemented by *APIImplementation
 type API interface {
-	//Call(method, path string, body io.Reader, v interface{}) error
-	Call(method, path string, body []byte, v interface{}) error
-	CallRaw(method, path string, headers http.Header, body io.Reader, v interface{}) error
+	CallContext(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}) error
+	CallNonIdempotentContext(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}, resolve OutcomeResolver) error
+	CallRawContext(ctx context.Context, method, path, contentType string, headers http.Header, body io.Reader, v interface{}) error
 }
 
+var _ API = (*APIImplementation)(nil)
+
 //APIImplementation represents config that used for HTTP client needs
 type APIImplementation struct {
-	APIKey     string
-	APISecret  string
-	OriginHost string
-	URL        string
-	HTTPClient *http.Client
-	LogLevel   int
-	Logger     *log.Logger
+	APIKey      string
+	APISecret   string
+	OriginHost  string
+	URL         string
+	HTTPClient  *http.Client
+	RetryPolicy *RetryPolicy
+	Middlewares []Middleware
+	LogLevel    int
+	Logger      *log.Logger
 }
 
 //NewAPI is used to initialize new APIImplementation
-func NewAPI(cfg Config) APIImplementation {
-	return APIImplementation{
+func NewAPI(cfg Config, opts ...Option) APIImplementation {
+	api := APIImplementation{
 		APIKey:     cfg.APIKey,
 		APISecret:  cfg.APISecret,
 		URL:        cfg.URL,
 		OriginHost: cfg.OriginHost,
 		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+		// nil: a single attempt per call
+		RetryPolicy: cfg.RetryPolicy,
+		Middlewares: cfg.Middlewares,
 		// 0: no logging
 		// 1: errors only
 		// 2: errors + informational (default)
@@ -52,12 +60,46 @@ func NewAPI(cfg Config) APIImplementation {
 		LogLevel: cfg.LogLevel,
 		Logger:   log.New(os.Stderr, "", log.LstdFlags),
 	}
+
+	for _, opt := range opts {
+		opt(&api)
+	}
+	return api
 }
 
 //Call is the implementation for invoking BCA API with its authentication
 //func (c *APIImplementation) Call(method, path, accessToken string, body io.Reader, v interface{}) error {
 func (c *APIImplementation) Call(method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}) error {
+	return c.CallContext(context.Background(), method, path, accessToken, additionalHeader, body, v)
+}
+
+//CallContext is like Call but aborts the HTTP call when ctx is cancelled or its deadline is exceeded.
+//Failed attempts are retried according to RetryPolicy, so it must only be used for idempotent calls
+func (c *APIImplementation) CallContext(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}) error {
+	return c.retry(ctx, true, nil, v, func() error {
+		return c.call(ctx, method, path, accessToken, additionalHeader, body, v)
+	})
+}
+
+//CallNonIdempotentContext is like CallContext for calls that move money. A failed attempt is only retried
+//after resolve confirms that BCA did not apply it, without resolve the call is never retried
+func (c *APIImplementation) CallNonIdempotentContext(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}, resolve OutcomeResolver) error {
+	return c.retry(ctx, false, resolve, v, func() error {
+		return c.call(ctx, method, path, accessToken, additionalHeader, body, v)
+	})
+}
+
+//call signs and sends a single attempt, the timestamp and signature are generated again for each attempt
+func (c *APIImplementation) call(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}) error {
+	headers := c.signedHeaders(method, path, accessToken, additionalHeader, body)
 
+	//return c.CallRaw(method, path, "application/json", headers, body, v)
+	return c.callRaw(ctx, method, path, "application/json", headers, bytes.NewBuffer(body), v)
+
+}
+
+//signedHeaders returns the authentication and signature headers of a request, along with additionalHeader
+func (c *APIImplementation) signedHeaders(method, path, accessToken string, additionalHeader map[string]string, body []byte) http.Header {
 	headers := http.Header{}
 	headers.Add("Authorization", "Bearer "+accessToken)
 	headers.Add("Origin", c.OriginHost)
@@ -77,31 +119,57 @@ func (c *APIImplementation) Call(method, path, accessToken string, additionalHea
 		headers.Add(key, val)
 	}
 
-	//return c.CallRaw(method, path, "application/json", headers, body, v)
-	return c.CallRaw(method, path, "application/json", headers, bytes.NewBuffer(body), v)
-
+	return headers
 }
 
 //CallRaw is the implementation for invoking API without any wrapper
 func (c *APIImplementation) CallRaw(method, path, contentType string, headers http.Header, body io.Reader, v interface{}) error {
-	req, err := c.NewRequest(method, path, contentType, headers, body)
+	return c.CallRawContext(context.Background(), method, path, contentType, headers, body, v)
+}
+
+//CallRawContext is like CallRaw but aborts the HTTP call when ctx is cancelled or its deadline is exceeded.
+//Failed attempts are retried according to RetryPolicy
+func (c *APIImplementation) CallRawContext(ctx context.Context, method, path, contentType string, headers http.Header, body io.Reader, v interface{}) error {
+	if c.RetryPolicy.maxAttempts() == 1 || body == nil {
+		return c.retry(ctx, true, nil, v, func() error {
+			return c.callRaw(ctx, method, path, contentType, headers, body, v)
+		})
+	}
 
+	// The body is buffered so that it can be sent again
+	buf, err := ioutil.ReadAll(body)
 	if err != nil {
 		return err
 	}
+	return c.retry(ctx, true, nil, v, func() error {
+		return c.callRaw(ctx, method, path, contentType, headers, bytes.NewReader(buf), v)
+	})
+}
+
+func (c *APIImplementation) callRaw(ctx context.Context, method, path, contentType string, headers http.Header, body io.Reader, v interface{}) error {
+	req, err := c.NewRequestWithContext(ctx, method, path, contentType, headers, body)
 
-	return c.Do(req, v)
+	if err != nil {
+		return err
+	}
+
+	return c.DoContext(ctx, req, v)
 }
 
 //NewRequest is used to create new HTTP request of BCA API
 func (c *APIImplementation) NewRequest(method, path, contentType string, headers http.Header, body io.Reader) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, path, contentType, headers, body)
+}
+
+//NewRequestWithContext is like NewRequest but binds the HTTP request to ctx
+func (c *APIImplementation) NewRequestWithContext(ctx context.Context, method, path, contentType string, headers http.Header, body io.Reader) (*http.Request, error) {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
 
 	path = c.URL + path
 
-	req, err := http.NewRequest(method, path, body)
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
 	if err != nil {
 		if c.LogLevel > 0 {
 			c.Logger.Printf("Cannot create Stripe request: %v\n", err)
@@ -124,6 +192,13 @@ func (c *APIImplementation) NewRequest(method, path, contentType string, headers
 
 //Do is used by Call to execute BCA HTTP request and parse the response
 func (c *APIImplementation) Do(req *http.Request, v interface{}) error {
+	return c.DoContext(req.Context(), req, v)
+}
+
+//DoContext is like Do but executes the HTTP request under ctx, through the Middlewares chain
+func (c *APIImplementation) DoContext(ctx context.Context, req *http.Request, v interface{}) error {
+	req = req.WithContext(ctx)
+
 	logLevel := c.LogLevel
 	logger := c.Logger
 
@@ -131,42 +206,32 @@ func (c *APIImplementation) Do(req *http.Request, v interface{}) error {
 		logger.Println("Request ", req.Method, ": ", req.URL.Host, req.URL.Path)
 	}
 
-	start := time.Now()
-
-	res, err := c.HTTPClient.Do(req)
+	exchange, err := c.chain(c.roundTrip)(req)
 	if err != nil {
 		if logLevel > 0 {
-			logger.Println("Cannot send request: ", err)
+			logger.Println("Request failed: ", err)
 		}
 		return err
 	}
-	defer res.Body.Close()
 
 	if logLevel > 2 {
-		logger.Println("Completed in ", time.Since(start))
+		logger.Println("Completed in ", exchange.Duration)
+		logger.Println("BCA response: ", string(exchange.Body))
 	}
 
-	if err != nil {
-		if logLevel > 0 {
-			logger.Println("Request failed: ", err)
+	if bcaErr := responseError(exchange); bcaErr != nil {
+		if v != nil {
+			_ = json.Unmarshal(exchange.Body, v)
 		}
-		return err
-	}
 
-	resBody, err := ioutil.ReadAll(res.Body)
-	if err != nil {
 		if logLevel > 0 {
-			logger.Println("Cannot read response body: ", err)
+			logger.Println("BCA error: ", bcaErr)
 		}
-		return err
-	}
-
-	if logLevel > 2 {
-		logger.Println("BCA response: ", string(resBody))
+		return bcaErr
 	}
 
 	if v != nil {
-		if err = json.Unmarshal(resBody, v); err != nil {
+		if err = json.Unmarshal(exchange.Body, v); err != nil {
 			return err
 		}
 	}
@@ -174,6 +239,51 @@ func (c *APIImplementation) Do(req *http.Request, v interface{}) error {
 	return nil
 }
 
+//chain wraps the innermost RoundTrip with the Middlewares, the first one being the outermost
+func (c *APIImplementation) chain(roundTrip RoundTrip) RoundTrip {
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		roundTrip = c.Middlewares[i](roundTrip)
+	}
+	return roundTrip
+}
+
+//responseError returns the BCA error of an unsuccessful exchange, or nil when its status is 2xx
+func responseError(exchange *Exchange) *Error {
+	if exchange.StatusCode >= 200 && exchange.StatusCode <= 299 {
+		return nil
+	}
+
+	bcaErr := &Error{}
+	// The error body is not guaranteed to be JSON, e.g. when returned by a proxy
+	_ = json.Unmarshal(exchange.Body, bcaErr)
+	bcaErr.HTTPStatus = exchange.StatusCode
+	return bcaErr
+}
+
+//roundTrip is the innermost RoundTrip, it sends the request with HTTPClient and reads the whole response body
+func (c *APIImplementation) roundTrip(req *http.Request) (*Exchange, error) {
+	start := time.Now()
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Exchange{
+		Request:    req,
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       resBody,
+		Duration:   time.Since(start),
+	}, nil
+}
+
 func canonicalize(str string) string {
 	var b strings.Builder
 	b.Grow(len(str))
@@ -195,6 +305,11 @@ func sortQueryParam(urlStr string) (string, error) {
 	return u.String(), nil
 }
 
+//Signature returns the X-BCA-Signature of a request, path includes the query string
+func Signature(apiSecret, method, path, accessToken, requestBody, timestamp string) string {
+	return generateSignature(apiSecret, method, path, accessToken, requestBody, timestamp)
+}
+
 func generateSignature(apiSecret, method, path, accessToken, requestBody, timestamp string) string {
 	canonicalReqBody := canonicalize(requestBody)
 	h := sha256.New()
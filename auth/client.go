@@ -12,15 +12,16 @@ import (
 
 //Client is used to invoke BCA OAuth 2.0 API
 type Client struct {
-	Client       bca.APIImplementation
+	Client       bca.API
 	ClientID     string
 	ClientSecret string
 }
 
 //NewClient is used to initialize new auth.Client
 func NewClient(config bca.Config) Client {
+	api := bca.NewAPI(config)
 	return Client{
-		Client:       bca.NewAPI(config),
+		Client:       &api,
 		ClientID:     config.ClientID,
 		ClientSecret: config.ClientSecret,
 	}
@@ -37,7 +38,7 @@ func (c *Client) GetToken(ctx context.Context) (*bca.AuthToken, error) {
 	data.Add("grant_type", "client_credentials")
 
 	var authToken bca.AuthToken
-	if err := c.Client.CallRaw("POST", path, "application/x-www-form-urlencoded",
+	if err := c.Client.CallRawContext(ctx, "POST", path, "application/x-www-form-urlencoded",
 		header, strings.NewReader(data.Encode()), &authToken); err != nil {
 		return &authToken, err
 	}
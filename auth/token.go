@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//DefaultExpiryMargin is how long before its expiry a cached token is refreshed
+const DefaultExpiryMargin = 60 * time.Second
+
+//TokenSource caches the OAuth 2.0 token of a Client and refreshes it before it expires.
+//It is safe for concurrent use, only one refresh runs at a time.
+type TokenSource struct {
+	Client       Client
+	ExpiryMargin time.Duration
+
+	mu        sync.Mutex
+	refreshMu sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+//NewTokenSource is used to initialize new auth.TokenSource
+func NewTokenSource(config bca.Config) *TokenSource {
+	return &TokenSource{
+		Client:       NewClient(config),
+		ExpiryMargin: DefaultExpiryMargin,
+	}
+}
+
+//Token returns the cached access token, fetching a new one when it is missing or about to expire
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	if token, ok := ts.cached(); ok {
+		return token, nil
+	}
+
+	ts.refreshMu.Lock()
+	defer ts.refreshMu.Unlock()
+
+	// Another goroutine may have refreshed the token while we were waiting
+	if token, ok := ts.cached(); ok {
+		return token, nil
+	}
+
+	ptr_authToken, err := ts.Client.GetToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	if (*ptr_authToken).AccessToken == "" {
+		return "", errors.New("auth: empty access token in response")
+	}
+
+	// Short-lived tokens are refreshed halfway through their lifetime instead
+	lifetime := time.Duration((*ptr_authToken).ExpiresIn) * time.Second
+	margin := ts.ExpiryMargin
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+
+	ts.mu.Lock()
+	ts.token = (*ptr_authToken).AccessToken
+	ts.refreshAt = time.Now().Add(lifetime - margin)
+	ts.mu.Unlock()
+
+	return (*ptr_authToken).AccessToken, nil
+}
+
+//Invalidate drops the cached token so the next call to Token fetches a new one
+func (ts *TokenSource) Invalidate() {
+	ts.mu.Lock()
+	ts.token = ""
+	ts.refreshAt = time.Time{}
+	ts.mu.Unlock()
+}
+
+func (ts *TokenSource) cached() (string, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token == "" || !time.Now().Before(ts.refreshAt) {
+		return "", false
+	}
+	return ts.token, true
+}
+
+var (
+	_ bca.TokenSource      = (*TokenSource)(nil)
+	_ bca.TokenInvalidator = (*TokenSource)(nil)
+)
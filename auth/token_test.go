@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+)
+
+func testTokenSource(api bca.API) *TokenSource {
+	return &TokenSource{
+		Client:       Client{Client: api, ClientID: "client", ClientSecret: "secret"},
+		ExpiryMargin: DefaultExpiryMargin,
+	}
+}
+
+func TestTokenSourceToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiresIn  int
+		invalidate bool
+		wantFetch  int
+	}{
+		{name: "cached", expiresIn: 3600, wantFetch: 1},
+		{name: "expired", expiresIn: 0, wantFetch: 2},
+		{name: "invalidated", expiresIn: 3600, invalidate: true, wantFetch: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.Respond("POST", "/api/oauth/token",
+				bcatest.Response{Body: bca.AuthToken{AccessToken: "first", ExpiresIn: tt.expiresIn}},
+				bcatest.Response{Body: bca.AuthToken{AccessToken: "second", ExpiresIn: tt.expiresIn}},
+			)
+			ts := testTokenSource(recorder)
+
+			first, err := ts.Token(context.Background())
+			if err != nil || first != "first" {
+				t.Fatalf("Token() = %q, %v, want first", first, err)
+			}
+			if tt.invalidate {
+				ts.Invalidate()
+			}
+
+			want := "first"
+			if tt.wantFetch == 2 {
+				want = "second"
+			}
+			if second, err := ts.Token(context.Background()); err != nil || second != want {
+				t.Errorf("Token() = %q, %v, want %s", second, err, want)
+			}
+			if calls := len(recorder.Calls()); calls != tt.wantFetch {
+				t.Errorf("fetched %d tokens, want %d", calls, tt.wantFetch)
+			}
+		})
+	}
+}
+
+func TestTokenSourceEmptyToken(t *testing.T) {
+	recorder := bcatest.NewRecorder()
+	recorder.RespondJSON("POST", "/api/oauth/token", bca.AuthToken{ExpiresIn: 3600})
+
+	if _, err := testTokenSource(recorder).Token(context.Background()); err == nil {
+		t.Error("Token() = nil error, want an error for an empty access token")
+	}
+}
+
+//blockingAPI answers the token requests once release is closed
+type blockingAPI struct {
+	*bcatest.Recorder
+	release chan struct{}
+}
+
+func (b blockingAPI) CallRawContext(ctx context.Context, method, path, contentType string, headers http.Header, body io.Reader, v interface{}) error {
+	<-b.release
+	return b.Recorder.CallRawContext(ctx, method, path, contentType, headers, body, v)
+}
+
+func TestTokenSourceConcurrentRefresh(t *testing.T) {
+	recorder := bcatest.NewRecorder()
+	recorder.RespondJSON("POST", "/api/oauth/token", bca.AuthToken{AccessToken: "token", ExpiresIn: 3600})
+	api := blockingAPI{Recorder: recorder, release: make(chan struct{})}
+	ts := testTokenSource(api)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = ts.Token(context.Background())
+		}(i)
+	}
+	close(api.release)
+	wg.Wait()
+
+	for i, token := range tokens {
+		if token != "token" {
+			t.Errorf("Token() in goroutine %d = %q, want token", i, token)
+		}
+	}
+	if calls := len(recorder.Calls()); calls != 1 {
+		t.Errorf("fetched %d tokens, want 1", calls)
+	}
+}
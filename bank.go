@@ -0,0 +1,308 @@
+package bca
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ianeinser/bca-api-go/internal/levenshtein"
+)
+
+//bankDirectoryCSV is the directory of Indonesian banks shipped with the library. Its first line holds the version,
+//such as "#version:2026.10", followed by the columns Code, SWIFT, Name, Online, LLG and RTGS with Y/N flags
+//
+//go:embed banks.csv
+var bankDirectoryCSV string
+
+//Amount limits of domestic transfer types set by Bank Indonesia, in IDR
+var (
+	MaxOnlineTransferAmount = MustParseAmount("250000000")
+	MaxLLGTransferAmount    = MustParseAmount("1000000000")
+	// RTGS transfers must be above MinRTGSTransferAmount
+	MinRTGSTransferAmount = MustParseAmount("100000000")
+)
+
+//ErrNoTransferType is returned by TransferTypeFor when the bank supports no transfer type for the amount
+var ErrNoTransferType = errors.New("bca: no transfer type of the bank accepts the amount")
+
+//Bank represents an Indonesian bank of the bank directory
+type Bank struct {
+	// Code is the BeneficiaryBankCode BCA expects for domestic transfers
+	Code  string
+	SWIFT string
+	Name  string
+	// Online is whether the bank receives online (ONL and BI-FAST) transfers
+	Online bool
+	LLG    bool
+	RTGS   bool
+}
+
+//Supports reports whether the bank receives transfers of transferType
+func (b Bank) Supports(transferType TransferType) bool {
+	switch transferType {
+	case TransferTypeONL:
+		return b.Online
+	case TransferTypeLLG:
+		return b.LLG
+	case TransferTypeRTG:
+		return b.RTGS
+	}
+	return false
+}
+
+//BankDirectory represents a versioned list of Indonesian banks
+type BankDirectory struct {
+	Version string
+
+	banks   []Bank
+	byCode  map[string]int
+	bySWIFT map[string]int
+}
+
+var (
+	bankDirectoryMu sync.RWMutex
+	bankDirectory   *BankDirectory
+)
+
+//DefaultBankDirectory returns the directory used to validate requests, the embedded one unless SetBankDirectory was called
+func DefaultBankDirectory() *BankDirectory {
+	bankDirectoryMu.RLock()
+	d := bankDirectory
+	bankDirectoryMu.RUnlock()
+	if d != nil {
+		return d
+	}
+
+	bankDirectoryMu.Lock()
+	defer bankDirectoryMu.Unlock()
+	if bankDirectory == nil {
+		embedded, err := LoadBankDirectory(strings.NewReader(bankDirectoryCSV))
+		if err != nil {
+			panic(err)
+		}
+		bankDirectory = embedded
+	}
+	return bankDirectory
+}
+
+//SetBankDirectory replaces the directory used to validate requests, such as with a newer one loaded with LoadBankDirectory
+func SetBankDirectory(d *BankDirectory) {
+	bankDirectoryMu.Lock()
+	defer bankDirectoryMu.Unlock()
+	bankDirectory = d
+}
+
+//LoadBankDirectory reads a bank directory in the format of the embedded one
+func LoadBankDirectory(r io.Reader) (*BankDirectory, error) {
+	br := bufio.NewReader(r)
+	firstLine, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	firstLine = strings.TrimSpace(firstLine)
+	if !strings.HasPrefix(firstLine, "#version:") {
+		return nil, fmt.Errorf("bca: bank directory has no version line")
+	}
+
+	d := &BankDirectory{
+		Version: strings.TrimPrefix(firstLine, "#version:"),
+		byCode:  map[string]int{},
+		bySWIFT: map[string]int{},
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 6
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("bca: bank directory: %v", err)
+	}
+
+	for n, row := range rows {
+		// The first row is the header
+		if n == 0 {
+			continue
+		}
+		bank := Bank{
+			Code:   strings.ToUpper(strings.TrimSpace(row[0])),
+			SWIFT:  strings.ToUpper(strings.TrimSpace(row[1])),
+			Name:   strings.TrimSpace(row[2]),
+			Online: row[3] == "Y",
+			LLG:    row[4] == "Y",
+			RTGS:   row[5] == "Y",
+		}
+		if bank.Code == "" {
+			return nil, fmt.Errorf("bca: bank directory row %d has no code", n+1)
+		}
+		if _, ok := d.byCode[bank.Code]; ok {
+			return nil, fmt.Errorf("bca: bank directory has code %s twice", bank.Code)
+		}
+
+		d.byCode[bank.Code] = len(d.banks)
+		if bank.SWIFT != "" {
+			d.bySWIFT[bank.SWIFT] = len(d.banks)
+		}
+		d.banks = append(d.banks, bank)
+	}
+	return d, nil
+}
+
+//Banks returns every bank of the directory
+func (d *BankDirectory) Banks() []Bank {
+	return append([]Bank(nil), d.banks...)
+}
+
+//ByCode returns the bank of a BeneficiaryBankCode
+func (d *BankDirectory) ByCode(code string) (Bank, bool) {
+	i, ok := d.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Bank{}, false
+	}
+	return d.banks[i], true
+}
+
+//BySWIFT returns the bank of a SWIFT code, of 8 or 11 characters
+func (d *BankDirectory) BySWIFT(swift string) (Bank, bool) {
+	swift = strings.ToUpper(strings.TrimSpace(swift))
+	if len(swift) == 8 {
+		swift += "XXX"
+	}
+	i, ok := d.bySWIFT[swift]
+	if !ok {
+		return Bank{}, false
+	}
+	return d.banks[i], true
+}
+
+//Search returns up to limit banks whose name looks like name, the closest first. Words such as "Bank", "PT" and "Tbk"
+//are ignored, so "mandiri" finds Bank Mandiri, and acronyms such as "BRI" find the bank they stand for
+func (d *BankDirectory) Search(name string, limit int) []Bank {
+	query := bankNameWords(name)
+	if len(query) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var matches []scored
+	for i, bank := range d.banks {
+		if score := bankNameScore(query, bank.Name); score >= 0.6 {
+			matches = append(matches, scored{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	var banks []Bank
+	for _, match := range matches {
+		if limit > 0 && len(banks) == limit {
+			break
+		}
+		banks = append(banks, d.banks[match.index])
+	}
+	return banks
+}
+
+//TransferTypeFor returns the cheapest transfer type the bank of code receives for amount: ONL up to
+//MaxOnlineTransferAmount, then LLG up to MaxLLGTransferAmount, then RTG above MinRTGSTransferAmount
+func (d *BankDirectory) TransferTypeFor(code string, amount Amount) (TransferType, error) {
+	bank, ok := d.ByCode(code)
+	if !ok {
+		return "", fmt.Errorf("bca: unknown bank code %q", code)
+	}
+
+	for _, transferType := range []TransferType{TransferTypeONL, TransferTypeLLG, TransferTypeRTG} {
+		if bank.Supports(transferType) && TransferTypeAccepts(transferType, amount) {
+			return transferType, nil
+		}
+	}
+	return "", ErrNoTransferType
+}
+
+//TransferTypeLimits returns the amount limits of transferType: min is exclusive and max inclusive, a zero max has no
+//limit. ok is false for TransferTypeBCA and unknown transfer types
+func TransferTypeLimits(transferType TransferType) (min, max Amount, ok bool) {
+	switch transferType {
+	case TransferTypeONL:
+		return Amount{}, MaxOnlineTransferAmount, true
+	case TransferTypeLLG:
+		return Amount{}, MaxLLGTransferAmount, true
+	case TransferTypeRTG:
+		return MinRTGSTransferAmount, Amount{}, true
+	}
+	return Amount{}, Amount{}, false
+}
+
+//TransferTypeAccepts reports whether amount is within the TransferTypeLimits of transferType
+func TransferTypeAccepts(transferType TransferType, amount Amount) bool {
+	min, max, ok := TransferTypeLimits(transferType)
+	if !ok || amount.Cmp(min) <= 0 {
+		return false
+	}
+	return max.IsZero() || amount.Cmp(max) <= 0
+}
+
+var bankNameNoise = map[string]bool{"BANK": true, "PT": true, "TBK": true, "PERSERO": true, "NA": true}
+
+func bankNameWords(name string) []string {
+	var words []string
+	for _, word := range splitBankName(name) {
+		if !bankNameNoise[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func splitBankName(name string) []string {
+	name = strings.ToUpper(strings.Replace(name, ".", "", -1))
+	return strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name))
+}
+
+//bankNameScore is the share of the query words found in the name of a bank, each counting by how close its best match is
+func bankNameScore(query []string, name string) float64 {
+	words := bankNameWords(name)
+
+	var acronym strings.Builder
+	for _, word := range splitBankName(name) {
+		first, _ := utf8.DecodeRuneInString(word)
+		acronym.WriteRune(first)
+	}
+
+	total := 0.0
+	for _, q := range query {
+		// A prefix such as "Mandi" or the acronym of the full name counts as a full match
+		if q == acronym.String() {
+			total++
+			continue
+		}
+		best := 0.0
+		for _, word := range words {
+			score := levenshtein.Ratio(q, word)
+			if strings.HasPrefix(word, q) {
+				score = 1
+			}
+			if score > best {
+				best = score
+			}
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
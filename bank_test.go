@@ -0,0 +1,99 @@
+package bca
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBankDirectorySearch(t *testing.T) {
+	d, err := LoadBankDirectory(strings.NewReader("#version:test\n" +
+		"Code,SWIFT,Name,Online,LLG,RTGS\n" +
+		"BRINIDJA,BRINIDJAXXX,Bank Rakyat Indonesia,Y,Y,Y\n" +
+		"BMRIIDJA,BMRIIDJAXXX,Bank Mandiri,Y,Y,Y\n" +
+		"EKONIDJA,EKONIDJAXXX,Bank Ékonomi Raharja,N,Y,N\n"))
+	if err != nil {
+		t.Fatalf("LoadBankDirectory() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "mandiri", want: "BMRIIDJA"},
+		{name: "PT Bank Mandri Tbk", want: "BMRIIDJA"},
+		{name: "BRI", want: "BRINIDJA"},
+		{name: "BÉR", want: "EKONIDJA"},
+		{name: "Bank", want: ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if banks := d.Search(tt.name, 1); len(banks) > 0 {
+			got = banks[0].Code
+		}
+		if got != tt.want {
+			t.Errorf("Search(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if bank, ok := d.BySWIFT("ekonidja"); !ok || bank.Code != "EKONIDJA" {
+		t.Errorf("BySWIFT(ekonidja) = %+v, %v, want EKONIDJA", bank, ok)
+	}
+}
+
+func TestBankDirectoryTransferTypeFor(t *testing.T) {
+	d, err := LoadBankDirectory(strings.NewReader("#version:test\n" +
+		"Code,SWIFT,Name,Online,LLG,RTGS\n" +
+		"BRINIDJA,BRINIDJAXXX,Bank Rakyat Indonesia,Y,Y,Y\n" +
+		"EKONIDJA,EKONIDJAXXX,Bank Ekonomi Raharja,N,Y,N\n"))
+	if err != nil {
+		t.Fatalf("LoadBankDirectory() = %v", err)
+	}
+
+	tests := []struct {
+		code    string
+		amount  string
+		want    TransferType
+		wantErr bool
+	}{
+		{code: "BRINIDJA", amount: "250000000", want: TransferTypeONL},
+		{code: "BRINIDJA", amount: "250000000.01", want: TransferTypeLLG},
+		{code: "BRINIDJA", amount: "1000000000.01", want: TransferTypeRTG},
+		{code: "EKONIDJA", amount: "100", want: TransferTypeLLG},
+		{code: "EKONIDJA", amount: "1000000000.01", wantErr: true},
+		{code: "UNKNOWN", amount: "100", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := d.TransferTypeFor(tt.code, MustParseAmount(tt.amount))
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("TransferTypeFor(%s, %s) = %s, %v, want %s", tt.code, tt.amount, got, err, tt.want)
+		}
+	}
+	if _, err := d.TransferTypeFor("EKONIDJA", MustParseAmount("1000000000.01")); !errors.Is(err, ErrNoTransferType) {
+		t.Errorf("TransferTypeFor() = %v, want ErrNoTransferType", err)
+	}
+}
+
+func TestTransferTypeAccepts(t *testing.T) {
+	tests := []struct {
+		transferType TransferType
+		amount       string
+		want         bool
+	}{
+		{transferType: TransferTypeONL, amount: "0", want: false},
+		{transferType: TransferTypeONL, amount: "250000000", want: true},
+		{transferType: TransferTypeONL, amount: "250000000.01", want: false},
+		{transferType: TransferTypeLLG, amount: "1000000000", want: true},
+		{transferType: TransferTypeRTG, amount: "100000000", want: false},
+		{transferType: TransferTypeRTG, amount: "100000000.01", want: true},
+		{transferType: TransferTypeBCA, amount: "100", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := TransferTypeAccepts(tt.transferType, MustParseAmount(tt.amount)); got != tt.want {
+			t.Errorf("TransferTypeAccepts(%s, %s) = %v, want %v", tt.transferType, tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package bcasandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+type statementEntry struct {
+	date time.Time
+	bca.AccountStatement
+}
+
+type offlineStatement struct {
+	accountNumber string
+	startDate     time.Time
+	endDate       time.Time
+}
+
+type transfer struct {
+	request     bca.InquiryTransferStatusResponse
+	referenceID string
+	ppuNumber   string
+}
+
+func (s *Server) handleBanking(w http.ResponseWriter, r *http.Request, body []byte) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && match(segments, "banking", "v3", "corporates", "*", "accounts", "*"):
+		s.balanceInformation(w, segments[3], strings.Split(segments[5], ","))
+	case r.Method == http.MethodGet && match(segments, "banking", "v3", "corporates", "*", "accounts", "*", "statements"):
+		s.accountStatement(w, r, segments[3], segments[5])
+	case r.Method == http.MethodPost && match(segments, "banking", "corporates", "transfers"):
+		s.fundTransfer(w, body)
+	case r.Method == http.MethodPost && match(segments, "banking", "corporates", "transfers", "domestic"):
+		s.domesticFundTransfer(w, body)
+	case r.Method == http.MethodGet && match(segments, "banking", "corporates", "transfers", "status", "*"):
+		s.inquiryTransferStatus(w, r, segments[4])
+	case r.Method == http.MethodGet && match(segments, "banking", "corporates", "transfers", "v2", "domestic", "beneficiaries", "banks", "*", "accounts", "*"):
+		s.inquiryDomesticAccount(w, segments[7], segments[9])
+	case r.Method == http.MethodGet && match(segments, "banking", "offline", "corporates", "accounts", "*", "filestatements"):
+		s.accountStatementOffline(w, r, segments[4])
+	default:
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+}
+
+func (s *Server) balanceInformation(w http.ResponseWriter, corporateID string, accountNumbers []string) {
+	if corporateID != s.CorporateID {
+		writeError(w, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var response bca.BalanceInformationResponse
+	for _, accountNumber := range accountNumbers {
+		account, ok := s.accounts[accountNumber]
+		if !ok {
+			response.AccountDetailDataFailed = append(response.AccountDetailDataFailed, bca.BalanceFailedBalanceInformationResponse{
+				Indonesian:    errAccountUnknown.ErrorMessage.Indonesian,
+				English:       errAccountUnknown.ErrorMessage.English,
+				AccountNumber: accountNumber,
+			})
+			continue
+		}
+
+		response.AccountDetailDataSuccess = append(response.AccountDetailDataSuccess, bca.BalanceSuccessBalanceInformationResponse{
+			AccountNumber:    account.AccountNumber,
+			Currency:         account.Currency,
+			Balance:          account.Balance,
+			AvailableBalance: account.Balance,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) accountStatement(w http.ResponseWriter, r *http.Request, corporateID, accountNumber string) {
+	startDate, err1 := time.ParseInLocation("2006-01-02", r.URL.Query().Get("StartDate"), time.Local)
+	endDate, err2 := time.ParseInLocation("2006-01-02", r.URL.Query().Get("EndDate"), time.Local)
+	if corporateID != s.CorporateID || err1 != nil || err2 != nil || endDate.Before(startDate) || endDate.Sub(startDate) > 31*24*time.Hour {
+		writeError(w, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[accountNumber]
+	if !ok {
+		writeError(w, http.StatusBadRequest, errAccountUnknown)
+		return
+	}
+
+	response := bca.AccountStatementResponse{
+		Currency:  account.Currency,
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+	}
+	var entries []statementEntry
+	response.StartBalance, entries = s.statementPeriod(account, startDate, endDate)
+	for _, entry := range entries {
+		response.Data = append(response.Data, entry.AccountStatement)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+//statementPeriod returns the balance of account at startDate and the entries booked until endDate. s.mu must be held
+func (s *Server) statementPeriod(account *Account, startDate, endDate time.Time) (bca.Amount, []statementEntry) {
+	// The start balance is found by rolling back every entry booked since the start date
+	startBalance := account.Balance
+	var entries []statementEntry
+	for _, entry := range s.statements[account.AccountNumber] {
+		if entry.date.Before(startDate) {
+			continue
+		}
+		if entry.TransactionType == "C" {
+			startBalance = startBalance.Sub(entry.TransactionAmount)
+		} else {
+			startBalance = startBalance.Add(entry.TransactionAmount)
+		}
+		if entry.date.Before(endDate.AddDate(0, 0, 1)) {
+			entries = append(entries, entry)
+		}
+	}
+	return startBalance, entries
+}
+
+func (s *Server) fundTransfer(w http.ResponseWriter, body []byte) {
+	var request bca.FundTransferRequest
+	if !decode(w, body, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if request.CorporateID != s.CorporateID {
+		writeError(w, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	if _, ok := s.transfers[request.TransactionDate+"/"+request.TransactionID]; ok {
+		writeError(w, http.StatusBadRequest, bca.ErrDuplicateTransactionID)
+		return
+	}
+
+	source, ok1 := s.accounts[request.SourceAccountNumber]
+	beneficiary, ok2 := s.accounts[request.BeneficiaryAccountNumber]
+	if !ok1 || !ok2 {
+		writeError(w, http.StatusBadRequest, errAccountUnknown)
+		return
+	}
+	if source.Balance.Cmp(request.Amount) < 0 {
+		writeError(w, http.StatusBadRequest, bca.ErrInsufficientFunds)
+		return
+	}
+
+	s.book(source, "D", request.Amount, beneficiary.Name, request.Remark1)
+	s.book(beneficiary, "C", request.Amount, source.Name, request.Remark1)
+
+	s.transfers[request.TransactionDate+"/"+request.TransactionID] = &transfer{
+		request: bca.InquiryTransferStatusResponse{
+			TransactionID:            request.TransactionID,
+			TransactionDate:          request.TransactionDate,
+			TransferType:             bca.TransferTypeBCA,
+			SourceAccountNumber:      request.SourceAccountNumber,
+			BeneficiaryAccountNumber: request.BeneficiaryAccountNumber,
+			CurrencyCode:             request.CurrencyCode,
+			Amount:                   request.Amount,
+			StatusCode:               bca.TransferStatusSuccess,
+		},
+		referenceID: request.ReferenceID,
+	}
+
+	writeJSON(w, http.StatusOK, bca.FundTransferResponse{
+		TransactionID:   request.TransactionID,
+		TransactionDate: request.TransactionDate,
+		ReferenceID:     request.ReferenceID,
+		Status:          "Success",
+	})
+}
+
+func (s *Server) domesticFundTransfer(w http.ResponseWriter, body []byte) {
+	var request bca.DomesticFundTransferRequest
+	if !decode(w, body, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.transfers[request.TransactionDate+"/"+request.TransactionID]; ok {
+		writeError(w, http.StatusBadRequest, bca.ErrDuplicateTransactionID)
+		return
+	}
+
+	source, ok1 := s.accounts[request.SourceAccountNumber]
+	_, ok2 := s.domesticAccounts[request.BeneficiaryBankCode+"/"+request.BeneficiaryAccountNumber]
+	if !ok1 || !ok2 {
+		writeError(w, http.StatusBadRequest, errAccountUnknown)
+		return
+	}
+	if source.Balance.Cmp(request.Amount) < 0 {
+		writeError(w, http.StatusBadRequest, bca.ErrInsufficientFunds)
+		return
+	}
+
+	s.book(source, "D", request.Amount, request.BeneficiaryName, request.Remark1)
+
+	ppuNumber := "PPU" + request.TransactionID
+	s.transfers[request.TransactionDate+"/"+request.TransactionID] = &transfer{
+		request: bca.InquiryTransferStatusResponse{
+			TransactionID:            request.TransactionID,
+			TransactionDate:          request.TransactionDate,
+			TransferType:             request.TransferType,
+			SourceAccountNumber:      request.SourceAccountNumber,
+			BeneficiaryAccountNumber: request.BeneficiaryAccountNumber,
+			CurrencyCode:             request.CurrencyCode,
+			Amount:                   request.Amount,
+			StatusCode:               bca.TransferStatusSuccess,
+		},
+		referenceID: request.ReferenceID,
+		ppuNumber:   ppuNumber,
+	}
+
+	writeJSON(w, http.StatusOK, bca.DomesticFundTransferResponse{
+		TransactionID:   request.TransactionID,
+		TransactionDate: request.TransactionDate,
+		ReferenceID:     request.ReferenceID,
+		PPUNumber:       ppuNumber,
+	})
+}
+
+func (s *Server) inquiryTransferStatus(w http.ResponseWriter, r *http.Request, transactionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transfers[r.URL.Query().Get("TransactionDate")+"/"+transactionID]
+	if !ok || string(t.request.TransferType) != r.URL.Query().Get("TransferType") {
+		writeError(w, http.StatusNotFound, bca.ErrTransactionNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, t.request)
+}
+
+func (s *Server) inquiryDomesticAccount(w http.ResponseWriter, bankCode, accountNumber string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name, ok := s.domesticAccounts[bankCode+"/"+accountNumber]
+	if !ok {
+		writeError(w, http.StatusNotFound, errAccountUnknown)
+		return
+	}
+	writeJSON(w, http.StatusOK, bca.InquiryDomesticAccountResponse{
+		BeneficiaryBankCode:      bankCode,
+		BeneficiaryAccountNumber: accountNumber,
+		BeneficiaryAccountName:   name,
+	})
+}
+
+func (s *Server) accountStatementOffline(w http.ResponseWriter, r *http.Request, accountNumber string) {
+	startDate, err1 := time.ParseInLocation("2006-01-02", r.URL.Query().Get("StartDate"), time.Local)
+	endDate, err2 := time.ParseInLocation("2006-01-02", r.URL.Query().Get("EndDate"), time.Local)
+	if err1 != nil || err2 != nil || endDate.Before(startDate) {
+		writeError(w, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[accountNumber]; !ok {
+		writeError(w, http.StatusBadRequest, errAccountUnknown)
+		return
+	}
+
+	s.offlineSeq++
+	requestID := fmt.Sprintf("REQ%s%s%04d", accountNumber, time.Now().Format("20060102150405"), s.offlineSeq)
+	s.offlineStatements[requestID] = &offlineStatement{
+		accountNumber: accountNumber,
+		startDate:     startDate,
+		endDate:       endDate,
+	}
+	writeJSON(w, http.StatusOK, bca.AccountStatementOfflineResponse{
+		RequestID:  requestID,
+		ResponseWS: "0",
+	})
+}
+
+//OpenStatementFile returns the bulk statement file of an AccountStatementOffline request in MT940 format, one
+//message per day. It makes the sandbox a business.OfflineStatementSource that delivers files at once
+func (s *Server) OpenStatementFile(ctx context.Context, accountNumber, requestID string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statement, ok := s.offlineStatements[requestID]
+	if !ok || statement.accountNumber != accountNumber {
+		return nil, fmt.Errorf("bcasandbox: unknown offline statement %s of account %s", requestID, accountNumber)
+	}
+
+	account := s.accounts[accountNumber]
+	balance, entries := s.statementPeriod(account, statement.startDate, statement.endDate)
+
+	var file bytes.Buffer
+	page := 0
+	for day := statement.startDate; !day.After(statement.endDate); day = day.AddDate(0, 0, 1) {
+		page++
+		fmt.Fprintf(&file, "{1:F01BCAIIDJAXXXX0000000000}{2:I940BCAIIDJAXXXXN}{4:\r\n")
+		fmt.Fprintf(&file, ":20:%s\r\n:25:%s\r\n:28C:%05d/001\r\n", requestID, accountNumber, page)
+		fmt.Fprintf(&file, ":60F:%s\r\n", mt940Balance(day, account.Currency, balance))
+
+		next := day.AddDate(0, 0, 1)
+		for _, entry := range entries {
+			if entry.date.Before(day) || !entry.date.Before(next) {
+				continue
+			}
+			if entry.TransactionType == "C" {
+				balance = balance.Add(entry.TransactionAmount)
+			} else {
+				balance = balance.Sub(entry.TransactionAmount)
+			}
+			fmt.Fprintf(&file, ":61:%s%s%sNTRFNONREF\r\n:86:%s\r\n%s\r\n", day.Format("060102"), entry.TransactionType,
+				mt940Amount(entry.TransactionAmount), entry.TransactionName, entry.Trailer)
+		}
+
+		fmt.Fprintf(&file, ":62F:%s\r\n-}\r\n", mt940Balance(day, account.Currency, balance))
+	}
+	return ioutil.NopCloser(&file), nil
+}
+
+func mt940Balance(day time.Time, currency string, balance bca.Amount) string {
+	mark := "C"
+	if balance.Sign() < 0 {
+		mark = "D"
+	}
+	return mark + day.Format("060102") + currency + mt940Amount(balance.Abs())
+}
+
+func mt940Amount(amount bca.Amount) string {
+	return strings.Replace(amount.String(), ".", ",", 1)
+}
+
+//book records a statement entry and updates the balance of account. s.mu must be held
+func (s *Server) book(account *Account, transactionType string, amount bca.Amount, name, trailer string) {
+	if transactionType == "C" {
+		account.Balance = account.Balance.Add(amount)
+	} else {
+		account.Balance = account.Balance.Sub(amount)
+	}
+
+	now := time.Now()
+	s.statements[account.AccountNumber] = append(s.statements[account.AccountNumber], statementEntry{
+		date: now,
+		AccountStatement: bca.AccountStatement{
+			TransactionDate:   now.Format("02/01"),
+			BranchCode:        "0000",
+			TransactionType:   transactionType,
+			TransactionAmount: amount,
+			TransactionName:   name,
+			Trailer:           trailer,
+		},
+	})
+}
+
+//match reports whether the path segments match pattern, where "*" matches any single segment
+func match(segments []string, pattern ...string) bool {
+	if len(segments) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != segments[i] {
+			return false
+		}
+	}
+	return true
+}
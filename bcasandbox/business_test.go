@@ -0,0 +1,152 @@
+package bcasandbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+func fundTransferRequest(transactionID, beneficiaryAccountNumber, amount string) *bca.FundTransferRequest {
+	return &bca.FundTransferRequest{
+		CorporateID:              DefaultCorporateID,
+		SourceAccountNumber:      "0201245680",
+		TransactionID:            transactionID,
+		TransactionDate:          time.Now().Format("2006-01-02"),
+		ReferenceID:              "REF" + transactionID,
+		CurrencyCode:             "IDR",
+		Amount:                   bca.MustParseAmount(amount),
+		BeneficiaryAccountNumber: beneficiaryAccountNumber,
+		Remark1:                  "Invoice 1",
+	}
+}
+
+func TestServerFundTransfer(t *testing.T) {
+	tests := []struct {
+		name            string
+		requests        []*bca.FundTransferRequest
+		wantErr         error
+		wantSource      string
+		wantBeneficiary string
+	}{
+		{
+			name:            "moves the amount",
+			requests:        []*bca.FundTransferRequest{fundTransferRequest("00000001", "0201245681", "250000")},
+			wantSource:      "750000.00",
+			wantBeneficiary: "300000.00",
+		},
+		{
+			name:            "insufficient funds",
+			requests:        []*bca.FundTransferRequest{fundTransferRequest("00000001", "0201245681", "1000000.01")},
+			wantErr:         bca.ErrInsufficientFunds,
+			wantSource:      "1000000.00",
+			wantBeneficiary: "50000.00",
+		},
+		{
+			name:            "duplicate TransactionID",
+			requests:        []*bca.FundTransferRequest{fundTransferRequest("00000001", "0201245681", "100000"), fundTransferRequest("00000001", "0201245681", "100000")},
+			wantErr:         bca.ErrDuplicateTransactionID,
+			wantSource:      "900000.00",
+			wantBeneficiary: "150000.00",
+		},
+		{
+			name:            "unknown beneficiary",
+			requests:        []*bca.FundTransferRequest{fundTransferRequest("00000001", "0201245689", "100000")},
+			wantErr:         errAccountUnknown,
+			wantSource:      "1000000.00",
+			wantBeneficiary: "50000.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testServer(t)
+			c := businessClient(s.Config())
+
+			var err error
+			for _, request := range tt.requests {
+				_, err = c.FundTransfer(context.Background(), request)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FundTransfer() = %v, want %v", err, tt.wantErr)
+			}
+
+			source, _ := s.Account("0201245680")
+			beneficiary, _ := s.Account("0201245681")
+			if source.Balance.String() != tt.wantSource || beneficiary.Balance.String() != tt.wantBeneficiary {
+				t.Errorf("balances = %s, %s, want %s, %s", source.Balance, beneficiary.Balance, tt.wantSource, tt.wantBeneficiary)
+			}
+		})
+	}
+}
+
+func TestServerInquiryTransferStatus(t *testing.T) {
+	s := testServer(t)
+	c := businessClient(s.Config())
+	request := fundTransferRequest("00000001", "0201245681", "100000")
+	if _, err := c.FundTransfer(context.Background(), request); err != nil {
+		t.Fatalf("FundTransfer() = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		transactionID string
+		transferType  bca.TransferType
+		wantErr       error
+	}{
+		{name: "sent", transactionID: "00000001", transferType: bca.TransferTypeBCA},
+		{name: "other transfer type", transactionID: "00000001", transferType: bca.TransferTypeLLG, wantErr: bca.ErrTransactionNotFound},
+		{name: "unknown", transactionID: "00000002", transferType: bca.TransferTypeBCA, wantErr: bca.ErrTransactionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr_status, err := c.InquiryTransferStatus(context.Background(), &bca.InquiryTransferStatusRequest{
+				TransactionID:   tt.transactionID,
+				TransactionDate: time.Now(),
+				TransferType:    tt.transferType,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InquiryTransferStatus() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (*ptr_status).StatusCode != bca.TransferStatusSuccess {
+				t.Errorf("StatusCode = %s, want Success", (*ptr_status).StatusCode)
+			}
+		})
+	}
+}
+
+func TestServerDomesticFundTransfer(t *testing.T) {
+	s := testServer(t)
+	s.AddDomesticAccount("BRINIDJA", "8888801234", "Siti Aminah")
+	c := businessClient(s.Config())
+
+	ptr_account, err := c.InquiryDomesticAccount(context.Background(), &bca.InquiryDomesticAccountRequest{BeneficiaryBankCode: "BRINIDJA", BeneficiaryAccountNumber: "8888801234"})
+	if err != nil || (*ptr_account).BeneficiaryAccountName != "Siti Aminah" {
+		t.Fatalf("InquiryDomesticAccount() = %+v, %v, want Siti Aminah", ptr_account, err)
+	}
+
+	ptr_response, err := c.DomesticFundTransfer(context.Background(), &bca.DomesticFundTransferRequest{
+		TransactionID:            "00000001",
+		TransactionDate:          time.Now().Format("2006-01-02"),
+		ReferenceID:              "REF00000001",
+		SourceAccountNumber:      "0201245680",
+		BeneficiaryAccountNumber: "8888801234",
+		BeneficiaryBankCode:      "BRINIDJA",
+		BeneficiaryName:          "Siti Aminah",
+		Amount:                   bca.MustParseAmount("100000"),
+		TransferType:             bca.TransferTypeLLG,
+		BeneficiaryCustType:      bca.CustomerTypeIndividual,
+		BeneficiaryCustResidence: bca.CustomerResidenceResident,
+		CurrencyCode:             "IDR",
+	})
+	if err != nil || (*ptr_response).PPUNumber == "" {
+		t.Fatalf("DomesticFundTransfer() = %+v, %v, want a PPUNumber", ptr_response, err)
+	}
+
+	if source, _ := s.Account("0201245680"); source.Balance.String() != "900000.00" {
+		t.Errorf("source balance = %s, want 900000.00", source.Balance)
+	}
+}
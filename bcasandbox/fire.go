@@ -0,0 +1,387 @@
+package bcasandbox
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//FIRe transaction statuses reported by the sandbox in StatusMessage of InquiryTransaction
+const (
+	FIReStatusReleased  = "Released"
+	FIReStatusPaid      = "Paid"
+	FIReStatusCancelled = "Cancelled"
+)
+
+//Sandbox StatusTransaction codes of failed FIRe requests
+const (
+	fireStatusNotFound       = bca.StatusTransactionNotFound
+	fireStatusDuplicate      = "0002"
+	fireStatusInvalidAuth    = "0003"
+	fireStatusInvalidRequest = "0004"
+	fireStatusNotAllowed     = "0005"
+)
+
+type fireTransaction struct {
+	cash            bool
+	formNumber      string
+	referenceNumber string
+	sender          bca.SenderInquiryTransactionResponse
+	beneficiary     bca.BeneficiaryInquiryTransactionResponse
+	currencyID      string
+	amount          bca.Amount
+	pin             string
+	description1    string
+	description2    string
+	releaseDateTime string
+	status          string
+}
+
+func (s *Server) handleFIRe(w http.ResponseWriter, r *http.Request, body []byte) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/fire/transactions/to-account":
+		s.teleTransferToAccount(w, body)
+	case "/fire/accounts":
+		s.inquiryAccount(w, body)
+	case "/fire/accounts/balance":
+		s.inquiryAccountBalance(w, body)
+	case "/fire/transactions":
+		s.inquiryTransaction(w, body)
+	case "/fire/transactions/cash-transfer":
+		s.teleTransferCashTransfer(w, body)
+	case "/fire/transactions/cash-transfer/amend":
+		s.teleTransferAmendCashTransfer(w, body)
+	case "/fire/transactions/cash-transfer/cancel":
+		s.teleTransferCancelCashTransfer(w, body)
+	default:
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+}
+
+//PayOutCashTransfer marks a released cash transfer as paid out to its beneficiary
+func (s *Server) PayOutCashTransfer(formNumber string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.fireTransactions[formNumber]
+	if !ok || !t.cash || t.status != FIReStatusReleased {
+		return false
+	}
+	t.status = FIReStatusPaid
+	return true
+}
+
+//fireStatus writes a FIRe response that only carries StatusTransaction and StatusMessage
+func fireStatus(w http.ResponseWriter, statusTransaction, statusMessage string) {
+	writeJSON(w, http.StatusOK, struct {
+		StatusTransaction string
+		StatusMessage     string
+	}{statusTransaction, statusMessage})
+}
+
+func (s *Server) checkAuth(w http.ResponseWriter, auth bca.Auth) bool {
+	if auth != s.FIRe {
+		fireStatus(w, fireStatusInvalidAuth, "Invalid authentication")
+		return false
+	}
+	return true
+}
+
+//newFIReTransaction registers a transaction under its FormNumber. s.mu must be held
+func (s *Server) newFIReTransaction(w http.ResponseWriter, t *fireTransaction) bool {
+	if t.formNumber == "" {
+		fireStatus(w, fireStatusInvalidRequest, "FormNumber is mandatory")
+		return false
+	}
+	if _, ok := s.fireTransactions[t.formNumber]; ok {
+		fireStatus(w, fireStatusDuplicate, "Duplicate FormNumber")
+		return false
+	}
+
+	s.fireSeq++
+	t.referenceNumber = fmt.Sprintf("FIRE%08d", s.fireSeq)
+	t.releaseDateTime = time.Now().Format("2006-01-02T15:04:05")
+	t.status = FIReStatusReleased
+	s.fireTransactions[t.formNumber] = t
+	return true
+}
+
+func (s *Server) teleTransferToAccount(w http.ResponseWriter, body []byte) {
+	var request bca.TeleTransferAccountRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &fireTransaction{
+		formNumber: request.TransactionDetails.FormNumber,
+		sender: bca.SenderInquiryTransactionResponse{
+			FirstName: request.SenderDetails.FirstName,
+			LastName:  request.SenderDetails.LastName,
+		},
+		beneficiary: bca.BeneficiaryInquiryTransactionResponse{
+			Name:          request.BeneficiaryDetails.Name,
+			BankCodeType:  request.BeneficiaryDetails.BankCodeType,
+			BankCodeValue: request.BeneficiaryDetails.BankCodeValue,
+			AccountNumber: request.BeneficiaryDetails.AccountNumber,
+		},
+		currencyID:   request.TransactionDetails.CurrencyID,
+		amount:       request.TransactionDetails.Amount,
+		description1: request.TransactionDetails.Description1,
+		description2: request.TransactionDetails.Description2,
+	}
+
+	// Transfers to an account held by the sandbox are credited, other banks are only recorded
+	beneficiary, isBCA := s.accounts[request.BeneficiaryDetails.AccountNumber]
+	if !s.newFIReTransaction(w, t) {
+		return
+	}
+	beneficiaryAccountName := request.BeneficiaryDetails.Name
+	if isBCA {
+		s.book(beneficiary, "C", t.amount, request.SenderDetails.FirstName+" "+request.SenderDetails.LastName, t.description1)
+		beneficiaryAccountName = beneficiary.Name
+	}
+
+	writeJSON(w, http.StatusOK, bca.TeleTransferAccountResponse{
+		BeneficiaryDetails: bca.BeneficiaryAccountResponse{
+			Name:                  request.BeneficiaryDetails.Name,
+			AccountNumber:         request.BeneficiaryDetails.AccountNumber,
+			ServerBeneAccountName: beneficiaryAccountName,
+		},
+		TransactionDetails: bca.TransactionAccountResponse{
+			CurrencyID:      t.currencyID,
+			Amount:          t.amount,
+			Description1:    t.description1,
+			Description2:    t.description2,
+			FormNumber:      t.formNumber,
+			ReferenceNumber: t.referenceNumber,
+			ReleaseDateTime: t.releaseDateTime,
+		},
+		StatusTransaction: bca.StatusTransactionSuccess,
+		StatusMessage:     "Success",
+	})
+}
+
+func (s *Server) inquiryAccount(w http.ResponseWriter, body []byte) {
+	var request bca.InquiryAccountRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := ""
+	if account, ok := s.accounts[request.BeneficiaryDetails.AccountNumber]; ok {
+		name = account.Name
+	} else if n, ok := s.domesticAccounts[request.BeneficiaryDetails.BankCodeValue+"/"+request.BeneficiaryDetails.AccountNumber]; ok {
+		name = n
+	} else {
+		fireStatus(w, fireStatusNotFound, "Account not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bca.InquiryAccountResponse{
+		BeneficiaryDetails: bca.BeneficiaryInquiryAccountResponse{ServerBeneAccountName: name},
+		StatusTransaction:  bca.StatusTransactionSuccess,
+		StatusMessage:      "Success",
+	})
+}
+
+func (s *Server) inquiryAccountBalance(w http.ResponseWriter, body []byte) {
+	var request bca.InquiryAccountBalanceRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[request.FIDetails.AccountNumber]
+	if !ok {
+		fireStatus(w, fireStatusNotFound, "Account not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bca.InquiryAccountBalanceResponse{
+		FIDetails: bca.FIInquiryAccountBalanceResponse{
+			CurrencyID:     account.Currency,
+			AccountBalance: account.Balance,
+		},
+		StatusTransaction: bca.StatusTransactionSuccess,
+		StatusMessage:     "Success",
+	})
+}
+
+func (s *Server) inquiryTransaction(w http.ResponseWriter, body []byte) {
+	var request bca.InquiryTransactionRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *fireTransaction
+	switch request.TransactionDetails.InquiryBy {
+	case bca.InquiryByFormNumber:
+		found = s.fireTransactions[request.TransactionDetails.InquiryValue]
+	case bca.InquiryByReferenceNumber:
+		for _, t := range s.fireTransactions {
+			if t.referenceNumber == request.TransactionDetails.InquiryValue {
+				found = t
+			}
+		}
+	}
+	if found == nil {
+		fireStatus(w, fireStatusNotFound, "Transaction not found")
+		return
+	}
+
+	response := bca.InquiryTransactionResponse{
+		SenderDetails:      found.sender,
+		BeneficiaryDetails: found.beneficiary,
+		TransactionDetails: bca.TransactionTTInquiryTransactionResponse{
+			CurrencyID:      found.currencyID,
+			ReleaseDateTime: found.releaseDateTime,
+			LocalID:         s.FIRe.LocalID,
+			FormNumber:      found.formNumber,
+			ReferenceNumber: found.referenceNumber,
+			PIN:             found.pin,
+			Description1:    found.description1,
+			Description2:    found.description2,
+		},
+		StatusTransaction: bca.StatusTransactionSuccess,
+		StatusMessage:     found.status,
+	}
+	if !found.cash || found.status == FIReStatusPaid {
+		response.TransactionDetails.AmountPaid = found.amount
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) teleTransferCashTransfer(w http.ResponseWriter, body []byte) {
+	var request bca.TeleTransferCashTransferRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &fireTransaction{
+		cash:       true,
+		formNumber: request.TransactionDetails.FormNumber,
+		sender: bca.SenderInquiryTransactionResponse{
+			FirstName: request.SenderDetails.FirstName,
+			LastName:  request.SenderDetails.LastName,
+		},
+		beneficiary:  bca.BeneficiaryInquiryTransactionResponse{Name: request.BeneficiaryDetails.Name},
+		currencyID:   request.TransactionDetails.CurrencyID,
+		amount:       request.TransactionDetails.Amount,
+		pin:          request.TransactionDetails.PIN,
+		description1: request.TransactionDetails.Description1,
+		description2: request.TransactionDetails.Description2,
+	}
+	if !s.newFIReTransaction(w, t) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bca.TeleTransferCashTransferResponse{
+		BeneficiaryDetails: bca.BeneficiaryTeleTransferCashTransferResponse{Name: t.beneficiary.Name},
+		TransactionDetails: bca.TransactionTeleTransferCashTransferResponse{
+			PIN:             t.pin,
+			CurrencyID:      t.currencyID,
+			Amount:          t.amount,
+			Description1:    t.description1,
+			Description2:    t.description2,
+			FormNumber:      t.formNumber,
+			ReferenceNumber: t.referenceNumber,
+			ReleaseDateTime: t.releaseDateTime,
+		},
+		StatusTransaction: bca.StatusTransactionSuccess,
+		StatusMessage:     "Success",
+	})
+}
+
+func (s *Server) teleTransferAmendCashTransfer(w http.ResponseWriter, body []byte) {
+	var request bca.TeleTransferAmendCashTransferRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.fireTransactions[request.TransactionDetails.FormNumber]
+	if !ok || !t.cash {
+		fireStatus(w, fireStatusNotFound, "Transaction not found")
+		return
+	}
+	if t.status != FIReStatusReleased {
+		fireStatus(w, fireStatusNotAllowed, "Transaction is "+strings.ToLower(t.status))
+		return
+	}
+
+	amendment := request.AmendmentDetails
+	if amendment.SenderDetails.FirstName != "" {
+		t.sender.FirstName = amendment.SenderDetails.FirstName
+		t.sender.LastName = amendment.SenderDetails.LastName
+	}
+	if amendment.BeneficiaryDetails.Name != "" {
+		t.beneficiary.Name = amendment.BeneficiaryDetails.Name
+	}
+	t.description1 = amendment.TransactionDetails.Description1
+	t.description2 = amendment.TransactionDetails.Description2
+
+	writeJSON(w, http.StatusOK, bca.TeleTransferAmendCashTransferResponse{
+		AmendmentDetails:   amendment,
+		TransactionDetails: request.TransactionDetails,
+		StatusTransaction:  bca.StatusTransactionSuccess,
+		StatusMessage:      "Success",
+	})
+}
+
+func (s *Server) teleTransferCancelCashTransfer(w http.ResponseWriter, body []byte) {
+	var request bca.TeleTransferCancelCashTransferRequest
+	if !decode(w, body, &request) || !s.checkAuth(w, request.Authentication) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.fireTransactions[request.TransactionDetails.FormNumber]
+	if !ok || !t.cash {
+		fireStatus(w, fireStatusNotFound, "Transaction not found")
+		return
+	}
+	if !t.amount.Equal(request.TransactionDetails.Amount) || t.currencyID != request.TransactionDetails.CurrencyID {
+		fireStatus(w, fireStatusInvalidRequest, "Amount or currency does not match")
+		return
+	}
+	if t.status != FIReStatusReleased {
+		fireStatus(w, fireStatusNotAllowed, "Transaction is "+strings.ToLower(t.status))
+		return
+	}
+	t.status = FIReStatusCancelled
+
+	writeJSON(w, http.StatusOK, bca.TeleTransferCancelCashTransferResponse{
+		TransactionDetails: bca.TransactionTeleTransferCancelCashTransferResponse{
+			FormNumber:      t.formNumber,
+			ReleaseDateTime: time.Now().Format("2006-01-02T15:04:05"),
+		},
+		StatusTransaction: bca.StatusTransactionSuccess,
+		StatusMessage:     "Success",
+	})
+}
@@ -0,0 +1,170 @@
+package bcasandbox
+
+import (
+	"context"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/auth"
+	"github.com/ianeinser/bca-api-go/fire"
+)
+
+//fireClient returns a fire.Client of the sandbox fetching its tokens with auth.TokenSource
+func fireClient(cfg bca.Config) fire.Client {
+	c := fire.NewClient(cfg)
+	c.TokenSource = auth.NewTokenSource(cfg)
+	return c
+}
+
+func cashTransferRequest(formNumber string) *bca.TeleTransferCashTransferRequest {
+	return &bca.TeleTransferCashTransferRequest{
+		SenderDetails: bca.SenderTeleTransferCashTransferRequest{
+			FirstName:            "Budi",
+			Address1:             "Jl. Sudirman 1",
+			City:                 "Jakarta",
+			CountryID:            "ID",
+			IdentificationType:   "KTP",
+			IdentificationNumber: "3171000000000001",
+		},
+		BeneficiaryDetails: bca.BeneficiaryTeleTransferCashTransferRequest{
+			Name:                 "Siti",
+			Address1:             "Jl. Thamrin 2",
+			City:                 "Jakarta",
+			CountryID:            "ID",
+			IdentificationType:   "KTP",
+			IdentificationNumber: "3171000000000002",
+		},
+		TransactionDetails: bca.TransactionTeleTransferCashTransferRequest{
+			PIN:             "123456",
+			CurrencyID:      "IDR",
+			Amount:          bca.MustParseAmount("250000"),
+			PurposeCode:     "011",
+			DetailOfCharges: bca.DetailOfChargesSha,
+			FormNumber:      formNumber,
+		},
+	}
+}
+
+func inquiryTransaction(c fire.Client, formNumber string) (*bca.InquiryTransactionResponse, error) {
+	return c.InquiryTransaction(context.Background(), &bca.InquiryTransactionRequest{
+		TransactionDetails: bca.TransactionInquiryTransactionRequest{InquiryBy: bca.InquiryByFormNumber, InquiryValue: formNumber},
+	})
+}
+
+func TestServerTeleTransferToAccount(t *testing.T) {
+	s := testServer(t)
+	c := fireClient(s.Config())
+
+	ptr_response, err := c.TeleTransferToAccount(context.Background(), &bca.TeleTransferAccountRequest{
+		SenderDetails: bca.SenderAccountRequest{
+			FirstName:            "Budi",
+			Address1:             "Jl. Sudirman 1",
+			City:                 "Jakarta",
+			CountryID:            "ID",
+			IdentificationType:   "KTP",
+			IdentificationNumber: "3171000000000001",
+			AccountNumber:        "0201245680",
+		},
+		BeneficiaryDetails: bca.BeneficiaryAccountRequest{
+			Name:                 "Budi Santoso",
+			Address1:             "Jl. Thamrin 2",
+			City:                 "Jakarta",
+			CountryID:            "ID",
+			IdentificationType:   "KTP",
+			IdentificationNumber: "3171000000000002",
+			BankCodeType:         bca.BankCodeTypeBIC,
+			BankCodeValue:        "CENAIDJA",
+			AccountNumber:        "0201245681",
+		},
+		TransactionDetails: bca.TransactionAccountRequest{
+			CurrencyID:      "IDR",
+			Amount:          bca.MustParseAmount("100000"),
+			PurposeCode:     "011",
+			DetailOfCharges: bca.DetailOfChargesSha,
+			FormNumber:      "FORM1",
+		},
+	})
+	if err != nil || (*ptr_response).StatusTransaction != bca.StatusTransactionSuccess {
+		t.Fatalf("TeleTransferToAccount() = %+v, %v, want success", ptr_response, err)
+	}
+	if beneficiary, _ := s.Account("0201245681"); beneficiary.Balance.String() != "150000.00" {
+		t.Errorf("beneficiary balance = %s, want 150000.00", beneficiary.Balance)
+	}
+
+	ptr_inquiry, err := inquiryTransaction(c, "FORM1")
+	if err != nil || (*ptr_inquiry).StatusMessage != FIReStatusReleased || (*ptr_inquiry).TransactionDetails.AmountPaid.String() != "100000.00" {
+		t.Errorf("InquiryTransaction() = %+v, %v, want a released transaction of 100000.00", ptr_inquiry, err)
+	}
+}
+
+func TestServerCashTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		payOut     bool
+		cancel     bool
+		wantCancel string
+		wantStatus string
+	}{
+		{name: "released", wantStatus: FIReStatusReleased},
+		{name: "paid out", payOut: true, wantStatus: FIReStatusPaid},
+		{name: "cancelled", cancel: true, wantCancel: bca.StatusTransactionSuccess, wantStatus: FIReStatusCancelled},
+		{name: "cancelled once paid out", payOut: true, cancel: true, wantCancel: fireStatusNotAllowed, wantStatus: FIReStatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testServer(t)
+			c := fireClient(s.Config())
+
+			request := cashTransferRequest("FORM1")
+			if ptr_response, err := c.TeleTransferCashTransfer(context.Background(), request); err != nil || (*ptr_response).StatusTransaction != bca.StatusTransactionSuccess {
+				t.Fatalf("TeleTransferCashTransfer() = %+v, %v, want success", ptr_response, err)
+			}
+			if tt.payOut && !s.PayOutCashTransfer("FORM1") {
+				t.Fatal("PayOutCashTransfer() = false, want true")
+			}
+			if tt.cancel {
+				ptr_cancel, err := c.TeleTransferCancelCashTransfer(context.Background(), &bca.TeleTransferCancelCashTransferRequest{
+					TransactionDetails: bca.TransactionTeleTransferCancelCashTransferRequest{
+						FormNumber: "FORM1",
+						Amount:     request.TransactionDetails.Amount,
+						CurrencyID: request.TransactionDetails.CurrencyID,
+					},
+				})
+				if err != nil || (*ptr_cancel).StatusTransaction != tt.wantCancel {
+					t.Errorf("TeleTransferCancelCashTransfer() = %+v, %v, want StatusTransaction %s", ptr_cancel, err, tt.wantCancel)
+				}
+			}
+
+			ptr_inquiry, err := inquiryTransaction(c, "FORM1")
+			if err != nil || (*ptr_inquiry).StatusMessage != tt.wantStatus {
+				t.Errorf("InquiryTransaction() = %+v, %v, want %s", ptr_inquiry, err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestServerInquiryTransaction(t *testing.T) {
+	s := testServer(t)
+
+	tests := []struct {
+		name   string
+		config func(cfg *bca.Config)
+		want   string
+	}{
+		{name: "unknown transaction", config: func(cfg *bca.Config) {}, want: fireStatusNotFound},
+		{name: "wrong access code", config: func(cfg *bca.Config) { cfg.AccessCode = "other" }, want: fireStatusInvalidAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := s.Config()
+			tt.config(&cfg)
+
+			ptr_inquiry, err := inquiryTransaction(fireClient(cfg), "FORM9")
+			if err != nil || (*ptr_inquiry).StatusTransaction != tt.want {
+				t.Errorf("InquiryTransaction() = %+v, %v, want StatusTransaction %s", ptr_inquiry, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+//Package bcasandbox provides an in-process fake of BCA API for offline integration testing.
+//Point bca.Config.URL at Server.URL, or use Server.Config, and the service clients talk to it as to the real API.
+package bcasandbox
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//Default credentials accepted by a new Server
+const (
+	DefaultClientID     = "sandbox-client-id"
+	DefaultClientSecret = "sandbox-client-secret"
+	DefaultAPIKey       = "sandbox-api-key"
+	DefaultAPISecret    = "sandbox-api-secret"
+	DefaultCorporateID  = "BCAAPI2016"
+)
+
+//Fault represents a failure injected into matching requests
+type Fault struct {
+	// Delay is waited before answering, longer than the client timeout to simulate a timeout
+	Delay time.Duration
+	// StatusCode is the HTTP status to answer with, 0 means 400 when Error is set
+	StatusCode int
+	// Error is the BCA error envelope to answer with
+	Error *bca.Error
+	// Times is how many matching requests fail, 0 means all of them
+	Times int
+}
+
+type fault struct {
+	Fault
+	method     string
+	pathPrefix string
+}
+
+//Account represents a BCA account held by the sandbox
+type Account struct {
+	AccountNumber string
+	Name          string
+	Currency      string
+	Balance       bca.Amount
+}
+
+//Server is an httptest based fake of BCA API that keeps accounts and transfers in memory
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+	APIKey       string
+	APISecret    string
+	CorporateID  string
+	FIRe         bca.Auth
+	TokenTTL     time.Duration
+
+	mu                sync.Mutex
+	tokenSeq          int
+	tokens            map[string]time.Time
+	accounts          map[string]*Account
+	statements        map[string][]statementEntry
+	offlineStatements map[string]*offlineStatement
+	offlineSeq        int
+	domesticAccounts  map[string]string
+	transfers         map[string]*transfer
+	fireTransactions  map[string]*fireTransaction
+	fireSeq           int
+	vaPayments        []vaPayment
+	currencies        map[string]bca.Currency
+	faults            []*fault
+}
+
+//NewServer starts a new sandbox with the default credentials, it must be closed with Close
+func NewServer() *Server {
+	s := &Server{
+		ClientID:     DefaultClientID,
+		ClientSecret: DefaultClientSecret,
+		APIKey:       DefaultAPIKey,
+		APISecret:    DefaultAPISecret,
+		CorporateID:  DefaultCorporateID,
+		FIRe: bca.Auth{
+			CorporateID: "SANDBOXFIRE",
+			AccessCode:  "sandbox-access-code",
+			BranchCode:  "SANDBOX01",
+			UserID:      "SANDBOXUSER",
+			LocalID:     "40115",
+		},
+		TokenTTL: time.Hour,
+
+		tokens:            map[string]time.Time{},
+		accounts:          map[string]*Account{},
+		statements:        map[string][]statementEntry{},
+		offlineStatements: map[string]*offlineStatement{},
+		domesticAccounts:  map[string]string{},
+		transfers:         map[string]*transfer{},
+		fireTransactions:  map[string]*fireTransaction{},
+		currencies:        map[string]bca.Currency{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/oauth/token", s.handleToken)
+	mux.Handle("/banking/", s.authenticated(s.handleBanking))
+	mux.Handle("/fire/", s.authenticated(s.handleFIRe))
+	mux.Handle("/va/payments", s.authenticated(s.handleVAPayments))
+	mux.Handle("/general/rate/forex", s.authenticated(s.handleForex))
+
+	s.Server = httptest.NewServer(s.withFaults(mux))
+	return s
+}
+
+//Config returns a bca.Config pointing at the sandbox with its credentials
+func (s *Server) Config() bca.Config {
+	return bca.Config{
+		URL:          s.URL,
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		APIKey:       s.APIKey,
+		APISecret:    s.APISecret,
+		CorporateID:  s.CorporateID,
+		OriginHost:   "localhost",
+
+		FIReCorporateID: s.FIRe.CorporateID,
+		AccessCode:      s.FIRe.AccessCode,
+		BranchCode:      s.FIRe.BranchCode,
+		UserID:          s.FIRe.UserID,
+		LocalID:         s.FIRe.LocalID,
+	}
+}
+
+//AddAccount adds or replaces a BCA account
+func (s *Server) AddAccount(account Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if account.Currency == "" {
+		account.Currency = "IDR"
+	}
+	s.accounts[account.AccountNumber] = &account
+}
+
+//Account returns a copy of a BCA account
+func (s *Server) Account(accountNumber string) (Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[accountNumber]
+	if !ok {
+		return Account{}, false
+	}
+	return *account, true
+}
+
+//AddDomesticAccount registers an account at another bank for InquiryDomesticAccount and DomesticFundTransfer
+func (s *Server) AddDomesticAccount(bankCode, accountNumber, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.domesticAccounts[bankCode+"/"+accountNumber] = name
+}
+
+//InjectFault makes requests matching method and path prefix fail, an empty method matches any method
+func (s *Server) InjectFault(method, pathPrefix string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{Fault: f, method: method, pathPrefix: pathPrefix})
+}
+
+//ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+//ExpireTokens makes every issued access token expired
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token := range s.tokens {
+		s.tokens[token] = time.Time{}
+	}
+}
+
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := s.matchFault(r)
+		if f == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		statusCode := f.StatusCode
+		if f.Error != nil {
+			if statusCode == 0 {
+				statusCode = http.StatusBadRequest
+			}
+			writeError(w, statusCode, f.Error)
+			return
+		}
+		if statusCode != 0 {
+			w.WriteHeader(statusCode)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) matchFault(r *http.Request) *fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if (f.method != "" && f.method != r.Method) || !strings.HasPrefix(r.URL.Path, f.pathPrefix) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte(s.ClientID+":"+s.ClientSecret))
+	if r.Header.Get("Authorization") != basic || r.PostFormValue("grant_type") != "client_credentials" {
+		writeError(w, http.StatusUnauthorized, errInvalidClient)
+		return
+	}
+
+	s.mu.Lock()
+	s.tokenSeq++
+	token := fmt.Sprintf("sandbox-token-%d-%d", s.tokenSeq, time.Now().UnixNano())
+	s.tokens[token] = time.Now().Add(s.TokenTTL)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, bca.AuthToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.TokenTTL / time.Second),
+		Scope:       "resource.WRITE resource.READ",
+	})
+}
+
+//authenticated checks the bearer token, the API key and the signature before calling handler with the request body
+func (s *Server) authenticated(handler func(w http.ResponseWriter, r *http.Request, body []byte)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		s.mu.Lock()
+		expiry, ok := s.tokens[token]
+		s.mu.Unlock()
+		if !ok || !time.Now().Before(expiry) {
+			writeError(w, http.StatusUnauthorized, bca.ErrExpiredToken)
+			return
+		}
+
+		if r.Header.Get("X-BCA-Key") != s.APIKey {
+			writeError(w, http.StatusUnauthorized, errInvalidKey)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errInvalidRequest)
+			return
+		}
+
+		signature := bca.Signature(s.APISecret, r.Method, r.URL.RequestURI(), token, string(body), r.Header.Get("X-BCA-Timestamp"))
+		if !hmac.Equal([]byte(signature), []byte(r.Header.Get("X-BCA-Signature"))) {
+			writeError(w, http.StatusBadRequest, bca.ErrInvalidSignature)
+			return
+		}
+
+		handler(w, r, body)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, bcaErr *bca.Error) {
+	writeJSON(w, statusCode, bcaErr)
+}
+
+func decode(w http.ResponseWriter, body []byte, v interface{}) bool {
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidRequest)
+		return false
+	}
+	return true
+}
+
+//Sandbox specific errors, in the same envelope as BCA API
+var (
+	errInvalidClient  = &bca.Error{ErrorCode: "ESB-14-008", ErrorMessage: bca.ErrorLang{Indonesian: "Client_Id/Client_Secret/Grant_Type tidak valid", English: "Invalid Client_Id/Client_Secret/Grant_Type"}}
+	errInvalidKey     = &bca.Error{ErrorCode: "ESB-14-007", ErrorMessage: bca.ErrorLang{Indonesian: "Key tidak valid", English: "Invalid key"}}
+	errInvalidRequest = &bca.Error{ErrorCode: "ESB-14-002", ErrorMessage: bca.ErrorLang{Indonesian: "Permintaan tidak valid", English: "Invalid request"}}
+	errNotFound       = &bca.Error{ErrorCode: "ESB-14-010", ErrorMessage: bca.ErrorLang{Indonesian: "Data tidak ditemukan", English: "Data not found"}}
+	errAccountUnknown = &bca.Error{ErrorCode: "ESB-82-003", ErrorMessage: bca.ErrorLang{Indonesian: "Rekening tidak ditemukan", English: "Account not found"}}
+)
@@ -0,0 +1,126 @@
+package bcasandbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/auth"
+	"github.com/ianeinser/bca-api-go/business"
+)
+
+//testServer starts a sandbox holding two BCA accounts, closed when the test ends
+func testServer(t *testing.T) *Server {
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	s.AddAccount(Account{AccountNumber: "0201245680", Name: "PT Sumber Makmur", Balance: bca.MustParseAmount("1000000.00")})
+	s.AddAccount(Account{AccountNumber: "0201245681", Name: "Budi Santoso", Balance: bca.MustParseAmount("50000.00")})
+	return s
+}
+
+//businessClient returns a business.Client of the sandbox fetching its tokens with auth.TokenSource
+func businessClient(cfg bca.Config) business.Client {
+	c := business.NewClient(cfg)
+	c.TokenSource = auth.NewTokenSource(cfg)
+	return c
+}
+
+func balanceInformation(c business.Client) error {
+	_, err := c.BalanceInformation(context.Background(), &bca.BalanceInformationRequest{CorporateID: DefaultCorporateID, AccountNumber: "0201245680"})
+	return err
+}
+
+func TestServerAuthenticates(t *testing.T) {
+	s := testServer(t)
+
+	tests := []struct {
+		name    string
+		config  func(cfg *bca.Config)
+		wantErr error
+	}{
+		{name: "valid", config: func(cfg *bca.Config) {}},
+		{name: "wrong API secret", config: func(cfg *bca.Config) { cfg.APISecret = "other" }, wantErr: bca.ErrInvalidSignature},
+		{name: "wrong API key", config: func(cfg *bca.Config) { cfg.APIKey = "other" }, wantErr: errInvalidKey},
+		{name: "wrong client secret", config: func(cfg *bca.Config) { cfg.ClientSecret = "other" }, wantErr: errInvalidClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := s.Config()
+			tt.config(&cfg)
+
+			if err := balanceInformation(businessClient(cfg)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("BalanceInformation() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerExpireTokens(t *testing.T) {
+	s := testServer(t)
+	c := businessClient(s.Config())
+
+	if err := balanceInformation(c); err != nil {
+		t.Fatalf("BalanceInformation() = %v", err)
+	}
+
+	s.ExpireTokens()
+	if err := balanceInformation(c); !errors.Is(err, bca.ErrExpiredToken) {
+		t.Errorf("BalanceInformation() with an expired token = %v, want ErrExpiredToken", err)
+	}
+	// The rejected token was dropped, so the next call fetches a new one
+	if err := balanceInformation(c); err != nil {
+		t.Errorf("BalanceInformation() after the token was rejected = %v, want nil", err)
+	}
+}
+
+func TestServerInjectFault(t *testing.T) {
+	unavailable := &bca.Error{ErrorCode: "ESB-99-999", ErrorMessage: bca.ErrorLang{English: "System unavailable"}}
+
+	tests := []struct {
+		name       string
+		method     string
+		pathPrefix string
+		fault      Fault
+		want       []int
+	}{
+		{name: "times", pathPrefix: "/banking/", fault: Fault{StatusCode: http.StatusServiceUnavailable, Times: 2}, want: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, 0, 0}},
+		{name: "always", pathPrefix: "/banking/", fault: Fault{StatusCode: http.StatusBadGateway}, want: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}},
+		{name: "error defaults to 400", pathPrefix: "/banking/", fault: Fault{Error: unavailable, Times: 1}, want: []int{http.StatusBadRequest, 0}},
+		{name: "other method", method: "POST", pathPrefix: "/banking/", fault: Fault{StatusCode: http.StatusBadGateway}, want: []int{0, 0}},
+		{name: "other path", pathPrefix: "/fire/", fault: Fault{StatusCode: http.StatusBadGateway}, want: []int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testServer(t)
+			s.InjectFault(tt.method, tt.pathPrefix, tt.fault)
+			c := businessClient(s.Config())
+
+			for i, want := range tt.want {
+				err := balanceInformation(c)
+				var bcaErr *bca.Error
+				got := 0
+				if errors.As(err, &bcaErr) {
+					got = bcaErr.HTTPStatus
+				}
+				if got != want {
+					t.Errorf("call %d answered %d (%v), want %d", i+1, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerClearFaults(t *testing.T) {
+	s := testServer(t)
+	s.InjectFault("", "/banking/", Fault{StatusCode: http.StatusBadGateway})
+	s.ClearFaults()
+
+	if err := balanceInformation(businessClient(s.Config())); err != nil {
+		t.Errorf("BalanceInformation() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,110 @@
+package bcasandbox
+
+import (
+	"net/http"
+	"strings"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+type vaPayment struct {
+	companyCode    string
+	customerNumber string
+	payment        bca.VAInquiryStatusPaymentResponse
+}
+
+//maxVAPayments is the number of rows BCA returns at most for a payment status inquiry
+const maxVAPayments = 10
+
+//AddVAPayment records a Virtual Account payment for VAInquiryStatusPayment
+func (s *Server) AddVAPayment(companyCode, customerNumber string, payment bca.VAInquiryStatusPaymentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payment.CompanyCode = companyCode
+	payment.CustomerNumber = customerNumber
+	s.vaPayments = append(s.vaPayments, vaPayment{
+		companyCode:    companyCode,
+		customerNumber: customerNumber,
+		payment:        payment,
+	})
+}
+
+//SetRate adds or replaces the exchange rates of a currency for ForeignExchangeRate
+func (s *Server) SetRate(currency bca.Currency) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.currencies[currency.CurrencyCode] = currency
+}
+
+func (s *Server) handleVAPayments(w http.ResponseWriter, r *http.Request, body []byte) {
+	query := r.URL.Query()
+	companyCode := query.Get("CompanyCode")
+	// As BCA, either a customer number or a request ID is required
+	if r.Method != http.MethodGet || companyCode == "" || (query.Get("CustomerNumber") == "" && query.Get("RequestId") == "") {
+		writeError(w, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var response bca.InquiryStatusPaymentResponse
+	for _, p := range s.vaPayments {
+		if p.companyCode != companyCode ||
+			(query.Get("CustomerNumber") != "" && p.customerNumber != query.Get("CustomerNumber")) ||
+			(query.Get("RequestId") != "" && p.payment.RequestID != query.Get("RequestId")) {
+			continue
+		}
+		if len(response.TransactionData) == maxVAPayments {
+			break
+		}
+		response.TransactionData = append(response.TransactionData, p.payment)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleForex(w http.ResponseWriter, r *http.Request, body []byte) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var response bca.ForeignExchangeRateResponse
+	codes := strings.Split(query.Get("CurrencyCode"), ",")
+	if query.Get("CurrencyCode") == "" {
+		codes = nil
+		for code := range s.currencies {
+			codes = append(codes, code)
+		}
+	}
+
+	rateTypes := map[string]bool{}
+	for _, rateType := range strings.Split(query.Get("RateType"), ",") {
+		switch rateType {
+		case "":
+		case "erate", "tt", "tc", "bn":
+			rateTypes[rateType] = true
+		default:
+			response.InvalidRateType = rateType
+		}
+	}
+
+	for _, code := range codes {
+		currency, ok := s.currencies[code]
+		if !ok {
+			response.InvalidCurrency = code
+			continue
+		}
+
+		filtered := bca.Currency{CurrencyCode: code}
+		for _, rate := range currency.RateDetail {
+			if len(rateTypes) == 0 || rateTypes[string(rate.RateType)] {
+				filtered.RateDetail = append(filtered.RateDetail, rate)
+			}
+		}
+		response.Currencies = append(response.Currencies, filtered)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
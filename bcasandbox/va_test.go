@@ -0,0 +1,110 @@
+package bcasandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/auth"
+	"github.com/ianeinser/bca-api-go/general"
+	"github.com/ianeinser/bca-api-go/va"
+)
+
+func TestServerVAPayments(t *testing.T) {
+	s := testServer(t)
+	for i := 1; i <= 12; i++ {
+		s.AddVAPayment("12345", "0001", bca.VAInquiryStatusPaymentResponse{RequestID: fmt.Sprintf("REQ%02d", i)})
+	}
+	s.AddVAPayment("12345", "0002", bca.VAInquiryStatusPaymentResponse{RequestID: "REQ13"})
+	s.AddVAPayment("54321", "0001", bca.VAInquiryStatusPaymentResponse{RequestID: "REQ14"})
+
+	cfg := s.Config()
+	c := va.NewClient(cfg)
+	c.TokenSource = auth.NewTokenSource(cfg)
+
+	tests := []struct {
+		name    string
+		request bca.InquiryStatusPaymentRequest
+		want    int
+	}{
+		{name: "capped at 10 rows", request: bca.InquiryStatusPaymentRequest{CompanyCode: "12345", CustomerNumber: "0001"}, want: maxVAPayments},
+		{name: "customer number", request: bca.InquiryStatusPaymentRequest{CompanyCode: "12345", CustomerNumber: "0002"}, want: 1},
+		{name: "request ID", request: bca.InquiryStatusPaymentRequest{CompanyCode: "12345", RequestID: "REQ12"}, want: 1},
+		{name: "other company", request: bca.InquiryStatusPaymentRequest{CompanyCode: "54321", CustomerNumber: "0001"}, want: 1},
+		{name: "unknown customer", request: bca.InquiryStatusPaymentRequest{CompanyCode: "12345", CustomerNumber: "0003"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr_response, err := c.VAInquiryStatusPayment(context.Background(), &tt.request)
+			if err != nil || len((*ptr_response).TransactionData) != tt.want {
+				t.Fatalf("VAInquiryStatusPayment() = %+v, %v, want %d rows", ptr_response, err, tt.want)
+			}
+			for _, payment := range (*ptr_response).TransactionData {
+				if payment.CompanyCode != tt.request.CompanyCode {
+					t.Errorf("CompanyCode = %s, want %s", payment.CompanyCode, tt.request.CompanyCode)
+				}
+			}
+		})
+	}
+}
+
+func TestServerVAPaymentsCompanyCodeAlone(t *testing.T) {
+	s := testServer(t)
+	s.AddVAPayment("12345", "0001", bca.VAInquiryStatusPaymentResponse{RequestID: "REQ01"})
+
+	cfg := s.Config()
+	accessToken, err := auth.NewTokenSource(cfg).Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() = %v", err)
+	}
+
+	// The client refuses such a request, so it is sent with the API itself
+	api := bca.NewAPI(cfg)
+	var response bca.InquiryStatusPaymentResponse
+	err = api.CallContext(context.Background(), "GET", "/va/payments?CompanyCode=12345", accessToken, nil, nil, &response)
+	if !errors.Is(err, errInvalidRequest) {
+		t.Errorf("CallContext() = %v, want errInvalidRequest", err)
+	}
+}
+
+func TestServerForex(t *testing.T) {
+	s := testServer(t)
+	s.SetRate(bca.Currency{CurrencyCode: "USD", RateDetail: []bca.RateDetails{
+		{RateType: bca.RateTypeERate, Buy: bca.MustParseAmount("15500"), Sell: bca.MustParseAmount("15600")},
+		{RateType: bca.RateTypeBN, Buy: bca.MustParseAmount("15400"), Sell: bca.MustParseAmount("15700")},
+	}})
+
+	cfg := s.Config()
+	c := general.NewClient(cfg)
+	c.TokenSource = auth.NewTokenSource(cfg)
+
+	tests := []struct {
+		name                string
+		request             bca.ForeignExchangeRateRequest
+		wantRates           int
+		wantInvalidCurrency string
+	}{
+		{name: "every rate", request: bca.ForeignExchangeRateRequest{CurrencyCode: "USD"}, wantRates: 2},
+		{name: "rate type", request: bca.ForeignExchangeRateRequest{CurrencyCode: "USD", RateType: "bn"}, wantRates: 1},
+		{name: "every currency", request: bca.ForeignExchangeRateRequest{RateType: "erate"}, wantRates: 1},
+		{name: "unknown currency", request: bca.ForeignExchangeRateRequest{CurrencyCode: "USD,JPY"}, wantRates: 2, wantInvalidCurrency: "JPY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr_response, err := c.ForeignExchangeRate(context.Background(), &tt.request)
+			if err != nil || len((*ptr_response).Currencies) != 1 {
+				t.Fatalf("ForeignExchangeRate() = %+v, %v, want USD", ptr_response, err)
+			}
+			if rates := len((*ptr_response).Currencies[0].RateDetail); rates != tt.wantRates {
+				t.Errorf("got %d rates, want %d", rates, tt.wantRates)
+			}
+			if (*ptr_response).InvalidCurrency != tt.wantInvalidCurrency {
+				t.Errorf("InvalidCurrency = %q, want %q", (*ptr_response).InvalidCurrency, tt.wantInvalidCurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+//Package bcatest provides a recording fake of bca.API to unit test code built on the service clients without any network
+package bcatest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//Call represents a call received by Recorder
+type Call struct {
+	Method        string
+	Path          string
+	AccessToken   string
+	ContentType   string
+	Header        http.Header
+	Body          []byte
+	NonIdempotent bool
+}
+
+//Response represents a canned response returned by Recorder
+type Response struct {
+	// Body is unmarshalled into the response of the call. A []byte or string is used as raw JSON, anything else is marshalled first
+	Body interface{}
+	Err  error
+}
+
+//Recorder is a fake bca.API that records every call and answers with canned responses
+type Recorder struct {
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string][]Response
+}
+
+//NewRecorder is used to initialize new bcatest.Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		responses: map[string][]Response{},
+	}
+}
+
+//Respond queues a response for calls matching method and path. A path without query matches any query.
+//Responses are returned in order, the last one is repeated once the queue is drained
+func (r *Recorder) Respond(method, path string, responses ...Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := method + " " + path
+	r.responses[key] = append(r.responses[key], responses...)
+}
+
+//RespondJSON queues a successful response with body for calls matching method and path
+func (r *Recorder) RespondJSON(method, path string, body interface{}) {
+	r.Respond(method, path, Response{Body: body})
+}
+
+//RespondError queues a failed response for calls matching method and path
+func (r *Recorder) RespondError(method, path string, err error) {
+	r.Respond(method, path, Response{Err: err})
+}
+
+//Calls returns all recorded calls in order
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+//LastCall returns the most recent recorded call
+func (r *Recorder) LastCall() (Call, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.calls) == 0 {
+		return Call{}, false
+	}
+	return r.calls[len(r.calls)-1], true
+}
+
+//Reset drops all recorded calls and queued responses
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+	r.responses = map[string][]Response{}
+}
+
+//CallContext records the call and answers with the queued response
+func (r *Recorder) CallContext(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}) error {
+	return r.decode(ctx, Call{
+		Method:      method,
+		Path:        path,
+		AccessToken: accessToken,
+		ContentType: "application/json",
+		Header:      toHeader(additionalHeader),
+		Body:        body,
+	}, v)
+}
+
+//CallNonIdempotentContext records the call and answers with the queued response, resolve is never invoked
+func (r *Recorder) CallNonIdempotentContext(ctx context.Context, method, path, accessToken string, additionalHeader map[string]string, body []byte, v interface{}, resolve bca.OutcomeResolver) error {
+	return r.decode(ctx, Call{
+		Method:        method,
+		Path:          path,
+		AccessToken:   accessToken,
+		ContentType:   "application/json",
+		Header:        toHeader(additionalHeader),
+		Body:          body,
+		NonIdempotent: true,
+	}, v)
+}
+
+//CallRawContext records the call and answers with the queued response
+func (r *Recorder) CallRawContext(ctx context.Context, method, path, contentType string, headers http.Header, body io.Reader, v interface{}) error {
+	var buf []byte
+	if body != nil {
+		var err error
+		if buf, err = ioutil.ReadAll(body); err != nil {
+			return err
+		}
+	}
+
+	return r.decode(ctx, Call{
+		Method:      method,
+		Path:        path,
+		ContentType: contentType,
+		Header:      headers.Clone(),
+		Body:        buf,
+	}, v)
+}
+
+func (r *Recorder) decode(ctx context.Context, call Call, v interface{}) error {
+	body, err := r.record(ctx, call)
+	if err != nil || body == nil || v == nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(body)).Decode(v)
+}
+
+//record records the call and returns the body of its queued response
+func (r *Recorder) record(ctx context.Context, call Call) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	response, ok := r.next(call.Method, call.Path)
+	r.mu.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	switch b := response.Body.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return b, nil
+	case string:
+		return []byte(b), nil
+	default:
+		return json.Marshal(b)
+	}
+}
+
+//next pops the queued response for the call, keeping the last one. r.mu must be held
+func (r *Recorder) next(method, path string) (Response, bool) {
+	key := method + " " + path
+	if _, ok := r.responses[key]; !ok {
+		key = method + " " + strings.SplitN(path, "?", 2)[0]
+	}
+
+	queue := r.responses[key]
+	if len(queue) == 0 {
+		return Response{}, false
+	}
+	if len(queue) > 1 {
+		r.responses[key] = queue[1:]
+	}
+	return queue[0], true
+}
+
+func toHeader(additionalHeader map[string]string) http.Header {
+	header := http.Header{}
+	for key, val := range additionalHeader {
+		header.Add(key, val)
+	}
+	return header
+}
+
+var _ bca.API = (*Recorder)(nil)
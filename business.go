@@ -1,6 +1,9 @@
 package bca
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 //BalanceInformationRequest is to get your KlikBCA Bisnis account balance information with maximum of 20 accounts in a request
 type BalanceInformationRequest struct {
@@ -12,11 +15,11 @@ type BalanceInformationRequest struct {
 type BalanceSuccessBalanceInformationResponse struct {
 	AccountNumber    string
 	Currency         string
-	Balance          float64 `json:",string"`
-	AvailableBalance float64 `json:",string"`
-	FloatAmount      float64 `json:",string"`
-	HoldAmount       float64 `json:",string"`
-	Plafon           float64 `json:",string"`
+	Balance          Amount
+	AvailableBalance Amount
+	FloatAmount      Amount
+	HoldAmount       Amount
+	Plafon           Amount
 	Indonesian       string
 	English          string
 }
@@ -35,6 +38,16 @@ type BalanceInformationResponse struct {
 	AccountDetailDataFailed  []BalanceFailedBalanceInformationResponse  `json:",omitempty"`
 }
 
+//MaxBalanceInformationAccounts is the maximum number of accounts BCA accepts in a BalanceInformationRequest
+const MaxBalanceInformationAccounts = 20
+
+//AccountBalance represents the balance of an account, or the reason why it could not be retrieved
+type AccountBalance struct {
+	AccountNumber string
+	Balance       *BalanceSuccessBalanceInformationResponse
+	Failure       *ErrorLang
+}
+
 //AccountStatementRequest is to get your KlikBCA Bisnis account statement for a period up to 31 days
 type AccountStatementRequest struct {
 	CorporateID   string
@@ -48,7 +61,7 @@ type AccountStatement struct {
 	TransactionDate   string
 	BranchCode        string
 	TransactionType   string
-	TransactionAmount float64 `json:",string"`
+	TransactionAmount Amount
 	TransactionName   string
 	Trailer           string
 }
@@ -57,7 +70,7 @@ type AccountStatement struct {
 type AccountStatementResponse struct {
 	Error
 	Currency     string
-	StartBalance float64 `json:",string"`
+	StartBalance Amount
 	StartDate    string
 	EndDate      string
 	Data         []AccountStatement
@@ -71,12 +84,23 @@ type FundTransferRequest struct {
 	TransactionDate          string
 	ReferenceID              string
 	CurrencyCode             string
-	Amount                   float64 `json:",string"`
+	Amount                   Amount
 	BeneficiaryAccountNumber string
 	Remark1                  string
 	Remark2                  string
 }
 
+//MarshalJSON encodes the request with Amount in the decimals of CurrencyCode, failing when that would lose precision
+func (r FundTransferRequest) MarshalJSON() ([]byte, error) {
+	type plain FundTransferRequest
+	amount, err := r.Amount.ForCurrency(r.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	r.Amount = amount
+	return json.Marshal(plain(r))
+}
+
 //FundTransferResponse is to send fund transfer instructions to BCA using this service. The source of fund transfer must be from your corporate’s own deposit account. The recipient may be any deposit account within BCA
 type FundTransferResponse struct {
 	Error
@@ -95,16 +119,27 @@ type DomesticFundTransferRequest struct {
 	BeneficiaryAccountNumber string
 	BeneficiaryBankCode      string
 	BeneficiaryName          string
-	Amount                   float64 `json:",string"`
-	TransferType             string
-	BeneficiaryCustType      string
-	BeneficiaryCustResidence string
+	Amount                   Amount
+	TransferType             TransferType
+	BeneficiaryCustType      CustomerType
+	BeneficiaryCustResidence CustomerResidence
 	CurrencyCode             string
 	Remark1                  string
 	Remark2                  string
 	BeneficiaryEmail         string
 }
 
+//MarshalJSON encodes the request with Amount in the decimals of CurrencyCode, failing when that would lose precision
+func (r DomesticFundTransferRequest) MarshalJSON() ([]byte, error) {
+	type plain DomesticFundTransferRequest
+	amount, err := r.Amount.ForCurrency(r.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	r.Amount = amount
+	return json.Marshal(plain(r))
+}
+
 //DomesticFundTransferResponse is to send fund transfer instructions to BCA using this service. The source of fund transfer must be from your corporate's own deposit account. The recipient may be any deposit account within domestic bank except BCA.
 type DomesticFundTransferResponse struct {
 	Error
@@ -112,6 +147,8 @@ type DomesticFundTransferResponse struct {
 	TransactionDate string
 	ReferenceID     string
 	PPUNumber       string
+	// Status is not sent by BCA, it is set when the outcome of a failed attempt was resolved with InquiryTransferStatus
+	Status string `json:",omitempty"`
 }
 
 //AccountStatementOfflineRequest is to get your bulk statement in form of file for a period up to 7 days
@@ -131,7 +168,7 @@ type AccountStatementOfflineResponse struct {
 type InquiryTransferStatusRequest struct {
 	TransactionID   string
 	TransactionDate time.Time
-	TransferType    string
+	TransferType    TransferType
 }
 
 //ReasonInquiryTransferStatusResponse represents
@@ -144,13 +181,13 @@ type ReasonInquiryTransferStatusResponse struct {
 type InquiryTransferStatusResponse struct {
 	Error
 	TransactionID            string
-	TransactionDate          time.Time
-	TransferType             string
+	TransactionDate          string
+	TransferType             TransferType
 	SourceAccountNumber      string
 	BeneficiaryAccountNumber string
 	CurrencyCode             string
-	Amount                   float64 `json:",string"`
-	StatusCode               string
+	Amount                   Amount
+	StatusCode               TransferStatusCode
 	Reason                   ReasonInquiryTransferStatusResponse
 }
 
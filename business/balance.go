@@ -0,0 +1,155 @@
+package business
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//DefaultBalanceConcurrency is the number of BalanceInformation requests BalanceInformationBatch runs at once when concurrency is 0
+const DefaultBalanceConcurrency = 4
+
+var reasonNotReturned = bca.ErrorLang{
+	Indonesian: "Rekening tidak ada dalam respon",
+	English:    "Account is missing from the response",
+}
+
+var reasonInvalidAccount = bca.ErrorLang{
+	Indonesian: "Nomor rekening tidak valid",
+	English:    "Invalid account number",
+}
+
+//BalanceInformationBatch is used to get the balance of any number of accounts of c.CorporateID. The accounts are split into
+//requests of 20 that run with at most concurrency at once. Each account is reported with either its balance or the
+//reason why BCA could not retrieve it. On error the balances retrieved so far are returned along with it
+func (c *Client) BalanceInformationBatch(ctx context.Context, accountNumbers []string, concurrency int) (map[string]bca.AccountBalance, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultBalanceConcurrency
+	}
+
+	var unique []string
+	seen := map[string]bool{}
+	invalid := map[string]bca.AccountBalance{}
+	for _, accountNumber := range accountNumbers {
+		if seen[accountNumber] {
+			continue
+		}
+		seen[accountNumber] = true
+
+		// An invalid account would fail the validation of its whole request
+		if !validAccountNumber(accountNumber) {
+			reason := reasonInvalidAccount
+			invalid[accountNumber] = bca.AccountBalance{AccountNumber: accountNumber, Failure: &reason}
+			continue
+		}
+		unique = append(unique, accountNumber)
+	}
+
+	var chunks [][]string
+	for len(unique) > 0 {
+		n := bca.MaxBalanceInformationAccounts
+		if len(unique) < n {
+			n = len(unique)
+		}
+		chunks = append(chunks, unique[:n])
+		unique = unique[n:]
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		balances = make(map[string]bca.AccountBalance, len(seen))
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for accountNumber, balance := range invalid {
+		balances[accountNumber] = balance
+	}
+
+	for _, chunk := range chunks {
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			ptr_response, err := c.BalanceInformation(ctx, &bca.BalanceInformationRequest{
+				CorporateID:   c.CorporateID,
+				AccountNumber: strings.Join(chunk, ","),
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			mergeBalances(balances, chunk, ptr_response)
+		}(chunk)
+	}
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil && len(balances) < len(seen) {
+		firstErr = ctx.Err()
+	}
+	return balances, firstErr
+}
+
+//mergeBalances adds the result of every account in chunk to balances
+func mergeBalances(balances map[string]bca.AccountBalance, chunk []string, ptr_response *bca.BalanceInformationResponse) {
+	for i := range (*ptr_response).AccountDetailDataSuccess {
+		success := (*ptr_response).AccountDetailDataSuccess[i]
+		balances[success.AccountNumber] = bca.AccountBalance{
+			AccountNumber: success.AccountNumber,
+			Balance:       &success,
+		}
+	}
+
+	for _, failed := range (*ptr_response).AccountDetailDataFailed {
+		balances[failed.AccountNumber] = bca.AccountBalance{
+			AccountNumber: failed.AccountNumber,
+			Failure: &bca.ErrorLang{
+				Indonesian: failed.Indonesian,
+				English:    failed.English,
+			},
+		}
+	}
+
+	for _, accountNumber := range chunk {
+		if _, ok := balances[accountNumber]; !ok {
+			reason := reasonNotReturned
+			balances[accountNumber] = bca.AccountBalance{
+				AccountNumber: accountNumber,
+				Failure:       &reason,
+			}
+		}
+	}
+}
+
+//validAccountNumber reports whether accountNumber is a 10 digit BCA account number
+func validAccountNumber(accountNumber string) bool {
+	if len(accountNumber) != 10 {
+		return false
+	}
+	for _, ch := range accountNumber {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
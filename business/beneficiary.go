@@ -0,0 +1,238 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/internal/levenshtein"
+)
+
+//DefaultNameMatchThreshold is the lowest NameSimilarity accepted when BeneficiaryVerifier.Threshold is 0
+const DefaultNameMatchThreshold = 0.85
+
+//ErrBeneficiaryNameMismatch matches, with errors.Is, every *NameMismatchError
+var ErrBeneficiaryNameMismatch = errors.New("business: beneficiary name mismatch")
+
+//NameMismatchError is returned when the name of a beneficiary account does not match the BeneficiaryName of a transfer
+type NameMismatchError struct {
+	BankCode      string
+	AccountNumber string
+	// ExpectedName is the BeneficiaryName of the transfer and AccountName the name BCA returned for the account
+	ExpectedName string
+	AccountName  string
+	Similarity   float64
+	Threshold    float64
+}
+
+func (e *NameMismatchError) Error() string {
+	return fmt.Sprintf("business: beneficiary name %q does not match %q of account %s/%s (similarity %.2f, threshold %.2f)",
+		e.ExpectedName, e.AccountName, e.BankCode, e.AccountNumber, e.Similarity, e.Threshold)
+}
+
+//Is reports whether target is ErrBeneficiaryNameMismatch
+func (e *NameMismatchError) Is(target error) bool {
+	return target == ErrBeneficiaryNameMismatch
+}
+
+//BeneficiaryCache keeps the account names of verified beneficiaries so that they are not inquired again
+type BeneficiaryCache interface {
+	Get(bankCode, accountNumber string) (accountName string, ok bool)
+	Put(bankCode, accountNumber, accountName string)
+}
+
+//MemoryBeneficiaryCache is a BeneficiaryCache held in memory, entries expire after TTL unless it is 0
+type MemoryBeneficiaryCache struct {
+	TTL time.Duration
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedBeneficiary
+}
+
+type cachedBeneficiary struct {
+	accountName string
+	expiry      time.Time
+}
+
+//NewMemoryBeneficiaryCache is used to initialize new MemoryBeneficiaryCache
+func NewMemoryBeneficiaryCache(ttl time.Duration) *MemoryBeneficiaryCache {
+	return &MemoryBeneficiaryCache{TTL: ttl, entries: map[string]cachedBeneficiary{}}
+}
+
+//Get returns the account name of a verified beneficiary
+func (m *MemoryBeneficiaryCache) Get(bankCode, accountNumber string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := bankCode + "/" + accountNumber
+	entry, ok := m.entries[key]
+	if ok && !entry.expiry.IsZero() && m.now().After(entry.expiry) {
+		delete(m.entries, key)
+		return "", false
+	}
+	return entry.accountName, ok
+}
+
+//Put records the account name of a verified beneficiary
+func (m *MemoryBeneficiaryCache) Put(bankCode, accountNumber, accountName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = map[string]cachedBeneficiary{}
+	}
+	entry := cachedBeneficiary{accountName: accountName}
+	if m.TTL > 0 {
+		entry.expiry = m.now().Add(m.TTL)
+	}
+	m.entries[bankCode+"/"+accountNumber] = entry
+}
+
+func (m *MemoryBeneficiaryCache) now() time.Time {
+	if m.Now == nil {
+		return time.Now()
+	}
+	return m.Now()
+}
+
+//BeneficiaryVerifier represents how the name of a beneficiary account is checked before a domestic transfer
+type BeneficiaryVerifier struct {
+	// Threshold is the lowest NameSimilarity accepted, DefaultNameMatchThreshold when 0
+	Threshold float64
+	// Cache is optional, a cached beneficiary is matched against its cached name without inquiring BCA
+	Cache BeneficiaryCache
+}
+
+//VerifyDomesticBeneficiary is used to check with InquiryDomesticAccount that the beneficiary account of a domestic transfer
+//belongs to its BeneficiaryName. A *NameMismatchError is returned when it does not. A nil verifier uses the defaults
+func (c *Client) VerifyDomesticBeneficiary(ctx context.Context, ptr_domesticFundTransferRequest *bca.DomesticFundTransferRequest, verifier *BeneficiaryVerifier) (*bca.InquiryDomesticAccountResponse, error) {
+	if verifier == nil {
+		verifier = &BeneficiaryVerifier{}
+	}
+	threshold := verifier.Threshold
+	if threshold == 0 {
+		threshold = DefaultNameMatchThreshold
+	}
+
+	request := *ptr_domesticFundTransferRequest
+	account := bca.InquiryDomesticAccountResponse{
+		BeneficiaryBankCode:      request.BeneficiaryBankCode,
+		BeneficiaryAccountNumber: request.BeneficiaryAccountNumber,
+	}
+
+	cached := false
+	if verifier.Cache != nil {
+		account.BeneficiaryAccountName, cached = verifier.Cache.Get(request.BeneficiaryBankCode, request.BeneficiaryAccountNumber)
+	}
+	if !cached {
+		ptr_account, err := c.InquiryDomesticAccount(ctx, &bca.InquiryDomesticAccountRequest{
+			BeneficiaryAccountNumber: request.BeneficiaryAccountNumber,
+			BeneficiaryBankCode:      request.BeneficiaryBankCode,
+		})
+		if err != nil {
+			return ptr_account, err
+		}
+		account = *ptr_account
+	}
+
+	similarity := NameSimilarity(request.BeneficiaryName, account.BeneficiaryAccountName)
+	if similarity < threshold {
+		return &account, &NameMismatchError{
+			BankCode:      request.BeneficiaryBankCode,
+			AccountNumber: request.BeneficiaryAccountNumber,
+			ExpectedName:  request.BeneficiaryName,
+			AccountName:   account.BeneficiaryAccountName,
+			Similarity:    similarity,
+			Threshold:     threshold,
+		}
+	}
+
+	if verifier.Cache != nil && !cached {
+		verifier.Cache.Put(request.BeneficiaryBankCode, request.BeneficiaryAccountNumber, account.BeneficiaryAccountName)
+	}
+	return &account, nil
+}
+
+//VerifiedDomesticFundTransfer is like DomesticFundTransfer but only sends the transfer once VerifyDomesticBeneficiary succeeded
+func (c *Client) VerifiedDomesticFundTransfer(ctx context.Context, ptr_domesticFundTransferRequest *bca.DomesticFundTransferRequest, verifier *BeneficiaryVerifier) (*bca.DomesticFundTransferResponse, error) {
+	if _, err := c.VerifyDomesticBeneficiary(ctx, ptr_domesticFundTransferRequest, verifier); err != nil {
+		return &bca.DomesticFundTransferResponse{}, err
+	}
+	return c.DomesticFundTransfer(ctx, ptr_domesticFundTransferRequest)
+}
+
+//nameNoise are the honorifics, academic titles and legal forms dropped by NormalizeName
+var nameNoise = map[string]bool{
+	// Legal forms
+	"PT": true, "CV": true, "TBK": true, "UD": true, "PD": true, "FA": true, "PERSERO": true, "PERUM": true, "KOPERASI": true, "YAYASAN": true,
+	// Honorifics
+	"BAPAK": true, "BPK": true, "PAK": true, "IBU": true, "BU": true, "SDR": true, "SDRI": true, "SAUDARA": true, "SAUDARI": true,
+	"TUAN": true, "TN": true, "NYONYA": true, "NY": true, "NONA": true, "NN": true, "HAJI": true, "HJ": true, "H": true,
+	// Academic and professional titles
+	"DR": true, "DRS": true, "DRA": true, "IR": true, "PROF": true, "SH": true, "SE": true, "ST": true, "SKOM": true, "SPD": true,
+	"SSI": true, "SKED": true, "MM": true, "MH": true, "MSC": true, "MBA": true, "AMD": true,
+}
+
+//NormalizeName returns name in upper case without punctuation, honorifics, academic titles and legal forms such as PT and CV
+func NormalizeName(name string) string {
+	name = strings.ToUpper(name)
+	// Dots are dropped so that abbreviations such as P.T. and S.H. become words
+	name = strings.Replace(name, ".", "", -1)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if !nameNoise[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+//NameSimilarity scores how alike two names are after NormalizeName, from 0 to 1. Words may be in any order,
+//and a name cut short by BCA still matches the start of the full name
+func NameSimilarity(a, b string) float64 {
+	a, b = NormalizeName(a), NormalizeName(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	score := levenshtein.Ratio(a, b)
+	if sorted := levenshtein.Ratio(sortWords(a), sortWords(b)); sorted > score {
+		score = sorted
+	}
+
+	// Account names are cut to a fixed length, so a long name is compared with its start only
+	shorter, longer := []rune(a), []rune(b)
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) >= 20 {
+		if truncated := levenshtein.Ratio(string(shorter), string(longer[:len(shorter)])); truncated > score {
+			score = truncated
+		}
+	}
+	return score
+}
+
+func sortWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
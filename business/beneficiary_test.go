@@ -0,0 +1,140 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "PT. Sumber Makmur, Tbk", want: "SUMBER MAKMUR"},
+		{name: "P.T. Sumber Makmur", want: "SUMBER MAKMUR"},
+		{name: "Bpk. Dr. Budi Santoso, S.H., M.M.", want: "BUDI SANTOSO"},
+		{name: "  siti   aminah  ", want: "SITI AMINAH"},
+		{name: "CV Maju-Jaya 2", want: "MAJU JAYA 2"},
+		{name: "PT", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{name: "same after normalization", a: "PT Sumber Makmur", b: "SUMBER MAKMUR", atLeast: 1},
+		{name: "words in another order", a: "Santoso Budi", b: "Budi Santoso", atLeast: 1},
+		{name: "typo", a: "Budi Santosa", b: "Budi Santoso", atLeast: DefaultNameMatchThreshold},
+		{name: "cut short by BCA", a: "Sumber Makmur Sejahtera Abadi", b: "SUMBER MAKMUR SEJAHTER", atLeast: 1},
+		{name: "cut short with multibyte letters", a: "Ðuàn Sumber Makmur Sejahtera", b: "DUAN SUMBER MAKMUR SEJAH", atLeast: 0.9},
+		{name: "other person", a: "Budi Santoso", b: "Siti Aminah", below: 0.5},
+		{name: "empty", a: "PT", b: "Budi Santoso", below: 0.01},
+	}
+
+	for _, tt := range tests {
+		got := NameSimilarity(tt.a, tt.b)
+		if tt.atLeast > 0 && got < tt.atLeast {
+			t.Errorf("%s: NameSimilarity(%q, %q) = %.2f, want at least %.2f", tt.name, tt.a, tt.b, got, tt.atLeast)
+		}
+		if tt.below > 0 && got >= tt.below {
+			t.Errorf("%s: NameSimilarity(%q, %q) = %.2f, want below %.2f", tt.name, tt.a, tt.b, got, tt.below)
+		}
+	}
+}
+
+func TestMemoryBeneficiaryCache(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	cache := NewMemoryBeneficiaryCache(time.Hour)
+	cache.Now = func() time.Time { return now }
+
+	cache.Put("BRINIDJA", "8888801234", "SITI AMINAH")
+	if name, ok := cache.Get("BRINIDJA", "8888801234"); !ok || name != "SITI AMINAH" {
+		t.Errorf("Get() = %q, %v, want SITI AMINAH", name, ok)
+	}
+	if _, ok := cache.Get("BRINIDJA", "8888801235"); ok {
+		t.Error("Get() of another account = true, want false")
+	}
+
+	now = now.Add(time.Hour + time.Second)
+	if _, ok := cache.Get("BRINIDJA", "8888801234"); ok {
+		t.Error("Get() after TTL = true, want false")
+	}
+
+	// Without TTL entries never expire
+	cache.TTL = 0
+	cache.Put("BRINIDJA", "8888801234", "SITI AMINAH")
+	now = now.Add(24 * 365 * time.Hour)
+	if _, ok := cache.Get("BRINIDJA", "8888801234"); !ok {
+		t.Error("Get() without TTL = false, want true")
+	}
+}
+
+func TestVerifyDomesticBeneficiary(t *testing.T) {
+	accountPath := "/banking/corporates/transfers/v2/domestic/beneficiaries/banks/BRINIDJA/accounts/8888801234"
+
+	tests := []struct {
+		name            string
+		beneficiaryName string
+		cached          string
+		wantErr         error
+		wantInquiries   int
+		wantCached      bool
+	}{
+		{name: "inquired", beneficiaryName: "Siti Aminah", wantInquiries: 1, wantCached: true},
+		{name: "cached", beneficiaryName: "Ibu Siti Aminah", cached: "SITI AMINAH", wantCached: true},
+		{name: "mismatch", beneficiaryName: "Budi Santoso", wantErr: ErrBeneficiaryNameMismatch, wantInquiries: 1},
+		{name: "mismatch with the cached name", beneficiaryName: "Budi Santoso", cached: "SITI AMINAH", wantErr: ErrBeneficiaryNameMismatch, wantCached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.RespondJSON("GET", accountPath, bca.InquiryDomesticAccountResponse{
+				BeneficiaryBankCode:      "BRINIDJA",
+				BeneficiaryAccountNumber: "8888801234",
+				BeneficiaryAccountName:   "SITI AMINAH",
+			})
+			c := Client{Client: recorder}
+
+			cache := NewMemoryBeneficiaryCache(0)
+			if tt.cached != "" {
+				cache.Put("BRINIDJA", "8888801234", tt.cached)
+			}
+
+			_, err := c.VerifyDomesticBeneficiary(context.Background(), &bca.DomesticFundTransferRequest{
+				BeneficiaryBankCode:      "BRINIDJA",
+				BeneficiaryAccountNumber: "8888801234",
+				BeneficiaryName:          tt.beneficiaryName,
+			}, &BeneficiaryVerifier{Cache: cache})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyDomesticBeneficiary() = %v, want %v", err, tt.wantErr)
+			}
+			var mismatch *NameMismatchError
+			if tt.wantErr != nil && (!errors.As(err, &mismatch) || mismatch.AccountName != "SITI AMINAH" || mismatch.Threshold != DefaultNameMatchThreshold) {
+				t.Errorf("VerifyDomesticBeneficiary() = %#v, want a *NameMismatchError with SITI AMINAH", err)
+			}
+
+			if inquiries := len(recorder.Calls()); inquiries != tt.wantInquiries {
+				t.Errorf("inquired %d times, want %d", inquiries, tt.wantInquiries)
+			}
+			if _, ok := cache.Get("BRINIDJA", "8888801234"); ok != tt.wantCached {
+				t.Errorf("cached = %v, want %v", ok, tt.wantCached)
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@ package business
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
 	bca "github.com/ianeinser/bca-api-go"
 )
@@ -16,29 +18,78 @@ var (
 
 //Client is used to invoke BCA Business Banking API
 type Client struct {
-	Client       bca.APIImplementation
+	Client       bca.API
 	CorporateID  string
 	AccessToken  string
+	TokenSource  bca.TokenSource
 	ChannelID    string
 	CredentialID string
+	// OfflineStatements fetches the bulk statement files of AccountStatementOffline, which BCA delivers outside of the API
+	OfflineStatements OfflineStatementSource
+	// NotFoundErrorCodes are the error codes answered by InquiryTransferStatus for a transfer BCA has no record of,
+	// the ErrorCode of bca.ErrTransactionNotFound when empty
+	NotFoundErrorCodes []string
 }
 
 //NewClient is used to initialize new business.Client
 func NewClient(config bca.Config) Client {
+	api := bca.NewAPI(config)
 	return Client{
 		CorporateID:  config.CorporateID,
 		ChannelID:    config.ChannelID,
 		CredentialID: config.CredentialID,
-		Client:       bca.NewAPI(config),
+		Client:       &api,
 	}
 }
 
+//accessToken returns the token from TokenSource when it is set, otherwise the static AccessToken
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.TokenSource == nil {
+		return c.AccessToken, nil
+	}
+	return c.TokenSource.Token(ctx)
+}
+
+//checkToken drops the token cached by TokenSource when BCA rejected it, so that the next call fetches a new one
+func (c *Client) checkToken(err error) error {
+	if invalidator, ok := c.TokenSource.(bca.TokenInvalidator); ok && bca.TokenRejected(err) {
+		invalidator.Invalidate()
+	}
+	return err
+}
+
+//TransferNotFound reports whether an error of InquiryTransferStatus tells that BCA has no record of the transfer
+func (c *Client) TransferNotFound(err error) bool {
+	var bcaErr *bca.Error
+	if !errors.As(err, &bcaErr) {
+		return false
+	}
+	if len(c.NotFoundErrorCodes) == 0 {
+		return bcaErr.ErrorCode == bca.ErrTransactionNotFound.ErrorCode
+	}
+	for _, code := range c.NotFoundErrorCodes {
+		if bcaErr.ErrorCode == code {
+			return true
+		}
+	}
+	return false
+}
+
 //BalanceInformation is used to Get your KlikBCA Bisnis account balance information with maximum of 20 accounts in a request
 func (c *Client) BalanceInformation(ctx context.Context, ptr_balanceInformationRequest *bca.BalanceInformationRequest) (*bca.BalanceInformationResponse, error) {
 	var balanceInformationResponse bca.BalanceInformationResponse
+	if err := (*ptr_balanceInformationRequest).Validate(); err != nil {
+		return &balanceInformationResponse, err
+	}
+
 	path := fmt.Sprintf("/banking/v3/corporates/%s/accounts/%s", (*ptr_balanceInformationRequest).CorporateID, (*ptr_balanceInformationRequest).AccountNumber)
 
-	if err := c.Client.Call("GET", path, c.AccessToken, nil, nil, &balanceInformationResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &balanceInformationResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, nil, nil, &balanceInformationResponse)); err != nil {
 		return &balanceInformationResponse, err
 	}
 	return &balanceInformationResponse, nil
@@ -47,6 +98,10 @@ func (c *Client) BalanceInformation(ctx context.Context, ptr_balanceInformationR
 //AccountStatement is used to get your KlikBCA Bisnis account statement for a period up to 31 days
 func (c *Client) AccountStatement(ctx context.Context, ptr_accountStatementRequest *bca.AccountStatementRequest) (*bca.AccountStatementResponse, error) {
 	var accountStatementResponse bca.AccountStatementResponse
+	if err := (*ptr_accountStatementRequest).Validate(); err != nil {
+		return &accountStatementResponse, err
+	}
+
 	path := fmt.Sprintf("/banking/v3/corporates/%s/accounts/%s/statements", (*ptr_accountStatementRequest).CorporateID, (*ptr_accountStatementRequest).AccountNumber)
 
 	startDate := (*ptr_accountStatementRequest).StartDate
@@ -57,7 +112,12 @@ func (c *Client) AccountStatement(ctx context.Context, ptr_accountStatementReque
 	v.Add("EndDate", endDate.Format("2006-01-02"))
 	path += "?" + v.Encode()
 
-	if err := c.Client.Call("GET", path, c.AccessToken, nil, nil, &accountStatementResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &accountStatementResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, nil, nil, &accountStatementResponse)); err != nil {
 		return &accountStatementResponse, err
 	}
 	return &accountStatementResponse, nil
@@ -66,6 +126,9 @@ func (c *Client) AccountStatement(ctx context.Context, ptr_accountStatementReque
 //FundTransfer is used to send fund transfer instructions to BCA using this service. The source of fund transfer must be from corporate’s own deposit account. The recipient may be any deposit account within BCA
 func (c *Client) FundTransfer(ctx context.Context, ptr_fundTransferRequest *bca.FundTransferRequest) (*bca.FundTransferResponse, error) {
 	var fundTransferResponse bca.FundTransferResponse
+	if err := (*ptr_fundTransferRequest).Validate(); err != nil {
+		return &fundTransferResponse, err
+	}
 
 	jsonReq, err := json.Marshal(*ptr_fundTransferRequest)
 	if err != nil {
@@ -74,7 +137,29 @@ func (c *Client) FundTransfer(ctx context.Context, ptr_fundTransferRequest *bca.
 
 	path := "/banking/corporates/transfers"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &fundTransferResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &fundTransferResponse, err
+	}
+
+	transactionDate, err := parseTransactionDate((*ptr_fundTransferRequest).TransactionDate)
+	if err != nil {
+		return &fundTransferResponse, err
+	}
+
+	resolve := c.transferStatusResolver(&bca.InquiryTransferStatusRequest{
+		TransactionID:   (*ptr_fundTransferRequest).TransactionID,
+		TransactionDate: transactionDate,
+		TransferType:    bca.TransferTypeBCA,
+	}, func(ptr_status *bca.InquiryTransferStatusResponse, v interface{}) {
+		ptr_response := v.(*bca.FundTransferResponse)
+		(*ptr_response).TransactionID = (*ptr_status).TransactionID
+		(*ptr_response).TransactionDate = (*ptr_status).TransactionDate
+		(*ptr_response).ReferenceID = (*ptr_fundTransferRequest).ReferenceID
+		(*ptr_response).Status = string((*ptr_status).StatusCode)
+	})
+
+	if err := c.checkToken(c.Client.CallNonIdempotentContext(ctx, "POST", path, accessToken, nil, jsonReq, &fundTransferResponse, resolve)); err != nil {
 		return &fundTransferResponse, err
 	}
 	return &fundTransferResponse, nil
@@ -83,6 +168,9 @@ func (c *Client) FundTransfer(ctx context.Context, ptr_fundTransferRequest *bca.
 //DomesticFundTransfer is used to send fund transfer instructions to BCA using this service. The source of fund transfer must be from your corporate's own deposit account. The recipient may be any deposit account within domestic bank except BCA
 func (c *Client) DomesticFundTransfer(ctx context.Context, ptr_domesticFundTransferRequest *bca.DomesticFundTransferRequest) (*bca.DomesticFundTransferResponse, error) {
 	var domesticFundTransferResponse bca.DomesticFundTransferResponse
+	if err := (*ptr_domesticFundTransferRequest).Validate(); err != nil {
+		return &domesticFundTransferResponse, err
+	}
 
 	jsonReq, err := json.Marshal(*ptr_domesticFundTransferRequest)
 	if err != nil {
@@ -96,7 +184,29 @@ func (c *Client) DomesticFundTransfer(ctx context.Context, ptr_domesticFundTrans
 		httpHeaderCredentialID: c.CredentialID,
 	}
 
-	if err := c.Client.Call("POST", path, c.AccessToken, headers, jsonReq, &domesticFundTransferResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &domesticFundTransferResponse, err
+	}
+
+	transactionDate, err := parseTransactionDate((*ptr_domesticFundTransferRequest).TransactionDate)
+	if err != nil {
+		return &domesticFundTransferResponse, err
+	}
+
+	resolve := c.transferStatusResolver(&bca.InquiryTransferStatusRequest{
+		TransactionID:   (*ptr_domesticFundTransferRequest).TransactionID,
+		TransactionDate: transactionDate,
+		TransferType:    (*ptr_domesticFundTransferRequest).TransferType,
+	}, func(ptr_status *bca.InquiryTransferStatusResponse, v interface{}) {
+		ptr_response := v.(*bca.DomesticFundTransferResponse)
+		(*ptr_response).TransactionID = (*ptr_status).TransactionID
+		(*ptr_response).TransactionDate = (*ptr_status).TransactionDate
+		(*ptr_response).ReferenceID = (*ptr_domesticFundTransferRequest).ReferenceID
+		(*ptr_response).Status = string((*ptr_status).StatusCode)
+	})
+
+	if err := c.checkToken(c.Client.CallNonIdempotentContext(ctx, "POST", path, accessToken, headers, jsonReq, &domesticFundTransferResponse, resolve)); err != nil {
 		return &domesticFundTransferResponse, err
 	}
 	return &domesticFundTransferResponse, nil
@@ -105,6 +215,10 @@ func (c *Client) DomesticFundTransfer(ctx context.Context, ptr_domesticFundTrans
 //AccountStatementOffline is used to get your bulk statement in form of file for a period up to 7 days
 func (c *Client) AccountStatementOffline(ctx context.Context, ptr_accountStatementOfflineRequest *bca.AccountStatementOfflineRequest) (*bca.AccountStatementOfflineResponse, error) {
 	var accountStatementOfflineResponse bca.AccountStatementOfflineResponse
+	if err := (*ptr_accountStatementOfflineRequest).Validate(); err != nil {
+		return &accountStatementOfflineResponse, err
+	}
+
 	path := fmt.Sprintf("/banking/offline/corporates/accounts/%s/filestatements", (*ptr_accountStatementOfflineRequest).AccountNumber)
 
 	startDate := (*ptr_accountStatementOfflineRequest).StartDate
@@ -115,12 +229,12 @@ func (c *Client) AccountStatementOffline(ctx context.Context, ptr_accountStateme
 	v.Add("EndDate", endDate.Format("2006-01-02"))
 	path += "?" + v.Encode()
 
-	headers := map[string]string{
-		httpHeaderChannelID:    c.ChannelID,
-		httpHeaderCredentialID: c.CredentialID,
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &accountStatementOfflineResponse, err
 	}
 
-	if err := c.Client.Call("GET", path, c.AccessToken, headers, nil, &accountStatementOfflineResponse); err != nil {
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, c.offlineHeaders(), nil, &accountStatementOfflineResponse)); err != nil {
 		return &accountStatementOfflineResponse, err
 	}
 	return &accountStatementOfflineResponse, nil
@@ -129,13 +243,17 @@ func (c *Client) AccountStatementOffline(ctx context.Context, ptr_accountStateme
 //InquiryTransferStatus is used to get fund transfer status
 func (c *Client) InquiryTransferStatus(ctx context.Context, ptr_inquiryTransferStatusRequest *bca.InquiryTransferStatusRequest) (*bca.InquiryTransferStatusResponse, error) {
 	var inquiryTransferStatusResponse bca.InquiryTransferStatusResponse
+	if err := (*ptr_inquiryTransferStatusRequest).Validate(); err != nil {
+		return &inquiryTransferStatusResponse, err
+	}
+
 	path := fmt.Sprintf("/banking/corporates/transfers/status/%s", (*ptr_inquiryTransferStatusRequest).TransactionID)
 
 	transactionDate := (*ptr_inquiryTransferStatusRequest).TransactionDate
 
 	v := url.Values{}
 	v.Add("TransactionDate", transactionDate.Format("2006-01-02"))
-	v.Add("TransferType", (*ptr_inquiryTransferStatusRequest).TransferType)
+	v.Add("TransferType", string((*ptr_inquiryTransferStatusRequest).TransferType))
 	path += "?" + v.Encode()
 
 	headers := map[string]string{
@@ -143,7 +261,12 @@ func (c *Client) InquiryTransferStatus(ctx context.Context, ptr_inquiryTransferS
 		httpHeaderCredentialID: c.CredentialID,
 	}
 
-	if err := c.Client.Call("GET", path, c.AccessToken, headers, nil, &inquiryTransferStatusResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &inquiryTransferStatusResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, headers, nil, &inquiryTransferStatusResponse)); err != nil {
 		return &inquiryTransferStatusResponse, err
 	}
 	return &inquiryTransferStatusResponse, nil
@@ -152,6 +275,10 @@ func (c *Client) InquiryTransferStatus(ctx context.Context, ptr_inquiryTransferS
 //InquiryDomesticAccount is used to get beneficiary account information including beneficiary account name
 func (c *Client) InquiryDomesticAccount(ctx context.Context, ptr_inquiryDomesticAccountRequest *bca.InquiryDomesticAccountRequest) (*bca.InquiryDomesticAccountResponse, error) {
 	var inquiryDomesticAccountResponse bca.InquiryDomesticAccountResponse
+	if err := (*ptr_inquiryDomesticAccountRequest).Validate(); err != nil {
+		return &inquiryDomesticAccountResponse, err
+	}
+
 	path := fmt.Sprintf("/banking/corporates/transfers/v2/domestic/beneficiaries/banks/%s/accounts/%s", (*ptr_inquiryDomesticAccountRequest).BeneficiaryBankCode, (*ptr_inquiryDomesticAccountRequest).BeneficiaryAccountNumber)
 
 	headers := map[string]string{
@@ -159,8 +286,47 @@ func (c *Client) InquiryDomesticAccount(ctx context.Context, ptr_inquiryDomestic
 		httpHeaderCredentialID: c.CredentialID,
 	}
 
-	if err := c.Client.Call("GET", path, c.AccessToken, headers, nil, &inquiryDomesticAccountResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &inquiryDomesticAccountResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, headers, nil, &inquiryDomesticAccountResponse)); err != nil {
 		return &inquiryDomesticAccountResponse, err
 	}
 	return &inquiryDomesticAccountResponse, nil
 }
+
+//transferStatusResolver checks with InquiryTransferStatus whether a failed transfer attempt was applied by BCA. It is
+//sent again only when BCA answers that it has no record of the transfer
+func (c *Client) transferStatusResolver(ptr_inquiryTransferStatusRequest *bca.InquiryTransferStatusRequest, fill func(*bca.InquiryTransferStatusResponse, interface{})) bca.OutcomeResolver {
+	return func(ctx context.Context, v interface{}) (bool, error) {
+		ptr_status, err := c.InquiryTransferStatus(ctx, ptr_inquiryTransferStatusRequest)
+		if c.TransferNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		switch (*ptr_status).StatusCode {
+		case bca.TransferStatusSuccess:
+			fill(ptr_status, v)
+			return true, nil
+		case bca.TransferStatusFailed:
+			return false, fmt.Errorf("%w: %s", bca.ErrTransferFailed, (*ptr_status).Reason.English)
+		case bca.TransferStatusPending:
+			return false, bca.ErrTransferPending
+		}
+		return false, fmt.Errorf("business: unknown transfer status %q", (*ptr_status).StatusCode)
+	}
+}
+
+//parseTransactionDate parses the TransactionDate of a transfer request
+func parseTransactionDate(transactionDate string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", transactionDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("business: invalid TransactionDate %q: %v", transactionDate, err)
+	}
+	return date, nil
+}
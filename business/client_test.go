@@ -0,0 +1,178 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+)
+
+func TestTransferStatusResolver(t *testing.T) {
+	statusPath := "/banking/corporates/transfers/status/00000001"
+	notFound := *bca.ErrTransactionNotFound
+	notFound.HTTPStatus = http.StatusNotFound
+	tooManyRequests := &bca.Error{HTTPStatus: http.StatusTooManyRequests}
+
+	tests := []struct {
+		name        string
+		response    bcatest.Response
+		wantApplied bool
+		wantErr     error
+		wantStatus  string
+	}{
+		{
+			name:     "not found is sent again",
+			response: bcatest.Response{Err: &notFound},
+		},
+		{
+			name:     "unauthorized is not resolved",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusUnauthorized, ErrorCode: bca.ErrExpiredToken.ErrorCode}},
+			wantErr:  bca.ErrExpiredToken,
+		},
+		{
+			name:     "too many requests is not resolved",
+			response: bcatest.Response{Err: tooManyRequests},
+			wantErr:  tooManyRequests,
+		},
+		{
+			name:     "invalid signature is not resolved",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusBadRequest, ErrorCode: bca.ErrInvalidSignature.ErrorCode}},
+			wantErr:  bca.ErrInvalidSignature,
+		},
+		{
+			name:        "success is applied",
+			response:    bcatest.Response{Body: bca.InquiryTransferStatusResponse{TransactionID: "00000001", TransactionDate: "2026-10-18", StatusCode: bca.TransferStatusSuccess}},
+			wantApplied: true,
+			wantStatus:  "Success",
+		},
+		{
+			name:     "failed is surfaced",
+			response: bcatest.Response{Body: bca.InquiryTransferStatusResponse{StatusCode: bca.TransferStatusFailed}},
+			wantErr:  bca.ErrTransferFailed,
+		},
+		{
+			name:     "pending is surfaced",
+			response: bcatest.Response{Body: bca.InquiryTransferStatusResponse{StatusCode: bca.TransferStatusPending}},
+			wantErr:  bca.ErrTransferPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.Respond("GET", statusPath, tt.response)
+			c := Client{Client: recorder}
+
+			resolve := c.transferStatusResolver(&bca.InquiryTransferStatusRequest{
+				TransactionID:   "00000001",
+				TransactionDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+				TransferType:    bca.TransferTypeLLG,
+			}, func(ptr_status *bca.InquiryTransferStatusResponse, v interface{}) {
+				(*v.(*bca.DomesticFundTransferResponse)).Status = string((*ptr_status).StatusCode)
+			})
+
+			var response bca.DomesticFundTransferResponse
+			applied, err := resolve(context.Background(), &response)
+			if applied != tt.wantApplied {
+				t.Errorf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if response.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", response.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestParseTransactionDate(t *testing.T) {
+	tests := []struct {
+		transactionDate string
+		want            time.Time
+		wantErr         bool
+	}{
+		{transactionDate: "2026-10-18", want: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{transactionDate: "18-10-2026", wantErr: true},
+		{transactionDate: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTransactionDate(tt.transactionDate)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTransactionDate(%q) err = %v, wantErr %v", tt.transactionDate, err, tt.wantErr)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTransactionDate(%q) = %v, want %v", tt.transactionDate, got, tt.want)
+		}
+	}
+}
+
+//invalidatingTokenSource counts the calls to Invalidate
+type invalidatingTokenSource struct {
+	invalidated int
+}
+
+func (ts *invalidatingTokenSource) Token(ctx context.Context) (string, error) {
+	return "token", nil
+}
+
+func (ts *invalidatingTokenSource) Invalidate() {
+	ts.invalidated++
+}
+
+func TestClientInvalidatesRejectedToken(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		wantInvalidated int
+	}{
+		{name: "unauthorized", err: &bca.Error{HTTPStatus: http.StatusUnauthorized}, wantInvalidated: 1},
+		{name: "expired token", err: &bca.Error{HTTPStatus: http.StatusBadRequest, ErrorCode: bca.ErrExpiredToken.ErrorCode}, wantInvalidated: 1},
+		{name: "server error", err: &bca.Error{HTTPStatus: http.StatusBadGateway}},
+		{name: "success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.RespondError("GET", "/banking/v3/corporates/BCAAPI2016/accounts/0201245680", tt.err)
+			ts := &invalidatingTokenSource{}
+			c := Client{Client: recorder, TokenSource: ts}
+
+			c.BalanceInformation(context.Background(), &bca.BalanceInformationRequest{CorporateID: "BCAAPI2016", AccountNumber: "0201245680"})
+			if ts.invalidated != tt.wantInvalidated {
+				t.Errorf("invalidated %d times, want %d", ts.invalidated, tt.wantInvalidated)
+			}
+		})
+	}
+}
+
+func TestTransferNotFound(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []string
+		err   error
+		want  bool
+	}{
+		{name: "default", err: &bca.Error{HTTPStatus: http.StatusNotFound, ErrorCode: bca.ErrTransactionNotFound.ErrorCode}, want: true},
+		{name: "default other code", err: &bca.Error{HTTPStatus: http.StatusNotFound, ErrorCode: "ESB-99-999"}},
+		{name: "configured", codes: []string{"ESB-82-027"}, err: &bca.Error{HTTPStatus: http.StatusNotFound, ErrorCode: "ESB-82-027"}, want: true},
+		{name: "configured replaces default", codes: []string{"ESB-82-027"}, err: &bca.Error{HTTPStatus: http.StatusNotFound, ErrorCode: bca.ErrTransactionNotFound.ErrorCode}},
+		{name: "not a BCA error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		c := Client{NotFoundErrorCodes: tt.codes}
+		if got := c.TransferNotFound(tt.err); got != tt.want {
+			t.Errorf("%s: TransferNotFound(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
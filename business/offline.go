@@ -0,0 +1,92 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//ErrOfflineStatementNotReady is returned by an OfflineStatementSource while the bulk statement file is not delivered yet,
+//and by WaitAccountStatementOffline when it is still not delivered after the last poll
+var ErrOfflineStatementNotReady = errors.New("business: offline statement file not ready")
+
+//ErrNoOfflineStatementSource is returned when the bulk statement file is fetched from a Client without OfflineStatements
+var ErrNoOfflineStatementSource = errors.New("business: Client has no OfflineStatements source")
+
+//DefaultOfflinePollPolicy is how WaitAccountStatementOffline polls when no policy is given, until ctx is done
+var DefaultOfflinePollPolicy = bca.RetryPolicy{
+	InitialBackoff: 5 * time.Second,
+	MaxBackoff:     time.Minute,
+	Multiplier:     1.5,
+	Jitter:         0.1,
+}
+
+//OfflineStatementSource fetches the bulk statement files requested with AccountStatementOffline. BCA delivers them
+//outside of the API, such as to the SFTP server of the corporate, so the source is provided by the application
+type OfflineStatementSource interface {
+	// OpenStatementFile returns the file of requestID, ErrOfflineStatementNotReady while it is not delivered yet
+	OpenStatementFile(ctx context.Context, accountNumber, requestID string) (io.ReadCloser, error)
+}
+
+//WaitAccountStatementOffline polls c.OfflineStatements, backing off according to policy, until the bulk statement file is delivered.
+//policy.MaxAttempts limits the number of polls, 0 polls until ctx is done. A nil policy means DefaultOfflinePollPolicy.
+//The file must be closed, use bca.NewStatementFileReader to parse it
+func (c *Client) WaitAccountStatementOffline(ctx context.Context, accountNumber, requestID string, policy *bca.RetryPolicy) (io.ReadCloser, error) {
+	if c.OfflineStatements == nil {
+		return nil, ErrNoOfflineStatementSource
+	}
+	if policy == nil {
+		policy = &DefaultOfflinePollPolicy
+	}
+
+	for attempt := 1; ; attempt++ {
+		file, err := c.OfflineStatements.OpenStatementFile(ctx, accountNumber, requestID)
+		if !errors.Is(err, ErrOfflineStatementNotReady) {
+			return file, err
+		}
+
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return nil, err
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+//AccountStatementOfflineFile requests the bulk statement file of a period, waits until c.OfflineStatements delivers it
+//according to policy and copies it into w
+func (c *Client) AccountStatementOfflineFile(ctx context.Context, ptr_accountStatementOfflineRequest *bca.AccountStatementOfflineRequest, policy *bca.RetryPolicy, w io.Writer) (*bca.AccountStatementOfflineResponse, error) {
+	if c.OfflineStatements == nil {
+		return &bca.AccountStatementOfflineResponse{}, ErrNoOfflineStatementSource
+	}
+
+	ptr_response, err := c.AccountStatementOffline(ctx, ptr_accountStatementOfflineRequest)
+	if err != nil {
+		return ptr_response, err
+	}
+
+	file, err := c.WaitAccountStatementOffline(ctx, (*ptr_accountStatementOfflineRequest).AccountNumber, (*ptr_response).RequestID, policy)
+	if err != nil {
+		return ptr_response, err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return ptr_response, err
+}
+
+func (c *Client) offlineHeaders() map[string]string {
+	return map[string]string{
+		httpHeaderChannelID:    c.ChannelID,
+		httpHeaderCredentialID: c.CredentialID,
+	}
+}
@@ -0,0 +1,199 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//Jakarta is the Asia/Jakarta (WIB) timezone the clearing windows are set in. It has no daylight saving time,
+//so a fixed zone is used instead of depending on the tz database of the host
+var Jakarta = time.FixedZone("WIB", 7*60*60)
+
+//ErrNoTransferWindow is returned by Router.Route when no transfer type opens within a year, such as when every day is a holiday
+var ErrNoTransferWindow = errors.New("business: no transfer window within a year")
+
+//Rail represents the amount limits and the daily window of a domestic transfer type
+type Rail struct {
+	TransferType bca.TransferType
+	// MinAmount is exclusive and MaxAmount inclusive, a zero MaxAmount has no limit
+	MinAmount bca.Amount
+	MaxAmount bca.Amount
+	// Open and Close are the times of day the transfer type accepts transfers, from midnight in Jakarta.
+	// Close is exclusive, zero or 24 hours keeps it open until midnight
+	Open  time.Duration
+	Close time.Duration
+	// BusinessDaysOnly closes the transfer type on weekends and on the days of the HolidayCalendar
+	BusinessDaysOnly bool
+}
+
+//Accepts reports whether amount is within the limits of the rail
+func (r Rail) Accepts(amount bca.Amount) bool {
+	if amount.Cmp(r.MinAmount) <= 0 {
+		return false
+	}
+	return r.MaxAmount.IsZero() || amount.Cmp(r.MaxAmount) <= 0
+}
+
+//DefaultRails returns the transfer types from the cheapest, with the bca.TransferTypeLimits and the usual BCA
+//cut-offs: ONL all day every day, LLG from 08:00 to 14:00 and RTG from 08:00 to 15:00 on business days.
+//Check the cut-offs of your agreement with BCA, they may be earlier
+func DefaultRails() []Rail {
+	rails := []Rail{
+		{
+			TransferType: bca.TransferTypeONL,
+		},
+		{
+			TransferType:     bca.TransferTypeLLG,
+			Open:             8 * time.Hour,
+			Close:            14 * time.Hour,
+			BusinessDaysOnly: true,
+		},
+		{
+			TransferType:     bca.TransferTypeRTG,
+			Open:             8 * time.Hour,
+			Close:            15 * time.Hour,
+			BusinessDaysOnly: true,
+		},
+	}
+	for i := range rails {
+		rails[i].MinAmount, rails[i].MaxAmount, _ = bca.TransferTypeLimits(rails[i].TransferType)
+	}
+	return rails
+}
+
+//HolidayCalendar tells the days clearing is closed besides weekends
+type HolidayCalendar interface {
+	IsHoliday(date time.Time) bool
+}
+
+//HolidayDates is a HolidayCalendar of dates in the yyyy-MM-dd format, such as the national holidays and collective leave days
+type HolidayDates map[string]bool
+
+//NewHolidayDates is used to initialize new HolidayDates
+func NewHolidayDates(dates ...string) HolidayDates {
+	h := HolidayDates{}
+	for _, date := range dates {
+		h[date] = true
+	}
+	return h
+}
+
+//IsHoliday reports whether the day of date in Jakarta is one of the dates
+func (h HolidayDates) IsHoliday(date time.Time) bool {
+	return h[date.In(Jakarta).Format("2006-01-02")]
+}
+
+//Router represents how the transfer type of a domestic transfer is chosen
+type Router struct {
+	// Rails are tried from the first, which should be the cheapest. DefaultRails when nil
+	Rails []Rail
+	// Holidays is optional, weekends are always closed for the rails open on business days only
+	Holidays HolidayCalendar
+	// Directory tells the transfer types each bank receives, bca.DefaultBankDirectory when nil
+	Directory *bca.BankDirectory
+}
+
+//Route represents the transfer type chosen by a Router
+type Route struct {
+	TransferType bca.TransferType
+	// Open is whether TransferType accepts transfers now, otherwise NextWindow is when it opens
+	Open       bool
+	NextWindow time.Time
+}
+
+//Route returns the cheapest transfer type the bank of bankCode receives for amount that is open at now. When every one
+//of them is closed, the Route is not Open and holds the transfer type opening first with its NextWindow.
+//bca.ErrNoTransferType is returned when no transfer type accepts the amount
+func (r *Router) Route(amount bca.Amount, bankCode string, now time.Time) (Route, error) {
+	directory := r.Directory
+	if directory == nil {
+		directory = bca.DefaultBankDirectory()
+	}
+	bank, ok := directory.ByCode(bankCode)
+	if !ok {
+		return Route{}, fmt.Errorf("business: unknown bank code %q", bankCode)
+	}
+
+	rails := r.Rails
+	if rails == nil {
+		rails = DefaultRails()
+	}
+
+	var next Route
+	valid := false
+	for _, rail := range rails {
+		if !bank.Supports(rail.TransferType) || !rail.Accepts(amount) {
+			continue
+		}
+		valid = true
+
+		opening, ok := r.nextOpening(rail, now)
+		if !ok {
+			continue
+		}
+		if !opening.After(now) {
+			return Route{TransferType: rail.TransferType, Open: true}, nil
+		}
+		if next.NextWindow.IsZero() || opening.Before(next.NextWindow) {
+			next = Route{TransferType: rail.TransferType, NextWindow: opening}
+		}
+	}
+
+	if !valid {
+		return Route{}, bca.ErrNoTransferType
+	}
+	if next.NextWindow.IsZero() {
+		return Route{}, ErrNoTransferWindow
+	}
+	return next, nil
+}
+
+//RouteDomesticFundTransfer is used to set the TransferType of a domestic transfer with Route when one is open now.
+//The request is left as it is when the Route is not Open
+func (r *Router) RouteDomesticFundTransfer(ptr_domesticFundTransferRequest *bca.DomesticFundTransferRequest, now time.Time) (Route, error) {
+	route, err := r.Route((*ptr_domesticFundTransferRequest).Amount, (*ptr_domesticFundTransferRequest).BeneficiaryBankCode, now)
+	if err != nil {
+		return route, err
+	}
+	if route.Open {
+		(*ptr_domesticFundTransferRequest).TransferType = route.TransferType
+	}
+	return route, nil
+}
+
+//nextOpening returns now when the rail is open, otherwise the time it opens next in Jakarta
+func (r *Router) nextOpening(rail Rail, now time.Time) (time.Time, bool) {
+	now = now.In(Jakarta)
+	year, month, day := now.Date()
+	for i := 0; i <= 366; i++ {
+		midnight := time.Date(year, month, day+i, 0, 0, 0, 0, Jakarta)
+		if rail.BusinessDaysOnly && !r.isBusinessDay(midnight) {
+			continue
+		}
+
+		closing := rail.Close
+		if closing == 0 {
+			closing = 24 * time.Hour
+		}
+		open, close := midnight.Add(rail.Open), midnight.Add(closing)
+		if !now.Before(close) {
+			continue
+		}
+		if now.Before(open) {
+			return open, true
+		}
+		return now, true
+	}
+	return time.Time{}, false
+}
+
+func (r *Router) isBusinessDay(date time.Time) bool {
+	switch date.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return r.Holidays == nil || !r.Holidays.IsHoliday(date)
+}
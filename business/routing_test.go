@@ -0,0 +1,151 @@
+package business
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//alwaysHoliday is a HolidayCalendar closing every day
+type alwaysHoliday struct{}
+
+func (alwaysHoliday) IsHoliday(date time.Time) bool {
+	return true
+}
+
+//wib returns the time of day on 2026-10-d in Jakarta, 2026-10-16 being a Friday
+func wib(d, hour, min int) time.Time {
+	return time.Date(2026, 10, d, hour, min, 0, 0, Jakarta)
+}
+
+func TestRouterRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		bankCode string
+		amount   string
+		now      time.Time
+		holidays HolidayCalendar
+		rails    []Rail
+		want     Route
+		wantErr  error
+	}{
+		{
+			name: "online", bankCode: "BRINIDJA", amount: "100000000", now: wib(16, 10, 0),
+			want: Route{TransferType: bca.TransferTypeONL, Open: true},
+		},
+		{
+			name: "online on a Sunday night", bankCode: "BRINIDJA", amount: "100000000", now: wib(18, 23, 59),
+			want: Route{TransferType: bca.TransferTypeONL, Open: true},
+		},
+		{
+			name: "above the online limit", bankCode: "BRINIDJA", amount: "300000000", now: wib(16, 10, 0),
+			want: Route{TransferType: bca.TransferTypeLLG, Open: true},
+		},
+		{
+			name: "time given in UTC", bankCode: "BRINIDJA", amount: "300000000", now: time.Date(2026, 10, 16, 6, 59, 0, 0, time.UTC),
+			want: Route{TransferType: bca.TransferTypeLLG, Open: true},
+		},
+		{
+			name: "LLG cut-off passed", bankCode: "BRINIDJA", amount: "300000000", now: wib(16, 14, 0),
+			want: Route{TransferType: bca.TransferTypeRTG, Open: true},
+		},
+		{
+			name: "every cut-off passed", bankCode: "BRINIDJA", amount: "300000000", now: wib(16, 15, 0),
+			want: Route{TransferType: bca.TransferTypeLLG, NextWindow: wib(19, 8, 0)},
+		},
+		{
+			name: "before opening", bankCode: "MHCCIDJA", amount: "100000000", now: wib(16, 7, 0),
+			want: Route{TransferType: bca.TransferTypeLLG, NextWindow: wib(16, 8, 0)},
+		},
+		{
+			name: "weekend", bankCode: "MHCCIDJA", amount: "100000000", now: wib(17, 10, 0),
+			want: Route{TransferType: bca.TransferTypeLLG, NextWindow: wib(19, 8, 0)},
+		},
+		{
+			name: "holiday after the weekend", bankCode: "MHCCIDJA", amount: "100000000", now: wib(17, 10, 0), holidays: NewHolidayDates("2026-10-19"),
+			want: Route{TransferType: bca.TransferTypeLLG, NextWindow: wib(20, 8, 0)},
+		},
+		{
+			name: "above the LLG limit", bankCode: "MHCCIDJA", amount: "2000000000", now: wib(16, 10, 0),
+			want: Route{TransferType: bca.TransferTypeRTG, Open: true},
+		},
+		{
+			name: "below the RTGS minimum of an RTGS only bank", bankCode: "CHASIDJX", amount: "100000000", now: wib(16, 10, 0),
+			wantErr: bca.ErrNoTransferType,
+		},
+		{
+			name: "every day a holiday", bankCode: "MHCCIDJA", amount: "100000000", now: wib(16, 10, 0), holidays: alwaysHoliday{},
+			wantErr: ErrNoTransferWindow,
+		},
+		{
+			name: "rail without cut-off", bankCode: "BRINIDJA", amount: "100", now: wib(16, 23, 59),
+			rails: []Rail{{TransferType: bca.TransferTypeONL, Open: 6 * time.Hour}},
+			want:  Route{TransferType: bca.TransferTypeONL, Open: true},
+		},
+		{
+			name: "rail without cut-off before opening", bankCode: "BRINIDJA", amount: "100", now: wib(16, 5, 0),
+			rails: []Rail{{TransferType: bca.TransferTypeONL, Open: 6 * time.Hour}},
+			want:  Route{TransferType: bca.TransferTypeONL, NextWindow: wib(16, 6, 0)},
+		},
+		{
+			name: "custom limit", bankCode: "BRINIDJA", amount: "100000001", now: wib(16, 10, 0),
+			rails: []Rail{
+				{TransferType: bca.TransferTypeONL, MaxAmount: bca.MustParseAmount("100000000")},
+				{TransferType: bca.TransferTypeRTG, MinAmount: bca.MustParseAmount("100000000"), Open: 8 * time.Hour, Close: 15 * time.Hour},
+			},
+			want: Route{TransferType: bca.TransferTypeRTG, Open: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := Router{Rails: tt.rails, Holidays: tt.holidays}
+			got, err := router.Route(bca.MustParseAmount(tt.amount), tt.bankCode, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Route() = %v, want %v", err, tt.wantErr)
+			}
+			if got.TransferType != tt.want.TransferType || got.Open != tt.want.Open || !got.NextWindow.Equal(tt.want.NextWindow) {
+				t.Errorf("Route() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&Router{}).Route(bca.MustParseAmount("100"), "UNKNOWN", wib(16, 10, 0)); err == nil {
+		t.Error("Route() to an unknown bank = nil, want an error")
+	}
+}
+
+func TestRouterRouteDomesticFundTransfer(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want bca.TransferType
+	}{
+		{name: "open", now: wib(16, 10, 0), want: bca.TransferTypeLLG},
+		{name: "closed", now: wib(17, 10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := bca.DomesticFundTransferRequest{BeneficiaryBankCode: "MHCCIDJA", Amount: bca.MustParseAmount("100000000")}
+			route, err := (&Router{}).RouteDomesticFundTransfer(&request, tt.now)
+			if err != nil {
+				t.Fatalf("RouteDomesticFundTransfer() = %v", err)
+			}
+			if request.TransferType != tt.want || route.TransferType != bca.TransferTypeLLG {
+				t.Errorf("TransferType = %q, route %+v, want %q", request.TransferType, route, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRails(t *testing.T) {
+	for _, rail := range DefaultRails() {
+		min, max, _ := bca.TransferTypeLimits(rail.TransferType)
+		if !rail.MinAmount.Equal(min) || !rail.MaxAmount.Equal(max) {
+			t.Errorf("%s limits = %s, %s, want %s, %s", rail.TransferType, rail.MinAmount, rail.MaxAmount, min, max)
+		}
+	}
+}
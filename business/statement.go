@@ -0,0 +1,79 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//AccountStatementRange is used to get the typed account statement of any period. The period is split into windows
+//of up to 31 days that are fetched in order. Pending entries, which BCA reports in every window, are only kept once
+//from the last window. A row with an invalid date or dated outside of its window is an error
+func (c *Client) AccountStatementRange(ctx context.Context, ptr_accountStatementRequest *bca.AccountStatementRequest) (*bca.Statement, error) {
+	request := *ptr_accountStatementRequest
+	start := truncateDay(request.StartDate)
+	end := truncateDay(request.EndDate)
+	if end.Before(start) {
+		return nil, errors.New("business: EndDate is before StartDate")
+	}
+
+	statement := bca.Statement{
+		AccountNumber: request.AccountNumber,
+		StartDate:     start,
+		EndDate:       end,
+	}
+
+	var pending []bca.StatementEntry
+	for windowStart := start; !windowStart.After(end); {
+		windowEnd := windowStart.AddDate(0, 0, bca.MaxAccountStatementDays-1)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		ptr_response, err := c.AccountStatement(ctx, &bca.AccountStatementRequest{
+			CorporateID:   request.CorporateID,
+			AccountNumber: request.AccountNumber,
+			StartDate:     windowStart,
+			EndDate:       windowEnd,
+		})
+		if err != nil {
+			return &statement, err
+		}
+
+		if windowStart.Equal(start) {
+			statement.Currency = (*ptr_response).Currency
+			statement.StartBalance = (*ptr_response).StartBalance
+		}
+
+		pending = nil
+		for _, row := range (*ptr_response).Data {
+			entry, err := bca.NewStatementEntry(row, windowStart, windowEnd)
+			if err != nil {
+				return &statement, err
+			}
+			if entry.Pending {
+				pending = append(pending, entry)
+				continue
+			}
+			statement.Entries = append(statement.Entries, entry)
+		}
+
+		windowStart = windowEnd.AddDate(0, 0, 1)
+	}
+	statement.Entries = append(statement.Entries, pending...)
+
+	balance := statement.StartBalance
+	for i := range statement.Entries {
+		balance = statement.Entries[i].Apply(balance)
+		statement.Entries[i].Balance = balance
+	}
+	statement.EndBalance = balance
+
+	return &statement, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -20,6 +20,9 @@ type Config struct {
 	UserID          string
 	LocalID         string
 
+	RetryPolicy *RetryPolicy
+	Middlewares []Middleware
+
 	LogLevel int
 	LogPath  string
 }
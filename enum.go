@@ -0,0 +1,482 @@
+package bca
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+//EnumValue describes an allowed value of a BCA code, such as a TransferType, to build selection lists
+type EnumValue struct {
+	Value      string
+	Indonesian string
+	English    string
+}
+
+var strictEnums int32
+
+//SetStrictEnums makes the JSON encoding and decoding of BCA codes fail on values the library does not know.
+//It is off by default, so that codes added by BCA do not break existing integrations
+func SetStrictEnums(strict bool) {
+	var flag int32
+	if strict {
+		flag = 1
+	}
+	atomic.StoreInt32(&strictEnums, flag)
+}
+
+//StrictEnums reports whether SetStrictEnums is on
+func StrictEnums() bool {
+	return atomic.LoadInt32(&strictEnums) == 1
+}
+
+//TransferType represents the network of a transfer
+type TransferType string
+
+//Transfer types, BCA is only used to inquire the status of a transfer between BCA accounts
+const (
+	TransferTypeBCA TransferType = "BCA"
+	TransferTypeLLG TransferType = "LLG"
+	TransferTypeRTG TransferType = "RTG"
+	TransferTypeONL TransferType = "ONL"
+)
+
+var transferTypes = enumValues{
+	{Value: "BCA", Indonesian: "Transfer antar rekening BCA", English: "Transfer between BCA accounts"},
+	{Value: "LLG", Indonesian: "Transfer SKN (kliring)", English: "SKN clearing transfer"},
+	{Value: "RTG", Indonesian: "Transfer RTGS", English: "RTGS transfer"},
+	{Value: "ONL", Indonesian: "Transfer online", English: "Online transfer"},
+}
+
+//TransferTypes returns every TransferType with its description
+func TransferTypes() []EnumValue {
+	return transferTypes.list()
+}
+
+func (t TransferType) String() string {
+	return string(t)
+}
+
+//Valid reports whether t is a known TransferType
+func (t TransferType) Valid() bool {
+	return transferTypes.valid(string(t))
+}
+
+//Description returns the description of t, empty when it is unknown
+func (t TransferType) Description() EnumValue {
+	return transferTypes.describe(string(t))
+}
+
+//MarshalJSON encodes t as a string, failing on unknown values in strict mode
+func (t TransferType) MarshalJSON() ([]byte, error) {
+	return transferTypes.marshal("TransferType", string(t))
+}
+
+//UnmarshalJSON decodes t from a string, failing on unknown values in strict mode
+func (t *TransferType) UnmarshalJSON(data []byte) error {
+	str, err := transferTypes.unmarshal("TransferType", data)
+	*t = TransferType(str)
+	return err
+}
+
+//RateType represents the kind of a foreign exchange rate
+type RateType string
+
+//Rate types of a foreign exchange rate
+const (
+	RateTypeERate RateType = "erate"
+	RateTypeTT    RateType = "tt"
+	RateTypeTC    RateType = "tc"
+	RateTypeBN    RateType = "bn"
+)
+
+var rateTypes = enumValues{
+	{Value: "erate", Indonesian: "Kurs e-Rate", English: "e-Rate"},
+	{Value: "tt", Indonesian: "Kurs transfer (TT)", English: "Telegraphic transfer"},
+	{Value: "tc", Indonesian: "Kurs travellers cheque", English: "Travellers cheque"},
+	{Value: "bn", Indonesian: "Kurs uang kertas asing", English: "Bank notes"},
+}
+
+//RateTypes returns every RateType with its description
+func RateTypes() []EnumValue {
+	return rateTypes.list()
+}
+
+func (t RateType) String() string {
+	return string(t)
+}
+
+//Valid reports whether t is a known RateType
+func (t RateType) Valid() bool {
+	return rateTypes.valid(string(t))
+}
+
+//Description returns the description of t, empty when it is unknown
+func (t RateType) Description() EnumValue {
+	return rateTypes.describe(string(t))
+}
+
+//MarshalJSON encodes t as a string, failing on unknown values in strict mode
+func (t RateType) MarshalJSON() ([]byte, error) {
+	return rateTypes.marshal("RateType", string(t))
+}
+
+//UnmarshalJSON decodes t from a string, failing on unknown values in strict mode
+func (t *RateType) UnmarshalJSON(data []byte) error {
+	str, err := rateTypes.unmarshal("RateType", data)
+	*t = RateType(str)
+	return err
+}
+
+//CustomerType represents the kind of the beneficiary of a domestic transfer
+type CustomerType string
+
+//Customer types of the beneficiary of a domestic transfer
+const (
+	CustomerTypeIndividual CustomerType = "1"
+	CustomerTypeCorporate  CustomerType = "2"
+	CustomerTypeGovernment CustomerType = "3"
+)
+
+var customerTypes = enumValues{
+	{Value: "1", Indonesian: "Perorangan", English: "Individual"},
+	{Value: "2", Indonesian: "Perusahaan", English: "Corporate"},
+	{Value: "3", Indonesian: "Pemerintah", English: "Government"},
+}
+
+//CustomerTypes returns every CustomerType with its description
+func CustomerTypes() []EnumValue {
+	return customerTypes.list()
+}
+
+func (t CustomerType) String() string {
+	return string(t)
+}
+
+//Valid reports whether t is a known CustomerType
+func (t CustomerType) Valid() bool {
+	return customerTypes.valid(string(t))
+}
+
+//Description returns the description of t, empty when it is unknown
+func (t CustomerType) Description() EnumValue {
+	return customerTypes.describe(string(t))
+}
+
+//MarshalJSON encodes t as a string, failing on unknown values in strict mode
+func (t CustomerType) MarshalJSON() ([]byte, error) {
+	return customerTypes.marshal("CustomerType", string(t))
+}
+
+//UnmarshalJSON decodes t from a string, failing on unknown values in strict mode
+func (t *CustomerType) UnmarshalJSON(data []byte) error {
+	str, err := customerTypes.unmarshal("CustomerType", data)
+	*t = CustomerType(str)
+	return err
+}
+
+//CustomerResidence represents whether the beneficiary of a domestic transfer resides in Indonesia
+type CustomerResidence string
+
+//Residences of the beneficiary of a domestic transfer
+const (
+	CustomerResidenceResident    CustomerResidence = "1"
+	CustomerResidenceNonResident CustomerResidence = "2"
+)
+
+var customerResidences = enumValues{
+	{Value: "1", Indonesian: "Penduduk", English: "Resident"},
+	{Value: "2", Indonesian: "Bukan penduduk", English: "Non-resident"},
+}
+
+//CustomerResidences returns every CustomerResidence with its description
+func CustomerResidences() []EnumValue {
+	return customerResidences.list()
+}
+
+func (r CustomerResidence) String() string {
+	return string(r)
+}
+
+//Valid reports whether r is a known CustomerResidence
+func (r CustomerResidence) Valid() bool {
+	return customerResidences.valid(string(r))
+}
+
+//Description returns the description of r, empty when it is unknown
+func (r CustomerResidence) Description() EnumValue {
+	return customerResidences.describe(string(r))
+}
+
+//MarshalJSON encodes r as a string, failing on unknown values in strict mode
+func (r CustomerResidence) MarshalJSON() ([]byte, error) {
+	return customerResidences.marshal("CustomerResidence", string(r))
+}
+
+//UnmarshalJSON decodes r from a string, failing on unknown values in strict mode
+func (r *CustomerResidence) UnmarshalJSON(data []byte) error {
+	str, err := customerResidences.unmarshal("CustomerResidence", data)
+	*r = CustomerResidence(str)
+	return err
+}
+
+//BankCodeType represents the kind of BankCodeValue identifying the bank of a FIRe beneficiary
+type BankCodeType string
+
+//Bank code types of a FIRe beneficiary
+const (
+	BankCodeTypeBIC BankCodeType = "BIC"
+	BankCodeTypeCH  BankCodeType = "CH"
+	BankCodeTypeNCC BankCodeType = "NCC"
+)
+
+var bankCodeTypes = enumValues{
+	{Value: "BIC", Indonesian: "Kode SWIFT (BIC)", English: "SWIFT code (BIC)"},
+	{Value: "CH", Indonesian: "Kode CHIPS", English: "CHIPS participant code"},
+	{Value: "NCC", Indonesian: "Kode kliring nasional", English: "National clearing code"},
+}
+
+//BankCodeTypes returns every BankCodeType with its description
+func BankCodeTypes() []EnumValue {
+	return bankCodeTypes.list()
+}
+
+func (t BankCodeType) String() string {
+	return string(t)
+}
+
+//Valid reports whether t is a known BankCodeType
+func (t BankCodeType) Valid() bool {
+	return bankCodeTypes.valid(string(t))
+}
+
+//Description returns the description of t, empty when it is unknown
+func (t BankCodeType) Description() EnumValue {
+	return bankCodeTypes.describe(string(t))
+}
+
+//MarshalJSON encodes t as a string, failing on unknown values in strict mode
+func (t BankCodeType) MarshalJSON() ([]byte, error) {
+	return bankCodeTypes.marshal("BankCodeType", string(t))
+}
+
+//UnmarshalJSON decodes t from a string, failing on unknown values in strict mode
+func (t *BankCodeType) UnmarshalJSON(data []byte) error {
+	str, err := bankCodeTypes.unmarshal("BankCodeType", data)
+	*t = BankCodeType(str)
+	return err
+}
+
+//DetailOfCharges represents who bears the charges of a FIRe transfer
+type DetailOfCharges string
+
+//Bearers of the charges of a FIRe transfer
+const (
+	DetailOfChargesOur DetailOfCharges = "OUR"
+	DetailOfChargesSha DetailOfCharges = "SHA"
+	DetailOfChargesBen DetailOfCharges = "BEN"
+)
+
+var detailsOfCharges = enumValues{
+	{Value: "OUR", Indonesian: "Biaya ditanggung pengirim", English: "Charges borne by the sender"},
+	{Value: "SHA", Indonesian: "Biaya ditanggung bersama", English: "Charges shared"},
+	{Value: "BEN", Indonesian: "Biaya ditanggung penerima", English: "Charges borne by the beneficiary"},
+}
+
+//DetailsOfCharges returns every DetailOfCharges with its description
+func DetailsOfCharges() []EnumValue {
+	return detailsOfCharges.list()
+}
+
+func (d DetailOfCharges) String() string {
+	return string(d)
+}
+
+//Valid reports whether d is a known DetailOfCharges
+func (d DetailOfCharges) Valid() bool {
+	return detailsOfCharges.valid(string(d))
+}
+
+//Description returns the description of d, empty when it is unknown
+func (d DetailOfCharges) Description() EnumValue {
+	return detailsOfCharges.describe(string(d))
+}
+
+//MarshalJSON encodes d as a string, failing on unknown values in strict mode
+func (d DetailOfCharges) MarshalJSON() ([]byte, error) {
+	return detailsOfCharges.marshal("DetailOfCharges", string(d))
+}
+
+//UnmarshalJSON decodes d from a string, failing on unknown values in strict mode
+func (d *DetailOfCharges) UnmarshalJSON(data []byte) error {
+	str, err := detailsOfCharges.unmarshal("DetailOfCharges", data)
+	*d = DetailOfCharges(str)
+	return err
+}
+
+//PurposeCode represents the purpose of a FIRe transfer. BCA gives the purpose codes along with the FIRe agreement, they are
+//not part of the published API documentation, so the library does not list them nor reject unknown ones in strict mode
+type PurposeCode string
+
+func (c PurposeCode) String() string {
+	return string(c)
+}
+
+//IdentificationType represents the identity document of a FIRe sender or beneficiary. As with PurposeCode, BCA gives the
+//accepted values along with the FIRe agreement, so the library does not list them nor reject unknown ones in strict mode
+type IdentificationType string
+
+func (t IdentificationType) String() string {
+	return string(t)
+}
+
+//TransferStatusCode represents the status of a transfer in InquiryTransferStatusResponse
+type TransferStatusCode string
+
+//Statuses of a transfer
+const (
+	TransferStatusSuccess TransferStatusCode = "Success"
+	TransferStatusFailed  TransferStatusCode = "Failed"
+	TransferStatusPending TransferStatusCode = "Pending"
+)
+
+var transferStatusCodes = enumValues{
+	{Value: "Success", Indonesian: "Transfer berhasil", English: "Transfer succeeded"},
+	{Value: "Failed", Indonesian: "Transfer gagal", English: "Transfer failed"},
+	{Value: "Pending", Indonesian: "Transfer sedang diproses", English: "Transfer in process"},
+}
+
+//TransferStatusCodes returns every TransferStatusCode with its description
+func TransferStatusCodes() []EnumValue {
+	return transferStatusCodes.list()
+}
+
+func (c TransferStatusCode) String() string {
+	return string(c)
+}
+
+//Valid reports whether c is a known TransferStatusCode
+func (c TransferStatusCode) Valid() bool {
+	return transferStatusCodes.valid(string(c))
+}
+
+//Description returns the description of c, empty when it is unknown
+func (c TransferStatusCode) Description() EnumValue {
+	return transferStatusCodes.describe(string(c))
+}
+
+//MarshalJSON encodes c as a string, failing on unknown values in strict mode
+func (c TransferStatusCode) MarshalJSON() ([]byte, error) {
+	return transferStatusCodes.marshal("TransferStatusCode", string(c))
+}
+
+//UnmarshalJSON decodes c from a string, failing on unknown values in strict mode
+func (c *TransferStatusCode) UnmarshalJSON(data []byte) error {
+	str, err := transferStatusCodes.unmarshal("TransferStatusCode", data)
+	*c = TransferStatusCode(str)
+	return err
+}
+
+//PaymentFlagStatus represents the status of a Virtual Account payment in InquiryStatusPaymentResponse
+type PaymentFlagStatus string
+
+//Payment flag statuses of a Virtual Account payment
+const (
+	PaymentFlagStatusSuccess PaymentFlagStatus = "Success"
+	PaymentFlagStatusFailed  PaymentFlagStatus = "Failed"
+	PaymentFlagStatusTimeout PaymentFlagStatus = "Timeout"
+)
+
+var paymentFlagStatuses = enumValues{
+	{Value: "Success", Indonesian: "Pembayaran berhasil", English: "Payment succeeded"},
+	{Value: "Failed", Indonesian: "Pembayaran ditolak", English: "Payment rejected"},
+	{Value: "Timeout", Indonesian: "Pembayaran melewati batas waktu", English: "Payment timed out"},
+}
+
+//paymentFlagStatusCodes maps the FlagStatus codes of payment flag messages, which BCA also sends in place of the names
+var paymentFlagStatusCodes = map[string]PaymentFlagStatus{
+	FlagStatusSuccess:  PaymentFlagStatusSuccess,
+	FlagStatusRejected: PaymentFlagStatusFailed,
+	FlagStatusTimeout:  PaymentFlagStatusTimeout,
+}
+
+//PaymentFlagStatuses returns every PaymentFlagStatus with its description
+func PaymentFlagStatuses() []EnumValue {
+	return paymentFlagStatuses.list()
+}
+
+func (s PaymentFlagStatus) String() string {
+	return string(s)
+}
+
+//Valid reports whether s is a known PaymentFlagStatus
+func (s PaymentFlagStatus) Valid() bool {
+	return paymentFlagStatuses.valid(string(s))
+}
+
+//Description returns the description of s, empty when it is unknown
+func (s PaymentFlagStatus) Description() EnumValue {
+	return paymentFlagStatuses.describe(string(s))
+}
+
+//MarshalJSON encodes s as a string, failing on unknown values in strict mode
+func (s PaymentFlagStatus) MarshalJSON() ([]byte, error) {
+	return paymentFlagStatuses.marshal("PaymentFlagStatus", string(s))
+}
+
+//UnmarshalJSON decodes s from its name or its FlagStatus code, failing on unknown values in strict mode
+func (s *PaymentFlagStatus) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	if status, ok := paymentFlagStatusCodes[str]; ok {
+		str = string(status)
+	}
+
+	*s = PaymentFlagStatus(str)
+	return paymentFlagStatuses.check("PaymentFlagStatus", str)
+}
+
+//enumValues is the table of the known values of an enum type
+type enumValues []EnumValue
+
+func (e enumValues) list() []EnumValue {
+	return append([]EnumValue(nil), e...)
+}
+
+func (e enumValues) valid(value string) bool {
+	return e.describe(value).Value != ""
+}
+
+func (e enumValues) describe(value string) EnumValue {
+	for _, v := range e {
+		if v.Value == value {
+			return v
+		}
+	}
+	return EnumValue{}
+}
+
+//check fails on unknown values in strict mode, an empty value is always accepted as the field is optional
+func (e enumValues) check(name, value string) error {
+	if value != "" && StrictEnums() && !e.valid(value) {
+		return fmt.Errorf("bca: unknown %s %q", name, value)
+	}
+	return nil
+}
+
+func (e enumValues) marshal(name, value string) ([]byte, error) {
+	if err := e.check(name, value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func (e enumValues) unmarshal(name string, data []byte) (string, error) {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return "", err
+	}
+	return str, e.check(name, str)
+}
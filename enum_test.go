@@ -0,0 +1,105 @@
+package bca
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnumJSON(t *testing.T) {
+	t.Cleanup(func() { SetStrictEnums(false) })
+
+	tests := []struct {
+		name  string
+		known string
+		// value converts a string to the enum type, target returns a pointer to decode into
+		value  func(str string) interface{}
+		target func() interface{}
+	}{
+		{name: "TransferType", known: "RTG", value: func(str string) interface{} { return TransferType(str) }, target: func() interface{} { return new(TransferType) }},
+		{name: "RateType", known: "erate", value: func(str string) interface{} { return RateType(str) }, target: func() interface{} { return new(RateType) }},
+		{name: "CustomerType", known: "1", value: func(str string) interface{} { return CustomerType(str) }, target: func() interface{} { return new(CustomerType) }},
+		{name: "CustomerResidence", known: "1", value: func(str string) interface{} { return CustomerResidence(str) }, target: func() interface{} { return new(CustomerResidence) }},
+		{name: "BankCodeType", known: "BIC", value: func(str string) interface{} { return BankCodeType(str) }, target: func() interface{} { return new(BankCodeType) }},
+		{name: "DetailOfCharges", known: "SHA", value: func(str string) interface{} { return DetailOfCharges(str) }, target: func() interface{} { return new(DetailOfCharges) }},
+		{name: "TransferStatusCode", known: "Success", value: func(str string) interface{} { return TransferStatusCode(str) }, target: func() interface{} { return new(TransferStatusCode) }},
+		{name: "PaymentFlagStatus", known: "Success", value: func(str string) interface{} { return PaymentFlagStatus(str) }, target: func() interface{} { return new(PaymentFlagStatus) }},
+	}
+
+	for _, tt := range tests {
+		for _, strict := range []bool{false, true} {
+			name := tt.name
+			if strict {
+				name += " strict"
+			}
+			t.Run(name, func(t *testing.T) {
+				SetStrictEnums(strict)
+
+				for _, str := range []string{tt.known, ""} {
+					data, err := json.Marshal(tt.value(str))
+					if err != nil || string(data) != `"`+str+`"` {
+						t.Errorf("Marshal(%q) = %s, %v, want %q", str, data, err, str)
+					}
+					target := tt.target()
+					if err := json.Unmarshal([]byte(`"`+str+`"`), target); err != nil || reflect.ValueOf(target).Elem().String() != str {
+						t.Errorf("Unmarshal(%q) = %v, %v, want %q", str, reflect.ValueOf(target).Elem(), err, str)
+					}
+				}
+
+				_, err := json.Marshal(tt.value("UNKNOWN"))
+				if strict && (err == nil || !strings.Contains(err.Error(), tt.name)) {
+					t.Errorf("Marshal(UNKNOWN) = %v, want an unknown %s error", err, tt.name)
+				}
+				if !strict && err != nil {
+					t.Errorf("Marshal(UNKNOWN) = %v, want no error", err)
+				}
+
+				target := tt.target()
+				err = json.Unmarshal([]byte(`"UNKNOWN"`), target)
+				if strict && (err == nil || !strings.Contains(err.Error(), tt.name)) {
+					t.Errorf("Unmarshal(UNKNOWN) = %v, want an unknown %s error", err, tt.name)
+				}
+				if !strict && (err != nil || reflect.ValueOf(target).Elem().String() != "UNKNOWN") {
+					t.Errorf("Unmarshal(UNKNOWN) = %v, %v, want UNKNOWN", reflect.ValueOf(target).Elem(), err)
+				}
+			})
+		}
+	}
+}
+
+func TestPaymentFlagStatusFromCode(t *testing.T) {
+	t.Cleanup(func() { SetStrictEnums(false) })
+	SetStrictEnums(true)
+
+	tests := []struct {
+		code string
+		want PaymentFlagStatus
+	}{
+		{code: FlagStatusSuccess, want: PaymentFlagStatusSuccess},
+		{code: FlagStatusRejected, want: PaymentFlagStatusFailed},
+		{code: FlagStatusTimeout, want: PaymentFlagStatusTimeout},
+	}
+
+	for _, tt := range tests {
+		var status PaymentFlagStatus
+		if err := json.Unmarshal([]byte(`"`+tt.code+`"`), &status); err != nil || status != tt.want {
+			t.Errorf("Unmarshal(%q) = %s, %v, want %s", tt.code, status, err, tt.want)
+		}
+	}
+}
+
+func TestEnumDescription(t *testing.T) {
+	if !TransferTypeRTG.Valid() || TransferTypeRTG.Description().English != "RTGS transfer" {
+		t.Errorf("RTG = %v, %+v, want a valid RTGS transfer", TransferTypeRTG.Valid(), TransferTypeRTG.Description())
+	}
+	if unknown := TransferType("UNKNOWN"); unknown.Valid() || unknown.Description() != (EnumValue{}) {
+		t.Errorf("UNKNOWN = %v, %+v, want invalid without description", unknown.Valid(), unknown.Description())
+	}
+
+	types := TransferTypes()
+	types[0].Value = "changed"
+	if TransferTypes()[0].Value != "BCA" {
+		t.Error("TransferTypes() returned the table itself, want a copy")
+	}
+}
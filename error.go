@@ -1,7 +1,15 @@
 package bca
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
 //Error represent BCA error response messsage
 type Error struct {
+	HTTPStatus   int `json:"-"`
 	ErrorCode    string
 	ErrorMessage ErrorLang
 }
@@ -11,3 +19,107 @@ type ErrorLang struct {
 	Indonesian string
 	English    string
 }
+
+//Common BCA error codes, to be used with errors.Is
+var (
+	ErrInvalidSignature       = &Error{ErrorCode: "ESB-14-001", ErrorMessage: ErrorLang{Indonesian: "HMAC tidak cocok", English: "HMAC mismatch"}}
+	ErrExpiredToken           = &Error{ErrorCode: "ESB-14-009", ErrorMessage: ErrorLang{Indonesian: "Tidak berhak", English: "Unauthorized"}}
+	ErrDuplicateTransactionID = &Error{ErrorCode: "ESB-82-019", ErrorMessage: ErrorLang{Indonesian: "Transaksi ID sudah pernah digunakan", English: "Duplicate transaction ID"}}
+	ErrInsufficientFunds      = &Error{ErrorCode: "ESB-82-008", ErrorMessage: ErrorLang{Indonesian: "Saldo tidak cukup", English: "Insufficient fund"}}
+	// ErrTransactionNotFound is answered by InquiryTransferStatus for a transfer BCA has no record of. The Business Banking
+	// API documentation does not list it, it is the answer of bcasandbox: confirm it with BCA and set
+	// business.Client.NotFoundErrorCodes when your environment answers otherwise
+	ErrTransactionNotFound = &Error{ErrorCode: "ESB-14-010", ErrorMessage: ErrorLang{Indonesian: "Data tidak ditemukan", English: "Data not found"}}
+)
+
+//ErrTransferFailed is returned when the status inquiry made to resolve a failed transfer attempt reports it Failed
+var ErrTransferFailed = errors.New("bca: transfer failed")
+
+//ErrTransferPending is returned when the status inquiry made to resolve a failed transfer attempt reports it still Pending
+var ErrTransferPending = errors.New("bca: transfer pending")
+
+//errorResponse is implemented by the responses embedding Error
+type errorResponse interface {
+	resetError()
+}
+
+//resetError clears the error fields a response was filled with
+func (e *Error) resetError() {
+	*e = Error{}
+}
+
+//Error returns the BCA error code and its English message
+func (e *Error) Error() string {
+	message := e.ErrorMessage.English
+	if message == "" {
+		message = e.ErrorMessage.Indonesian
+	}
+	if message == "" {
+		message = http.StatusText(e.HTTPStatus)
+	}
+
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("bca: %s (HTTP %d)", message, e.HTTPStatus)
+	}
+	return fmt.Sprintf("bca: %s %s (HTTP %d)", e.ErrorCode, message, e.HTTPStatus)
+}
+
+//Is reports whether target is a BCA error with the same error code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t.ErrorCode == "" {
+		return false
+	}
+	return t.ErrorCode == e.ErrorCode
+}
+
+//Rejected reports whether err is BCA or Validate refusing a request, which was then not applied. Errors that
+//do not tell whether the request was processed, such as 5xx, 401, 408 and 429 responses or an invalid signature, are not rejections
+func Rejected(err error) bool {
+	if errors.Is(err, ErrValidation) {
+		return true
+	}
+
+	var bcaErr *Error
+	if !errors.As(err, &bcaErr) || bcaErr.HTTPStatus < 400 || bcaErr.HTTPStatus >= 500 {
+		return false
+	}
+	switch bcaErr.HTTPStatus {
+	case http.StatusUnauthorized, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return !errors.Is(err, ErrInvalidSignature) && !errors.Is(err, ErrExpiredToken)
+}
+
+//MultiError holds several errors, such as a failed call and the failure to record it. errors.Is and errors.As
+//match any of them
+type MultiError []error
+
+//Error returns the messages of the errors separated by semicolons
+func (m MultiError) Error() string {
+	messages := make([]string, 0, len(m))
+	for _, err := range m {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+//Is reports whether any of the errors matches target
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+//As finds the first of the errors that matches target
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
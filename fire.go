@@ -1,5 +1,21 @@
 package bca
 
+import "encoding/json"
+
+//StatusTransactionSuccess is the StatusTransaction of a successful FIRe response
+const StatusTransactionSuccess = "0000"
+
+//StatusTransactionNotFound is the StatusTransaction answered by InquiryTransaction for a transaction FIRe has no record of.
+//The FIRe API documentation does not list it, it is the answer of bcasandbox: confirm it with BCA and set
+//fire.Client.NotFoundStatuses when your FIRe environment answers otherwise
+const StatusTransactionNotFound = "0001"
+
+//InquiryBy values used in TransactionInquiryTransactionRequest
+const (
+	InquiryByReferenceNumber = "R"
+	InquiryByFormNumber      = "F"
+)
+
 //Auth represents authentication info used in FIRe transaction
 type Auth struct {
 	CorporateID string
@@ -21,7 +37,7 @@ type SenderAccountRequest struct {
 	PostalCode           string
 	CountryID            string
 	Mobile               string
-	IdentificationType   string
+	IdentificationType   IdentificationType
 	IdentificationNumber string
 	AccountNumber        string
 }
@@ -37,11 +53,11 @@ type BeneficiaryAccountRequest struct {
 	PostalCode           string
 	CountryID            string
 	Mobile               string
-	IdentificationType   string
+	IdentificationType   IdentificationType
 	IdentificationNumber string
 	NationalityID        string
 	Occupation           string
-	BankCodeType         string
+	BankCodeType         BankCodeType
 	BankCodeValue        string
 	BankCountryID        string
 	BankAddress          string
@@ -52,15 +68,26 @@ type BeneficiaryAccountRequest struct {
 //TransactionTTAccountRequest represents transaction details used in TTAccountRequest
 type TransactionAccountRequest struct {
 	CurrencyID      string
-	Amount          float64 `json:",string"`
-	PurposeCode     string
+	Amount          Amount
+	PurposeCode     PurposeCode
 	Description1    string
 	Description2    string
-	DetailOfCharges string
+	DetailOfCharges DetailOfCharges
 	SourceOfFund    string
 	FormNumber      string
 }
 
+//MarshalJSON encodes the request with Amount in the decimals of CurrencyID, failing when that would lose precision
+func (r TransactionAccountRequest) MarshalJSON() ([]byte, error) {
+	type plain TransactionAccountRequest
+	amount, err := r.Amount.ForCurrency(r.CurrencyID)
+	if err != nil {
+		return nil, err
+	}
+	r.Amount = amount
+	return json.Marshal(plain(r))
+}
+
 //TeleTransferAccountRequest is to provides service transaction “Transaction to BCA’s Account” and also “Transfer to Other Bank”
 type TeleTransferAccountRequest struct {
 	Authentication     Auth
@@ -79,7 +106,7 @@ type BeneficiaryAccountResponse struct {
 //TransactionTTAccountResponse represents transaction details for response message
 type TransactionAccountResponse struct {
 	CurrencyID        string
-	Amount            float64 `json:",string"`
+	Amount            Amount
 	Description1      string
 	Description2      string
 	FormNumber        string
@@ -100,7 +127,7 @@ type TeleTransferAccountResponse struct {
 
 //BeneficiaryInquiryAccountRequest represents beneficiary details to inquire account(s)
 type BeneficiaryInquiryAccountRequest struct {
-	BankCodeType  string
+	BankCodeType  BankCodeType
 	BankCodeValue string
 	AccountNumber string
 }
@@ -138,7 +165,7 @@ type InquiryAccountBalanceRequest struct {
 //FIInquiryAccountBalanceResponse represents FID details in response message
 type FIInquiryAccountBalanceResponse struct {
 	CurrencyID     string
-	AccountBalance float64 `json:",string"`
+	AccountBalance Amount
 }
 
 //TTInquiryAccountBalanceResponse is to provide service to Inquiry balance for Vostro’s Account.
@@ -170,14 +197,14 @@ type SenderInquiryTransactionResponse struct {
 //BeneficiaryInquiryTransactionResponse represents beneficiary details for response message
 type BeneficiaryInquiryTransactionResponse struct {
 	Name          string
-	BankCodeType  string
+	BankCodeType  BankCodeType
 	BankCodeValue string
 	AccountNumber string
 }
 
 //TransactionInquiryTransactionResponse represents transaction details for response message
 type TransactionTTInquiryTransactionResponse struct {
-	AmountPaid      float64 `json:",string"`
+	AmountPaid      Amount
 	CurrencyID      string
 	ReleaseDateTime string
 	LocalID         string
@@ -210,7 +237,7 @@ type SenderTeleTransferCashTransferRequest struct {
 	PostalCode           string
 	CountryID            string
 	Mobile               string
-	IdentificationType   string
+	IdentificationType   IdentificationType
 	IdentificationNumber string
 }
 
@@ -225,7 +252,7 @@ type BeneficiaryTeleTransferCashTransferRequest struct {
 	PostalCode           string
 	CountryID            string
 	Mobile               string
-	IdentificationType   string
+	IdentificationType   IdentificationType
 	IdentificationNumber string
 	NationalityID        string
 	Occupation           string
@@ -237,15 +264,26 @@ type TransactionTeleTransferCashTransferRequest struct {
 	SecretQuestion  string
 	SecretAnswer    string
 	CurrencyID      string
-	Amount          float64 `json:",string"`
-	PurposeCode     string
+	Amount          Amount
+	PurposeCode     PurposeCode
 	Description1    string
 	Description2    string
-	DetailOfCharges string
+	DetailOfCharges DetailOfCharges
 	SourceOfFund    string
 	FormNumber      string
 }
 
+//MarshalJSON encodes the request with Amount in the decimals of CurrencyID, failing when that would lose precision
+func (r TransactionTeleTransferCashTransferRequest) MarshalJSON() ([]byte, error) {
+	type plain TransactionTeleTransferCashTransferRequest
+	amount, err := r.Amount.ForCurrency(r.CurrencyID)
+	if err != nil {
+		return nil, err
+	}
+	r.Amount = amount
+	return json.Marshal(plain(r))
+}
+
 //TeleTransferCashTransferRequest is to provide service for transaction “Cash Transfer” to Non account holder.
 type TeleTransferCashTransferRequest struct {
 	Authentication     Auth
@@ -263,7 +301,7 @@ type BeneficiaryTeleTransferCashTransferResponse struct {
 type TransactionTeleTransferCashTransferResponse struct {
 	PIN             string
 	CurrencyID      string
-	Amount          float64 `json:",string"`
+	Amount          Amount
 	Description1    string
 	Description2    string
 	FormNumber      string
@@ -292,7 +330,7 @@ type SenderTeleTransferAmendCashTransfer struct {
 	PostalCode           string
 	CountryID            string
 	Mobile               string
-	IdentificationType   string
+	IdentificationType   IdentificationType
 	IdentificationNumber string
 }
 
@@ -307,7 +345,7 @@ type BeneficiaryTeleTransferAmendCashTransfer struct {
 	PostalCode           string
 	CountryID            string
 	Mobile               string
-	IdentificationType   string
+	IdentificationType   IdentificationType
 	IdentificationNumber string
 	NationalityID        string
 	Occupation           string
@@ -361,10 +399,21 @@ type TeleTransferAmendCashTransferResponse struct {
 //TransactionTTCancelCashTransferRequest represents transaction details to cancel cash-transfer
 type TransactionTeleTransferCancelCashTransferRequest struct {
 	FormNumber string
-	Amount     float64 `json:",string"`
+	Amount     Amount
 	CurrencyID string
 }
 
+//MarshalJSON encodes the request with Amount in the decimals of CurrencyID, failing when that would lose precision
+func (r TransactionTeleTransferCancelCashTransferRequest) MarshalJSON() ([]byte, error) {
+	type plain TransactionTeleTransferCancelCashTransferRequest
+	amount, err := r.Amount.ForCurrency(r.CurrencyID)
+	if err != nil {
+		return nil, err
+	}
+	r.Amount = amount
+	return json.Marshal(plain(r))
+}
+
 //TTCancelCashTransferRequest is to provide service for Cancellation “Cash Transfer” to Non account holder
 type TeleTransferCancelCashTransferRequest struct {
 	Authentication     Auth
@@ -3,45 +3,146 @@ package fire
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	bca "github.com/ianeinser/bca-api-go"
 )
 
 //Client is used to invoke BCA FIRe API
 type Client struct {
-	Client      bca.APIImplementation
+	Client      bca.API
 	AccessToken string
+	TokenSource bca.TokenSource
 	CorporateID string
 	AccessCode  string
 	BranchCode  string
 	UserID      string
 	LocalID     string
+	// Identities are the other FIRe identities of the client, such as one per branch, picked per call with Identity
+	Identities map[string]bca.Auth
+	// NotFoundStatuses are the StatusTransaction answered by InquiryTransaction for a transaction FIRe has no record of,
+	// bca.StatusTransactionNotFound when empty
+	NotFoundStatuses []string
 }
 
+//ErrUnknownIdentity is returned by Identity for a name missing from Identities
+var ErrUnknownIdentity = errors.New("fire: unknown identity")
+
 //NewClient is used to initialize new fire.Client
 func NewClient(config bca.Config) Client {
+	api := bca.NewAPI(config)
 	return Client{
 		CorporateID: config.FIReCorporateID,
 		AccessCode:  config.AccessCode,
 		BranchCode:  config.BranchCode,
 		UserID:      config.UserID,
 		LocalID:     config.LocalID,
-		Client:      bca.NewAPI(config),
+		Client:      &api,
+	}
+}
+
+//Auth returns the FIRe authentication of the client, set in the Authentication of requests that leave it empty
+func (c *Client) Auth() bca.Auth {
+	return bca.Auth{
+		CorporateID: c.CorporateID,
+		AccessCode:  c.AccessCode,
+		BranchCode:  c.BranchCode,
+		UserID:      c.UserID,
+		LocalID:     c.LocalID,
+	}
+}
+
+//Identity returns a copy of the client authenticating requests as the identity of Identities with the given name.
+//The copy shares the API and token of the client
+func (c *Client) Identity(name string) (*Client, error) {
+	auth, ok := c.Identities[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownIdentity, name)
+	}
+
+	identity := *c
+	identity.CorporateID = auth.CorporateID
+	identity.AccessCode = auth.AccessCode
+	identity.BranchCode = auth.BranchCode
+	identity.UserID = auth.UserID
+	identity.LocalID = auth.LocalID
+	return &identity, nil
+}
+
+//authenticate sets the Auth of the client in ptr_auth when the caller left it empty
+func (c *Client) authenticate(ptr_auth *bca.Auth) {
+	if *ptr_auth == (bca.Auth{}) {
+		*ptr_auth = c.Auth()
+	}
+}
+
+//accessToken returns the token from TokenSource when it is set, otherwise the static AccessToken
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.TokenSource == nil {
+		return c.AccessToken, nil
+	}
+	return c.TokenSource.Token(ctx)
+}
+
+//checkToken drops the token cached by TokenSource when BCA rejected it, so that the next call fetches a new one
+func (c *Client) checkToken(err error) error {
+	if invalidator, ok := c.TokenSource.(bca.TokenInvalidator); ok && bca.TokenRejected(err) {
+		invalidator.Invalidate()
 	}
+	return err
+}
+
+//TransactionNotFound reports whether an answer of InquiryTransaction tells that FIRe has no record of the transaction
+func (c *Client) TransactionNotFound(ptr_inquiry *bca.InquiryTransactionResponse) bool {
+	if len(c.NotFoundStatuses) == 0 {
+		return (*ptr_inquiry).StatusTransaction == bca.StatusTransactionNotFound
+	}
+	for _, status := range c.NotFoundStatuses {
+		if (*ptr_inquiry).StatusTransaction == status {
+			return true
+		}
+	}
+	return false
 }
 
 //Account provides service transaction “Transaction to BCA’s Account” and also “Transfer to Other Bank”
 func (c *Client) TeleTransferToAccount(ctx context.Context, ptr_ttAccountRequest *bca.TeleTransferAccountRequest) (*bca.TeleTransferAccountResponse, error) {
 	var ttAccountResponse bca.TeleTransferAccountResponse
+	request := *ptr_ttAccountRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &ttAccountResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_ttAccountRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &ttAccountResponse, err
 	}
 
 	path := "/fire/transactions/to-account"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &ttAccountResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &ttAccountResponse, err
+	}
+
+	resolve := c.transactionResolver(request.Authentication, request.TransactionDetails.FormNumber, func(ptr_inquiry *bca.InquiryTransactionResponse, v interface{}) {
+		ptr_response := v.(*bca.TeleTransferAccountResponse)
+		(*ptr_response).BeneficiaryDetails.Name = (*ptr_inquiry).BeneficiaryDetails.Name
+		(*ptr_response).BeneficiaryDetails.AccountNumber = (*ptr_inquiry).BeneficiaryDetails.AccountNumber
+		(*ptr_response).TransactionDetails.CurrencyID = (*ptr_inquiry).TransactionDetails.CurrencyID
+		(*ptr_response).TransactionDetails.Amount = (*ptr_inquiry).TransactionDetails.AmountPaid
+		(*ptr_response).TransactionDetails.Description1 = (*ptr_inquiry).TransactionDetails.Description1
+		(*ptr_response).TransactionDetails.Description2 = (*ptr_inquiry).TransactionDetails.Description2
+		(*ptr_response).TransactionDetails.FormNumber = (*ptr_inquiry).TransactionDetails.FormNumber
+		(*ptr_response).TransactionDetails.ReferenceNumber = (*ptr_inquiry).TransactionDetails.ReferenceNumber
+		(*ptr_response).TransactionDetails.ReleaseDateTime = (*ptr_inquiry).TransactionDetails.ReleaseDateTime
+		(*ptr_response).StatusTransaction = (*ptr_inquiry).StatusTransaction
+		(*ptr_response).StatusMessage = (*ptr_inquiry).StatusMessage
+	})
+
+	if err := c.checkToken(c.Client.CallNonIdempotentContext(ctx, "POST", path, accessToken, nil, jsonReq, &ttAccountResponse, resolve)); err != nil {
 		return &ttAccountResponse, err
 	}
 
@@ -51,15 +152,25 @@ func (c *Client) TeleTransferToAccount(ctx context.Context, ptr_ttAccountRequest
 //InquiryAccount provides service to Inquiry BCA’s Account name or Other Bank Switching’s Account name.
 func (c *Client) InquiryAccount(ctx context.Context, ptr_ttInquiryAccountRequest *bca.InquiryAccountRequest) (*bca.InquiryAccountResponse, error) {
 	var ttInquiryAccountResponse bca.InquiryAccountResponse
+	request := *ptr_ttInquiryAccountRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &ttInquiryAccountResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_ttInquiryAccountRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &ttInquiryAccountResponse, err
 	}
 
 	path := "/fire/accounts"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &ttInquiryAccountResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &ttInquiryAccountResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "POST", path, accessToken, nil, jsonReq, &ttInquiryAccountResponse)); err != nil {
 		return &ttInquiryAccountResponse, err
 	}
 
@@ -69,15 +180,25 @@ func (c *Client) InquiryAccount(ctx context.Context, ptr_ttInquiryAccountRequest
 //InquiryAccountBalance provides service to Inquiry balance for Vostro’s Account
 func (c *Client) InquiryAccountBalance(ctx context.Context, ptr_inquiryAccountBalanceRequest *bca.InquiryAccountBalanceRequest) (*bca.InquiryAccountBalanceResponse, error) {
 	var inquiryAccountBalanceResponse bca.InquiryAccountBalanceResponse
+	request := *ptr_inquiryAccountBalanceRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &inquiryAccountBalanceResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_inquiryAccountBalanceRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &inquiryAccountBalanceResponse, err
 	}
 
 	path := "/fire/accounts/balance"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &inquiryAccountBalanceResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &inquiryAccountBalanceResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "POST", path, accessToken, nil, jsonReq, &inquiryAccountBalanceResponse)); err != nil {
 		return &inquiryAccountBalanceResponse, err
 	}
 
@@ -87,15 +208,25 @@ func (c *Client) InquiryAccountBalance(ctx context.Context, ptr_inquiryAccountBa
 //TTInquiryTransaction provides service to Inquiry Transaction that has been submitted before
 func (c *Client) InquiryTransaction(ctx context.Context, ptr_inquiryTransactionRequest *bca.InquiryTransactionRequest) (*bca.InquiryTransactionResponse, error) {
 	var inquiryTransactionResponse bca.InquiryTransactionResponse
+	request := *ptr_inquiryTransactionRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &inquiryTransactionResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_inquiryTransactionRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &inquiryTransactionResponse, err
 	}
 
 	path := "/fire/transactions"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &inquiryTransactionResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &inquiryTransactionResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "POST", path, accessToken, nil, jsonReq, &inquiryTransactionResponse)); err != nil {
 		return &inquiryTransactionResponse, err
 	}
 
@@ -105,15 +236,40 @@ func (c *Client) InquiryTransaction(ctx context.Context, ptr_inquiryTransactionR
 //TTCashTransfer provides service for transaction “Cash Transfer” to Non account holder
 func (c *Client) TeleTransferCashTransfer(ctx context.Context, ptr_ttCashTransferRequest *bca.TeleTransferCashTransferRequest) (*bca.TeleTransferCashTransferResponse, error) {
 	var ttCashTransferResponse bca.TeleTransferCashTransferResponse
+	request := *ptr_ttCashTransferRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &ttCashTransferResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_ttCashTransferRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &ttCashTransferResponse, err
 	}
 
 	path := "/fire/transactions/cash-transfer"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &ttCashTransferResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &ttCashTransferResponse, err
+	}
+
+	resolve := c.transactionResolver(request.Authentication, request.TransactionDetails.FormNumber, func(ptr_inquiry *bca.InquiryTransactionResponse, v interface{}) {
+		ptr_response := v.(*bca.TeleTransferCashTransferResponse)
+		(*ptr_response).BeneficiaryDetails.Name = (*ptr_inquiry).BeneficiaryDetails.Name
+		(*ptr_response).TransactionDetails.PIN = (*ptr_inquiry).TransactionDetails.PIN
+		(*ptr_response).TransactionDetails.CurrencyID = (*ptr_inquiry).TransactionDetails.CurrencyID
+		(*ptr_response).TransactionDetails.Amount = (*ptr_inquiry).TransactionDetails.AmountPaid
+		(*ptr_response).TransactionDetails.Description1 = (*ptr_inquiry).TransactionDetails.Description1
+		(*ptr_response).TransactionDetails.Description2 = (*ptr_inquiry).TransactionDetails.Description2
+		(*ptr_response).TransactionDetails.FormNumber = (*ptr_inquiry).TransactionDetails.FormNumber
+		(*ptr_response).TransactionDetails.ReferenceNumber = (*ptr_inquiry).TransactionDetails.ReferenceNumber
+		(*ptr_response).TransactionDetails.ReleaseDateTime = (*ptr_inquiry).TransactionDetails.ReleaseDateTime
+		(*ptr_response).StatusTransaction = (*ptr_inquiry).StatusTransaction
+		(*ptr_response).StatusMessage = (*ptr_inquiry).StatusMessage
+	})
+
+	if err := c.checkToken(c.Client.CallNonIdempotentContext(ctx, "POST", path, accessToken, nil, jsonReq, &ttCashTransferResponse, resolve)); err != nil {
 		return &ttCashTransferResponse, err
 	}
 
@@ -123,15 +279,26 @@ func (c *Client) TeleTransferCashTransfer(ctx context.Context, ptr_ttCashTransfe
 //TTAmendCashTransfer provides service for Amendment “Cash Transfer” to Non account holder
 func (c *Client) TeleTransferAmendCashTransfer(ctx context.Context, ptr_ttAmendCashTransferRequest *bca.TeleTransferAmendCashTransferRequest) (*bca.TeleTransferAmendCashTransferResponse, error) {
 	var ttAmendCashTransferResponse bca.TeleTransferAmendCashTransferResponse
+	request := *ptr_ttAmendCashTransferRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &ttAmendCashTransferResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_ttAmendCashTransferRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &ttAmendCashTransferResponse, err
 	}
 
 	path := "/fire/transactions/cash-transfer/amend"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &ttAmendCashTransferResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &ttAmendCashTransferResponse, err
+	}
+
+	// BCA offers no way to check whether an amendment or cancellation was applied, so it is never retried
+	if err := c.checkToken(c.Client.CallNonIdempotentContext(ctx, "POST", path, accessToken, nil, jsonReq, &ttAmendCashTransferResponse, nil)); err != nil {
 		return &ttAmendCashTransferResponse, err
 	}
 
@@ -141,17 +308,60 @@ func (c *Client) TeleTransferAmendCashTransfer(ctx context.Context, ptr_ttAmendC
 //TTCancelCashTransfer provides service for Cancellation “Cash Transfer” to Non account holder
 func (c *Client) TeleTransferCancelCashTransfer(ctx context.Context, ptr_ttCancelCashTransferRequest *bca.TeleTransferCancelCashTransferRequest) (*bca.TeleTransferCancelCashTransferResponse, error) {
 	var ttCancelCashTransferResponse bca.TeleTransferCancelCashTransferResponse
+	request := *ptr_ttCancelCashTransferRequest
+	c.authenticate(&request.Authentication)
+	if err := request.Validate(); err != nil {
+		return &ttCancelCashTransferResponse, err
+	}
 
-	jsonReq, err := json.Marshal(*ptr_ttCancelCashTransferRequest)
+	jsonReq, err := json.Marshal(request)
 	if err != nil {
 		return &ttCancelCashTransferResponse, err
 	}
 
 	path := "/fire/transactions/cash-transfer/cancel"
 
-	if err := c.Client.Call("POST", path, c.AccessToken, nil, jsonReq, &ttCancelCashTransferResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &ttCancelCashTransferResponse, err
+	}
+
+	// BCA offers no way to check whether an amendment or cancellation was applied, so it is never retried
+	if err := c.checkToken(c.Client.CallNonIdempotentContext(ctx, "POST", path, accessToken, nil, jsonReq, &ttCancelCashTransferResponse, nil)); err != nil {
 		return &ttCancelCashTransferResponse, err
 	}
 
 	return &ttCancelCashTransferResponse, nil
 }
+
+//transactionResolver checks with InquiryTransaction, under the auth the transfer was sent with, whether a failed transfer attempt
+//with the given FormNumber was applied by BCA. It is sent again only when FIRe answers that it has no record of the transaction
+func (c *Client) transactionResolver(auth bca.Auth, formNumber string, fill func(*bca.InquiryTransactionResponse, interface{})) bca.OutcomeResolver {
+	return func(ctx context.Context, v interface{}) (bool, error) {
+		if formNumber == "" {
+			return false, errors.New("fire: cannot inquire transaction without FormNumber")
+		}
+
+		ptr_inquiry, err := c.InquiryTransaction(ctx, &bca.InquiryTransactionRequest{
+			Authentication: auth,
+			TransactionDetails: bca.TransactionInquiryTransactionRequest{
+				InquiryBy:    bca.InquiryByFormNumber,
+				InquiryValue: formNumber,
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case (*ptr_inquiry).StatusTransaction == bca.StatusTransactionSuccess:
+		case c.TransactionNotFound(ptr_inquiry):
+			return false, nil
+		default:
+			return false, fmt.Errorf("fire: inquiry of FormNumber %s answered %s %s", formNumber, (*ptr_inquiry).StatusTransaction, (*ptr_inquiry).StatusMessage)
+		}
+
+		fill(ptr_inquiry, v)
+		return true, nil
+	}
+}
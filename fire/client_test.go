@@ -0,0 +1,108 @@
+package fire
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+)
+
+func testClient(api bca.API) Client {
+	return Client{
+		Client:      api,
+		CorporateID: "IBSTT01",
+		AccessCode:  "q1w2e3r4",
+		BranchCode:  "IBSTT0101",
+		UserID:      "IBSTT0101",
+		LocalID:     "40115",
+	}
+}
+
+func TestTransactionResolver(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    bcatest.Response
+		wantApplied bool
+		wantErr     bool
+	}{
+		{
+			name:     "not found is sent again",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionNotFound, StatusMessage: "Transaction not found"}},
+		},
+		{
+			name:        "success is applied",
+			response:    bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionSuccess}},
+			wantApplied: true,
+		},
+		{
+			name:     "other status is not resolved",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: "0003", StatusMessage: "Invalid authentication"}},
+			wantErr:  true,
+		},
+		{
+			name:     "unauthorized is not resolved",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusUnauthorized}},
+			wantErr:  true,
+		},
+		{
+			name:     "too many requests is not resolved",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusTooManyRequests}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.Respond("POST", "/fire/transactions", tt.response)
+			c := testClient(recorder)
+
+			// The transfer was sent as another identity than the one of the client
+			auth := c.Auth()
+			auth.BranchCode = "IBSTT0102"
+
+			filled := false
+			resolve := c.transactionResolver(auth, "2610180000000001", func(*bca.InquiryTransactionResponse, interface{}) {
+				filled = true
+			})
+
+			applied, err := resolve(context.Background(), nil)
+			if applied != tt.wantApplied || filled != tt.wantApplied {
+				t.Errorf("applied = %v, filled = %v, want %v", applied, filled, tt.wantApplied)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			call, _ := recorder.LastCall()
+			var inquiry bca.InquiryTransactionRequest
+			if err := json.Unmarshal(call.Body, &inquiry); err != nil || inquiry.Authentication != auth {
+				t.Errorf("inquired as %+v, %v, want %+v", inquiry.Authentication, err, auth)
+			}
+		})
+	}
+}
+
+func TestTransactionNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		status   string
+		want     bool
+	}{
+		{name: "default", status: bca.StatusTransactionNotFound, want: true},
+		{name: "default success", status: bca.StatusTransactionSuccess},
+		{name: "configured", statuses: []string{"0104"}, status: "0104", want: true},
+		{name: "configured replaces default", statuses: []string{"0104"}, status: bca.StatusTransactionNotFound},
+	}
+
+	for _, tt := range tests {
+		c := Client{NotFoundStatuses: tt.statuses}
+		if got := c.TransactionNotFound(&bca.InquiryTransactionResponse{StatusTransaction: tt.status}); got != tt.want {
+			t.Errorf("%s: TransactionNotFound(%s) = %v, want %v", tt.name, tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package fire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//CashTransferState is the state of a cash transfer followed by a CashTransferTracker
+type CashTransferState string
+
+//States of a cash transfer. Cancelled, Paid and Failed are final
+const (
+	// CashTransferSubmitted is a cash transfer sent to BCA whose outcome is not known yet
+	CashTransferSubmitted CashTransferState = "SUBMITTED"
+	CashTransferReleased  CashTransferState = "RELEASED"
+	CashTransferAmended   CashTransferState = "AMENDED"
+	CashTransferCancelled CashTransferState = "CANCELLED"
+	CashTransferPaid      CashTransferState = "PAID"
+	CashTransferFailed    CashTransferState = "FAILED"
+)
+
+//Final reports whether the cash transfer can no longer change
+func (s CashTransferState) Final() bool {
+	return s == CashTransferCancelled || s == CashTransferPaid || s == CashTransferFailed
+}
+
+//stage orders the states, a cash transfer only moves to a later stage. Released and Amended share theirs
+//since BCA keeps reporting an amended cash transfer as released
+func (s CashTransferState) stage() int {
+	switch s {
+	case CashTransferSubmitted:
+		return 0
+	case CashTransferReleased, CashTransferAmended:
+		return 1
+	}
+	return 2
+}
+
+//cashTransferStatuses maps the StatusMessage of InquiryTransaction, in upper case, to the state of a cash transfer
+var cashTransferStatuses = map[string]CashTransferState{
+	"RELEASED":  CashTransferReleased,
+	"PAID":      CashTransferPaid,
+	"CANCELLED": CashTransferCancelled,
+}
+
+//DefaultCashTransferStatuses returns the StatusMessage of InquiryTransaction, in upper case, mapped to the state of a cash
+//transfer, such as to extend it in CashTransferTracker.Statuses
+func DefaultCashTransferStatuses() map[string]CashTransferState {
+	statuses := make(map[string]CashTransferState, len(cashTransferStatuses))
+	for statusMessage, state := range cashTransferStatuses {
+		statuses[statusMessage] = state
+	}
+	return statuses
+}
+
+//CashTransferStatus returns the state of a cash transfer for the StatusMessage of InquiryTransaction in DefaultCashTransferStatuses
+func CashTransferStatus(statusMessage string) (CashTransferState, bool) {
+	state, ok := cashTransferStatuses[strings.ToUpper(strings.TrimSpace(statusMessage))]
+	return state, ok
+}
+
+//DefaultNotFoundGrace is how long a Submitted cash transfer stays in doubt when BCA reports it not found, since BCA may
+//still be processing a call that timed out
+const DefaultNotFoundGrace = 15 * time.Minute
+
+//ErrUnknownCashTransfer is returned for a FormNumber the tracker does not follow
+var ErrUnknownCashTransfer = errors.New("fire: unknown cash transfer")
+
+//ErrCashTransferBusy is returned when a cash transfer is amended or cancelled while another call on it is in progress
+var ErrCashTransferBusy = errors.New("fire: cash transfer has a call in progress")
+
+//ErrCashTransferState matches, with errors.Is, every *StateError
+var ErrCashTransferState = errors.New("fire: action not allowed in cash transfer state")
+
+//StateError is returned when a cash transfer is amended or cancelled in a state that does not allow it
+type StateError struct {
+	FormNumber string
+	State      CashTransferState
+	Action     string
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("fire: cannot %s cash transfer %s in state %s", e.Action, e.FormNumber, e.State)
+}
+
+//Is reports whether target is ErrCashTransferState
+func (e *StateError) Is(target error) bool {
+	return target == ErrCashTransferState
+}
+
+//CashTransfer represents a cash transfer followed by a CashTransferTracker
+type CashTransfer struct {
+	FormNumber      string
+	ReferenceNumber string
+	CurrencyID      string
+	Amount          bca.Amount
+	// Authentication is the one the cash transfer was sent with, and is inquired with. Empty for the Auth of the client
+	Authentication bca.Auth
+	State          CashTransferState
+	// StatusMessage is the last one BCA returned for the cash transfer
+	StatusMessage string
+	UpdatedAt     time.Time
+}
+
+//CashTransferStateChange is sent to the handler of a CashTransferTracker when a cash transfer changes state
+type CashTransferStateChange struct {
+	CashTransfer CashTransfer
+	From         CashTransferState
+	To           CashTransferState
+}
+
+//CashTransferTracker follows the lifecycle of cash transfers sent through it, by FormNumber and ReferenceNumber
+type CashTransferTracker struct {
+	Client *Client
+	// Statuses maps the StatusMessage of InquiryTransaction in upper case to a state, DefaultCashTransferStatuses when nil
+	Statuses map[string]CashTransferState
+	// NotFoundGrace is how long after its UpdatedAt a Submitted cash transfer BCA reports not found stays Submitted,
+	// DefaultNotFoundGrace when zero
+	NotFoundGrace time.Duration
+	// OnChange is optional, it is called for every state change outside of the lock of the tracker
+	OnChange func(CashTransferStateChange)
+	// OnError is optional, it is called by Watch with the errors of Poll
+	OnError func(error)
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+
+	mu          sync.Mutex
+	transfers   map[string]*CashTransfer
+	byReference map[string]string
+	// busy holds the FormNumbers with a call to BCA in progress, which Poll leaves alone
+	busy map[string]bool
+}
+
+//NewCashTransferTracker is used to initialize new CashTransferTracker
+func NewCashTransferTracker(client *Client, onChange func(CashTransferStateChange)) *CashTransferTracker {
+	return &CashTransferTracker{
+		Client:      client,
+		OnChange:    onChange,
+		transfers:   map[string]*CashTransfer{},
+		byReference: map[string]string{},
+		busy:        map[string]bool{},
+	}
+}
+
+//Track follows a cash transfer sent before, such as one loaded back after a restart
+func (t *CashTransferTracker) Track(transfer CashTransfer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.put(&transfer)
+}
+
+//Get returns the cash transfer of a FormNumber
+func (t *CashTransferTracker) Get(formNumber string) (CashTransfer, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ptr_transfer, ok := t.transfers[formNumber]
+	if !ok {
+		return CashTransfer{}, false
+	}
+	return *ptr_transfer, true
+}
+
+//GetByReference returns the cash transfer of a ReferenceNumber
+func (t *CashTransferTracker) GetByReference(referenceNumber string) (CashTransfer, bool) {
+	t.mu.Lock()
+	formNumber, ok := t.byReference[referenceNumber]
+	t.mu.Unlock()
+	if !ok {
+		return CashTransfer{}, false
+	}
+	return t.Get(formNumber)
+}
+
+//CashTransfers returns every cash transfer of the tracker
+func (t *CashTransferTracker) CashTransfers() []CashTransfer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transfers := make([]CashTransfer, 0, len(t.transfers))
+	for _, ptr_transfer := range t.transfers {
+		transfers = append(transfers, *ptr_transfer)
+	}
+	return transfers
+}
+
+//TeleTransferCashTransfer sends a cash transfer with the client and follows it. It is Submitted until BCA answers,
+//Released when BCA accepted it and Failed when BCA or Validate explicitly rejected it. Any other error leaves it
+//Submitted until Poll finds it. Only a Failed FormNumber may be sent again
+func (t *CashTransferTracker) TeleTransferCashTransfer(ctx context.Context, ptr_ttCashTransferRequest *bca.TeleTransferCashTransferRequest) (*bca.TeleTransferCashTransferResponse, error) {
+	details := (*ptr_ttCashTransferRequest).TransactionDetails
+	if details.FormNumber == "" {
+		return &bca.TeleTransferCashTransferResponse{}, errors.New("fire: cannot track cash transfer without FormNumber")
+	}
+
+	t.mu.Lock()
+	if ptr_transfer, ok := t.transfers[details.FormNumber]; ok && ((*ptr_transfer).State != CashTransferFailed || t.busy[details.FormNumber]) {
+		t.mu.Unlock()
+		return &bca.TeleTransferCashTransferResponse{}, fmt.Errorf("fire: cash transfer %s is already tracked", details.FormNumber)
+	}
+	t.put(&CashTransfer{
+		FormNumber:     details.FormNumber,
+		CurrencyID:     details.CurrencyID,
+		Amount:         details.Amount,
+		Authentication: (*ptr_ttCashTransferRequest).Authentication,
+		State:          CashTransferSubmitted,
+		UpdatedAt:      t.now(),
+	})
+	t.busy[details.FormNumber] = true
+	t.mu.Unlock()
+	defer t.release(details.FormNumber)
+
+	ptr_response, err := t.Client.TeleTransferCashTransfer(ctx, ptr_ttCashTransferRequest)
+	switch {
+	case err == nil && (*ptr_response).StatusTransaction == bca.StatusTransactionSuccess:
+		t.transition(details.FormNumber, CashTransferReleased, (*ptr_response).TransactionDetails.ReferenceNumber, (*ptr_response).StatusMessage)
+	case err == nil:
+		t.transition(details.FormNumber, CashTransferFailed, "", (*ptr_response).StatusMessage)
+	case bca.Rejected(err):
+		t.transition(details.FormNumber, CashTransferFailed, "", err.Error())
+	}
+	// A 5xx, 401 or 429 response or any other error leaves the outcome unknown, the cash transfer stays Submitted
+	return ptr_response, err
+}
+
+//TeleTransferAmendCashTransfer amends a followed cash transfer, which must be Released or Amended
+func (t *CashTransferTracker) TeleTransferAmendCashTransfer(ctx context.Context, ptr_ttAmendCashTransferRequest *bca.TeleTransferAmendCashTransferRequest) (*bca.TeleTransferAmendCashTransferResponse, error) {
+	formNumber := (*ptr_ttAmendCashTransferRequest).TransactionDetails.FormNumber
+	if err := t.reserve(formNumber, "amend"); err != nil {
+		return &bca.TeleTransferAmendCashTransferResponse{}, err
+	}
+	defer t.release(formNumber)
+
+	ptr_response, err := t.Client.TeleTransferAmendCashTransfer(ctx, ptr_ttAmendCashTransferRequest)
+	if err == nil && (*ptr_response).StatusTransaction == bca.StatusTransactionSuccess {
+		t.transition(formNumber, CashTransferAmended, "", (*ptr_response).StatusMessage)
+	}
+	return ptr_response, err
+}
+
+//TeleTransferCancelCashTransfer cancels a followed cash transfer, which must be Released or Amended
+func (t *CashTransferTracker) TeleTransferCancelCashTransfer(ctx context.Context, ptr_ttCancelCashTransferRequest *bca.TeleTransferCancelCashTransferRequest) (*bca.TeleTransferCancelCashTransferResponse, error) {
+	formNumber := (*ptr_ttCancelCashTransferRequest).TransactionDetails.FormNumber
+	if err := t.reserve(formNumber, "cancel"); err != nil {
+		return &bca.TeleTransferCancelCashTransferResponse{}, err
+	}
+	defer t.release(formNumber)
+
+	ptr_response, err := t.Client.TeleTransferCancelCashTransfer(ctx, ptr_ttCancelCashTransferRequest)
+	if err == nil && (*ptr_response).StatusTransaction == bca.StatusTransactionSuccess {
+		t.transition(formNumber, CashTransferCancelled, "", (*ptr_response).StatusMessage)
+	}
+	return ptr_response, err
+}
+
+//Poll inquires every cash transfer that is not final nor has a call in progress, with its Authentication, and moves it
+//forward to the state of its StatusMessage. A Submitted cash transfer BCA reports not found is Failed once NotFoundGrace
+//has passed since its UpdatedAt. The first inquiry error, or answer that is not understood, is returned after every
+//transfer was tried
+func (t *CashTransferTracker) Poll(ctx context.Context) error {
+	t.mu.Lock()
+	var pending []string
+	for formNumber, ptr_transfer := range t.transfers {
+		if !(*ptr_transfer).State.Final() && !t.busy[formNumber] {
+			pending = append(pending, formNumber)
+		}
+	}
+	t.mu.Unlock()
+
+	var firstErr error
+	for _, formNumber := range pending {
+		if err := t.poll(ctx, formNumber); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return firstErr
+}
+
+//Watch calls Poll every interval until ctx is done. The errors of Poll are passed to OnError, the inquiries being tried
+//again in the next round
+func (t *CashTransferTracker) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Poll(ctx); err != nil && ctx.Err() == nil && t.OnError != nil {
+			t.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *CashTransferTracker) poll(ctx context.Context, formNumber string) error {
+	transfer, ok := t.Get(formNumber)
+	if !ok {
+		return nil
+	}
+	ptr_inquiry, err := t.Client.InquiryTransaction(ctx, &bca.InquiryTransactionRequest{
+		Authentication: transfer.Authentication,
+		TransactionDetails: bca.TransactionInquiryTransactionRequest{
+			InquiryBy:    bca.InquiryByFormNumber,
+			InquiryValue: formNumber,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	inquiry := *ptr_inquiry
+	switch {
+	case inquiry.StatusTransaction == bca.StatusTransactionSuccess:
+	case t.Client.TransactionNotFound(ptr_inquiry):
+		grace := t.NotFoundGrace
+		if grace == 0 {
+			grace = DefaultNotFoundGrace
+		}
+		// BCA may not know yet of a cash transfer whose call timed out, it stays in doubt until the grace period ends
+		if transfer.State == CashTransferSubmitted && !t.now().Before(transfer.UpdatedAt.Add(grace)) {
+			t.transition(formNumber, CashTransferFailed, "", inquiry.StatusMessage)
+		}
+		return nil
+	default:
+		return fmt.Errorf("fire: inquiry of cash transfer %s answered %s %s", formNumber, inquiry.StatusTransaction, inquiry.StatusMessage)
+	}
+
+	statuses := t.Statuses
+	if statuses == nil {
+		statuses = cashTransferStatuses
+	}
+	state, ok := statuses[strings.ToUpper(strings.TrimSpace(inquiry.StatusMessage))]
+	if !ok {
+		return nil
+	}
+	t.transition(formNumber, state, inquiry.TransactionDetails.ReferenceNumber, inquiry.StatusMessage)
+	return nil
+}
+
+//reserve checks that a cash transfer can be amended or cancelled and marks it busy until release, so that the
+//check still holds when the call reaches BCA
+func (t *CashTransferTracker) reserve(formNumber, action string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ptr_transfer, ok := t.transfers[formNumber]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCashTransfer, formNumber)
+	}
+	if state := (*ptr_transfer).State; state != CashTransferReleased && state != CashTransferAmended {
+		return &StateError{FormNumber: formNumber, State: state, Action: action}
+	}
+	if t.busy[formNumber] {
+		return fmt.Errorf("%w: %s", ErrCashTransferBusy, formNumber)
+	}
+	t.busy[formNumber] = true
+	return nil
+}
+
+//release ends the call in progress on a cash transfer
+func (t *CashTransferTracker) release(formNumber string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.busy, formNumber)
+}
+
+//transition moves a cash transfer to state unless it is final or state is of an earlier stage, except Amended
+//which follows Released, then calls OnChange
+func (t *CashTransferTracker) transition(formNumber string, state CashTransferState, referenceNumber, statusMessage string) {
+	t.mu.Lock()
+	ptr_transfer, ok := t.transfers[formNumber]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+
+	from := (*ptr_transfer).State
+	forward := state.stage() > from.stage() || (from == CashTransferReleased && state == CashTransferAmended)
+	if referenceNumber != "" && (*ptr_transfer).ReferenceNumber == "" {
+		(*ptr_transfer).ReferenceNumber = referenceNumber
+		t.byReference[referenceNumber] = formNumber
+	}
+	if !forward || from.Final() {
+		t.mu.Unlock()
+		return
+	}
+
+	(*ptr_transfer).State = state
+	(*ptr_transfer).StatusMessage = statusMessage
+	(*ptr_transfer).UpdatedAt = t.now()
+	change := CashTransferStateChange{CashTransfer: *ptr_transfer, From: from, To: state}
+	t.mu.Unlock()
+
+	if t.OnChange != nil {
+		t.OnChange(change)
+	}
+}
+
+func (t *CashTransferTracker) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+//put adds a cash transfer to the maps of the tracker. t.mu must be held
+func (t *CashTransferTracker) put(ptr_transfer *CashTransfer) {
+	if t.transfers == nil {
+		t.transfers = map[string]*CashTransfer{}
+		t.byReference = map[string]string{}
+		t.busy = map[string]bool{}
+	}
+	t.transfers[(*ptr_transfer).FormNumber] = ptr_transfer
+	if (*ptr_transfer).ReferenceNumber != "" {
+		t.byReference[(*ptr_transfer).ReferenceNumber] = (*ptr_transfer).FormNumber
+	}
+}
@@ -0,0 +1,266 @@
+package fire
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+)
+
+func cashTransferRequest(formNumber string) *bca.TeleTransferCashTransferRequest {
+	return &bca.TeleTransferCashTransferRequest{
+		SenderDetails: bca.SenderTeleTransferCashTransferRequest{
+			FirstName:            "Budi",
+			Address1:             "Jl. Sudirman 1",
+			City:                 "Jakarta",
+			CountryID:            "ID",
+			IdentificationType:   "KTP",
+			IdentificationNumber: "3171000000000001",
+		},
+		BeneficiaryDetails: bca.BeneficiaryTeleTransferCashTransferRequest{
+			Name:                 "Siti",
+			Address1:             "Jl. Thamrin 2",
+			City:                 "Jakarta",
+			CountryID:            "ID",
+			IdentificationType:   "KTP",
+			IdentificationNumber: "3171000000000002",
+		},
+		TransactionDetails: bca.TransactionTeleTransferCashTransferRequest{
+			PIN:             "123456",
+			CurrencyID:      "IDR",
+			Amount:          bca.MustParseAmount("250000"),
+			PurposeCode:     "011",
+			DetailOfCharges: bca.DetailOfChargesSha,
+			FormNumber:      formNumber,
+		},
+	}
+}
+
+func TestCashTransferTrackerSubmit(t *testing.T) {
+	tests := []struct {
+		name     string
+		response bcatest.Response
+		want     CashTransferState
+	}{
+		{
+			name:     "accepted",
+			response: bcatest.Response{Body: bca.TeleTransferCashTransferResponse{StatusTransaction: bca.StatusTransactionSuccess, TransactionDetails: bca.TransactionTeleTransferCashTransferResponse{ReferenceNumber: "REF1"}}},
+			want:     CashTransferReleased,
+		},
+		{
+			name:     "refused in the response",
+			response: bcatest.Response{Body: bca.TeleTransferCashTransferResponse{StatusTransaction: "0005", StatusMessage: "Not allowed"}},
+			want:     CashTransferFailed,
+		},
+		{
+			name:     "rejected by BCA",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusBadRequest, ErrorCode: bca.ErrInsufficientFunds.ErrorCode}},
+			want:     CashTransferFailed,
+		},
+		{
+			name:     "unauthorized",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusUnauthorized, ErrorCode: bca.ErrExpiredToken.ErrorCode}},
+			want:     CashTransferSubmitted,
+		},
+		{
+			name:     "too many requests",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusTooManyRequests}},
+			want:     CashTransferSubmitted,
+		},
+		{
+			name:     "invalid signature",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusBadRequest, ErrorCode: bca.ErrInvalidSignature.ErrorCode}},
+			want:     CashTransferSubmitted,
+		},
+		{
+			name:     "server error",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusBadGateway}},
+			want:     CashTransferSubmitted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.Respond("POST", "/fire/transactions/cash-transfer", tt.response)
+			client := testClient(recorder)
+			tracker := NewCashTransferTracker(&client, nil)
+
+			tracker.TeleTransferCashTransfer(context.Background(), cashTransferRequest("FORM1"))
+
+			transfer, _ := tracker.Get("FORM1")
+			if transfer.State != tt.want {
+				t.Errorf("State = %s, want %s", transfer.State, tt.want)
+			}
+
+			// Only a Failed cash transfer may be sent again
+			tracker.TeleTransferCashTransfer(context.Background(), cashTransferRequest("FORM1"))
+			wantCalls := 1
+			if tt.want == CashTransferFailed {
+				wantCalls = 2
+			}
+			if calls := len(recorder.Calls()); calls != wantCalls {
+				t.Errorf("sent %d times, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestCashTransferTrackerPoll(t *testing.T) {
+	tests := []struct {
+		name string
+		from CashTransferState
+		// age is the time since the UpdatedAt of the cash transfer
+		age     time.Duration
+		inquiry bcatest.Response
+		want    CashTransferState
+		wantErr bool
+	}{
+		{
+			name:    "submitted not found",
+			from:    CashTransferSubmitted,
+			age:     DefaultNotFoundGrace,
+			inquiry: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionNotFound}},
+			want:    CashTransferFailed,
+		},
+		{
+			name:    "submitted not found yet",
+			from:    CashTransferSubmitted,
+			age:     DefaultNotFoundGrace - time.Second,
+			inquiry: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionNotFound}},
+			want:    CashTransferSubmitted,
+		},
+		{
+			name:    "submitted not understood",
+			from:    CashTransferSubmitted,
+			inquiry: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: "0003", StatusMessage: "Invalid authentication"}},
+			want:    CashTransferSubmitted,
+			wantErr: true,
+		},
+		{
+			name:    "submitted unauthorized",
+			from:    CashTransferSubmitted,
+			inquiry: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusUnauthorized}},
+			want:    CashTransferSubmitted,
+			wantErr: true,
+		},
+		{
+			name:    "submitted released",
+			from:    CashTransferSubmitted,
+			inquiry: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionSuccess, StatusMessage: "Released"}},
+			want:    CashTransferReleased,
+		},
+		{
+			name:    "released paid",
+			from:    CashTransferReleased,
+			inquiry: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionSuccess, StatusMessage: "Paid"}},
+			want:    CashTransferPaid,
+		},
+		{
+			name:    "released not found",
+			from:    CashTransferReleased,
+			inquiry: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionNotFound}},
+			want:    CashTransferReleased,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			recorder.Respond("POST", "/fire/transactions", tt.inquiry)
+			client := testClient(recorder)
+			tracker := NewCashTransferTracker(&client, nil)
+			now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+			tracker.Now = func() time.Time { return now }
+			tracker.Track(CashTransfer{FormNumber: "FORM1", State: tt.from, UpdatedAt: now.Add(-tt.age)})
+
+			if err := tracker.Poll(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Poll() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if transfer, _ := tracker.Get("FORM1"); transfer.State != tt.want {
+				t.Errorf("State = %s, want %s", transfer.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestCashTransferTrackerReserve(t *testing.T) {
+	tracker := NewCashTransferTracker(nil, nil)
+	tracker.Track(CashTransfer{FormNumber: "FORM1", State: CashTransferReleased})
+	tracker.Track(CashTransfer{FormNumber: "FORM2", State: CashTransferPaid})
+
+	tests := []struct {
+		name       string
+		formNumber string
+		wantErr    error
+	}{
+		{name: "released", formNumber: "FORM1"},
+		{name: "in progress", formNumber: "FORM1", wantErr: ErrCashTransferBusy},
+		{name: "final", formNumber: "FORM2", wantErr: ErrCashTransferState},
+		{name: "unknown", formNumber: "FORM3", wantErr: ErrUnknownCashTransfer},
+	}
+
+	for _, tt := range tests {
+		if err := tracker.reserve(tt.formNumber, "cancel"); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: reserve() = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	tracker.release("FORM1")
+	if err := tracker.reserve("FORM1", "cancel"); err != nil {
+		t.Errorf("reserve() after release = %v, want nil", err)
+	}
+}
+
+func TestCashTransferTrackerPollAuthentication(t *testing.T) {
+	recorder := bcatest.NewRecorder()
+	recorder.Respond("POST", "/fire/transactions/cash-transfer", bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusGatewayTimeout}})
+	recorder.RespondJSON("POST", "/fire/transactions", bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionSuccess, StatusMessage: "Released"})
+	client := testClient(recorder)
+	tracker := NewCashTransferTracker(&client, nil)
+
+	branch := bca.Auth{CorporateID: "IBSTT02", AccessCode: "a1s2d3f4", BranchCode: "IBSTT0201", UserID: "IBSTT0201", LocalID: "40116"}
+	request := cashTransferRequest("FORM1")
+	request.Authentication = branch
+	tracker.TeleTransferCashTransfer(context.Background(), request)
+	if err := tracker.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() = %v", err)
+	}
+
+	calls := recorder.Calls()
+	var inquiry bca.InquiryTransactionRequest
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &inquiry); err != nil || inquiry.Authentication != branch {
+		t.Errorf("inquired with %+v, %v, want %+v", inquiry.Authentication, err, branch)
+	}
+	if transfer, _ := tracker.Get("FORM1"); transfer.State != CashTransferReleased {
+		t.Errorf("State = %s, want %s", transfer.State, CashTransferReleased)
+	}
+}
+
+func TestCashTransferTrackerWatch(t *testing.T) {
+	recorder := bcatest.NewRecorder()
+	recorder.Respond("POST", "/fire/transactions", bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusBadGateway}})
+	client := testClient(recorder)
+	tracker := NewCashTransferTracker(&client, nil)
+	tracker.Track(CashTransfer{FormNumber: "FORM1", State: CashTransferReleased})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	tracker.OnError = func(err error) {
+		errs <- err
+		cancel()
+	}
+
+	if err := tracker.Watch(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("Watch() = %v, want context.Canceled", err)
+	}
+	var bcaErr *bca.Error
+	if err := <-errs; !errors.As(err, &bcaErr) || bcaErr.HTTPStatus != http.StatusBadGateway {
+		t.Errorf("OnError() got %v, want the error of the inquiry", err)
+	}
+}
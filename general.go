@@ -6,9 +6,9 @@ type ForeignExchangeRateRequest struct {
 }
 
 type RateDetails struct {
-	RateType   string  /// Must be among erate, tt, tc, bn.
-	Buy        float64 `json:",string"`
-	Sell       float64 `json:",string"`
+	RateType   RateType
+	Buy        Amount
+	Sell       Amount
 	LastUpdate string
 }
 
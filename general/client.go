@@ -9,22 +9,43 @@ import (
 
 //Client is used to invoke BCA General API
 type Client struct {
-	Client      bca.APIImplementation
+	Client      bca.API
 	CorporateID string
 	AccessToken string
+	TokenSource bca.TokenSource
 }
 
 //NewClient is used to initialize new general Client
 func NewClient(config bca.Config) Client {
+	api := bca.NewAPI(config)
 	return Client{
 		CorporateID: config.CorporateID,
-		Client:      bca.NewAPI(config),
+		Client:      &api,
 	}
 }
 
+//accessToken returns the token from TokenSource when it is set, otherwise the static AccessToken
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.TokenSource == nil {
+		return c.AccessToken, nil
+	}
+	return c.TokenSource.Token(ctx)
+}
+
+//checkToken drops the token cached by TokenSource when BCA rejected it, so that the next call fetches a new one
+func (c *Client) checkToken(err error) error {
+	if invalidator, ok := c.TokenSource.(bca.TokenInvalidator); ok && bca.TokenRejected(err) {
+		invalidator.Invalidate()
+	}
+	return err
+}
+
 //FundTransfer is used to send fund transfer instructions to BCA using this service. The source of fund transfer must be from corporate’s own deposit account. The recipient may be any deposit account within BCA
 func (c *Client) ForeignExchangeRate(ctx context.Context, ptr_foreignExchangeRate *bca.ForeignExchangeRateRequest) (*bca.ForeignExchangeRateResponse, error) {
 	var foreignExchangeRateResponse bca.ForeignExchangeRateResponse
+	if err := (*ptr_foreignExchangeRate).Validate(); err != nil {
+		return &foreignExchangeRateResponse, err
+	}
 
 	path := "/general/rate/forex"
 
@@ -36,7 +57,12 @@ func (c *Client) ForeignExchangeRate(ctx context.Context, ptr_foreignExchangeRat
 	v.Add("RateType", rateType)
 	path += "?" + v.Encode()
 
-	if err := c.Client.Call("GET", path, c.AccessToken, nil, nil, &foreignExchangeRateResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &foreignExchangeRateResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, nil, nil, &foreignExchangeRateResponse)); err != nil {
 		return &foreignExchangeRateResponse, err
 	}
 	return &foreignExchangeRateResponse, nil
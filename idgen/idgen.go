@@ -0,0 +1,213 @@
+//Package idgen generates the identifiers BCA expects in transfers: 8 digit TransactionIDs unique per corporate
+//per day, ReferenceIDs and FIRe FormNumbers. Identifiers come from per-day counters kept in a SequenceStore, or
+//are derived from an internal key such as a UUID so that retries reuse the identifier of the first attempt.
+package idgen
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ianeinser/bca-api-go/business"
+)
+
+//Limits of the generated identifiers, within the rules of bca Validate
+const (
+	maxDailyCounter = 99999999
+	maxFormCounter  = 9999999999
+	// maxAttempts bounds the candidates tried for an identifier before ErrCollision
+	maxAttempts = 100
+)
+
+//ErrExhausted is returned when the counter of the day has no identifier left
+var ErrExhausted = errors.New("idgen: no identifier left for the day")
+
+//ErrCollision is returned when every candidate identifier was already issued
+var ErrCollision = errors.New("idgen: identifier collision")
+
+//Generator represents the identifiers issued for one corporate
+type Generator struct {
+	CorporateID string
+	Store       SequenceStore
+	// Now returns the current time, time.Now when nil. Days start at midnight in Jakarta
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+//NewGenerator is used to initialize new Generator
+func NewGenerator(corporateID string, store SequenceStore) *Generator {
+	return &Generator{CorporateID: corporateID, Store: store}
+}
+
+//TransactionID returns the next TransactionID of the day and its TransactionDate
+func (g *Generator) TransactionID(ctx context.Context) (transactionID, transactionDate string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	transactionDate = g.today()
+	scope := "tx/" + g.CorporateID + "/" + transactionDate
+	transactionID, err = g.counted(ctx, scope, scope, maxDailyCounter, func(n uint64) string {
+		return fmt.Sprintf("%08d", n)
+	})
+	return transactionID, transactionDate, err
+}
+
+//ReferenceID returns the next ReferenceID of the day, such as 261018-00000001
+func (g *Generator) ReferenceID(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	return g.counted(ctx, "ref/"+g.CorporateID+"/"+now.Format("2006-01-02"), "ref/"+g.CorporateID, maxDailyCounter, func(n uint64) string {
+		return fmt.Sprintf("%s-%08d", now.Format("060102"), n)
+	})
+}
+
+//FormNumber returns the next FIRe FormNumber of the day, such as 2610180000000001
+func (g *Generator) FormNumber(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	return g.counted(ctx, "form/"+g.CorporateID+"/"+now.Format("2006-01-02"), "form/"+g.CorporateID, maxFormCounter, func(n uint64) string {
+		return fmt.Sprintf("%s%010d", now.Format("060102"), n)
+	})
+}
+
+//TransactionIDFor returns the TransactionID and TransactionDate of an internal key such as a UUID. The first call
+//issues them for the day, derived from the key, and later calls return the same ones whatever the day
+func (g *Generator) TransactionIDFor(ctx context.Context, key string) (transactionID, transactionDate string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	date := g.today()
+	value, err := g.mapped(ctx, "tx-key/"+g.CorporateID, key, func(key string, attempt int) (string, string) {
+		id := fmt.Sprintf("%08d", derive(key, attempt)%maxDailyCounter+1)
+		return "tx/" + g.CorporateID + "/" + date, date + "/" + id
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("idgen: invalid TransactionID %q recorded for %s", value, key)
+	}
+	return parts[1], parts[0], nil
+}
+
+//ReferenceIDFor returns the ReferenceID of an internal key such as a UUID, 15 upper case hexadecimal digits derived from it
+func (g *Generator) ReferenceIDFor(ctx context.Context, key string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.mapped(ctx, "ref-key/"+g.CorporateID, key, func(key string, attempt int) (string, string) {
+		return "ref/" + g.CorporateID, derivedHex(key, attempt, 15)
+	})
+}
+
+//FormNumberFor returns the FIRe FormNumber of an internal key such as a UUID, 16 upper case hexadecimal digits derived from it
+func (g *Generator) FormNumberFor(ctx context.Context, key string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.mapped(ctx, "form-key/"+g.CorporateID, key, func(key string, attempt int) (string, string) {
+		return "form/" + g.CorporateID, derivedHex(key, attempt, 16)
+	})
+}
+
+//counted issues the identifier of the next value of the counter of counterScope, skipping the identifiers already
+//issued in scope
+func (g *Generator) counted(ctx context.Context, counterScope, scope string, max uint64, format func(uint64) string) (string, error) {
+	owner, err := nonce()
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		n, err := g.Store.Next(ctx, counterScope)
+		if err != nil {
+			return "", err
+		}
+		if n > max {
+			return "", ErrExhausted
+		}
+
+		id := format(n)
+		claimed, err := g.Store.Claim(ctx, scope, id, owner)
+		if err != nil {
+			return "", err
+		}
+		if claimed == owner {
+			return id, nil
+		}
+	}
+	return "", ErrCollision
+}
+
+//mapped returns the value recorded for key in mapScope, otherwise claims in its scope the first candidate not
+//issued before and records it for key. A candidate value may be prefixed with a date and a slash, the identifier following it
+func (g *Generator) mapped(ctx context.Context, mapScope, key string, candidate func(key string, attempt int) (scope, value string)) (string, error) {
+	// UUIDs are compared case-insensitively
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return "", errors.New("idgen: empty key")
+	}
+	if value, ok, err := g.Store.Get(ctx, mapScope, key); err != nil || ok {
+		return value, err
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		scope, value := candidate(key, attempt)
+		id := value[strings.LastIndex(value, "/")+1:]
+
+		claimed, err := g.Store.Claim(ctx, scope, id, "key:"+key)
+		if err != nil {
+			return "", err
+		}
+		if claimed == "key:"+key {
+			return g.Store.Claim(ctx, mapScope, key, value)
+		}
+	}
+	return "", ErrCollision
+}
+
+func (g *Generator) now() time.Time {
+	if g.Now != nil {
+		return g.Now().In(business.Jakarta)
+	}
+	return time.Now().In(business.Jakarta)
+}
+
+func (g *Generator) today() string {
+	return g.now().Format("2006-01-02")
+}
+
+//derive returns a number derived from key, a different one for every attempt
+func derive(key string, attempt int) uint64 {
+	sum := sha256.Sum256([]byte(key + "#" + strconv.Itoa(attempt)))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+func derivedHex(key string, attempt, length int) string {
+	sum := sha256.Sum256([]byte(key + "#" + strconv.Itoa(attempt)))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))[:length]
+}
+
+//nonce identifies the claims of one counted identifier, so that a counter reset is detected as a collision
+func nonce() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "seq:" + hex.EncodeToString(b), nil
+}
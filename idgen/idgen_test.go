@@ -0,0 +1,115 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ianeinser/bca-api-go/business"
+)
+
+//testGenerator returns a Generator of a memory store whose clock is *now
+func testGenerator(now *time.Time) *Generator {
+	g := NewGenerator("BCAAPI2016", NewMemorySequenceStore())
+	g.Now = func() time.Time { return *now }
+	return g
+}
+
+func TestGeneratorCounted(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, business.Jakarta)
+	g := testGenerator(&now)
+	ctx := context.Background()
+
+	// 00000002 was issued before, such as by TransactionIDFor
+	if _, err := g.Store.Claim(ctx, "tx/BCAAPI2016/2026-10-18", "00000002", "key:other"); err != nil {
+		t.Fatalf("Claim() = %v", err)
+	}
+
+	for _, want := range []string{"00000001", "00000003"} {
+		transactionID, transactionDate, err := g.TransactionID(ctx)
+		if err != nil || transactionID != want || transactionDate != "2026-10-18" {
+			t.Errorf("TransactionID() = %s, %s, %v, want %s, 2026-10-18", transactionID, transactionDate, err, want)
+		}
+	}
+
+	// Days start at midnight in Jakarta, which is still the 18th in UTC
+	now = time.Date(2026, 10, 18, 17, 0, 0, 0, time.UTC)
+	if transactionID, transactionDate, err := g.TransactionID(ctx); err != nil || transactionID != "00000001" || transactionDate != "2026-10-19" {
+		t.Errorf("TransactionID() of the next day = %s, %s, %v, want 00000001, 2026-10-19", transactionID, transactionDate, err)
+	}
+
+	if referenceID, err := g.ReferenceID(ctx); err != nil || referenceID != "261019-00000001" {
+		t.Errorf("ReferenceID() = %s, %v, want 261019-00000001", referenceID, err)
+	}
+	if formNumber, err := g.FormNumber(ctx); err != nil || formNumber != "2610190000000001" {
+		t.Errorf("FormNumber() = %s, %v, want 2610190000000001", formNumber, err)
+	}
+}
+
+func TestGeneratorExhausted(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, business.Jakarta)
+	g := testGenerator(&now)
+	g.Store.(*MemorySequenceStore).counters["tx/BCAAPI2016/2026-10-18"] = maxDailyCounter - 1
+
+	if transactionID, _, err := g.TransactionID(context.Background()); err != nil || transactionID != "99999999" {
+		t.Errorf("TransactionID() = %s, %v, want 99999999", transactionID, err)
+	}
+	if _, _, err := g.TransactionID(context.Background()); !errors.Is(err, ErrExhausted) {
+		t.Errorf("TransactionID() = %v, want ErrExhausted", err)
+	}
+}
+
+func TestGeneratorTransactionIDFor(t *testing.T) {
+	now := time.Date(2026, 10, 18, 23, 59, 0, 0, business.Jakarta)
+	g := testGenerator(&now)
+	ctx := context.Background()
+
+	transactionID, transactionDate, err := g.TransactionIDFor(ctx, "6F9619FF-8B86-D011-B42D-00C04FC964FF")
+	if err != nil || len(transactionID) != 8 || transactionDate != "2026-10-18" {
+		t.Fatalf("TransactionIDFor() = %s, %s, %v, want a TransactionID of 2026-10-18", transactionID, transactionDate, err)
+	}
+
+	// A retry after midnight, with the key in lower case, returns the first TransactionID and its day
+	now = now.Add(2 * time.Minute)
+	if retryID, retryDate, err := g.TransactionIDFor(ctx, "6f9619ff-8b86-d011-b42d-00c04fc964ff"); err != nil || retryID != transactionID || retryDate != transactionDate {
+		t.Errorf("TransactionIDFor() retried = %s, %s, %v, want %s, %s", retryID, retryDate, err, transactionID, transactionDate)
+	}
+
+	if _, otherDate, err := g.TransactionIDFor(ctx, "other"); err != nil || otherDate != "2026-10-19" {
+		t.Errorf("TransactionIDFor() of another key = %s, %v, want 2026-10-19", otherDate, err)
+	}
+
+	// The counter skips the TransactionID issued for the key
+	g.Store.(*MemorySequenceStore).counters["tx/BCAAPI2016/2026-10-18"] = derive("6f9619ff-8b86-d011-b42d-00c04fc964ff", 0) % maxDailyCounter
+	now = now.Add(-2 * time.Minute)
+	if counted, _, err := g.TransactionID(ctx); err != nil || counted == transactionID {
+		t.Errorf("TransactionID() = %s, %v, want another one than %s", counted, err, transactionID)
+	}
+
+	if _, _, err := g.TransactionIDFor(ctx, " "); err == nil {
+		t.Error("TransactionIDFor() of an empty key = nil, want an error")
+	}
+}
+
+func TestGeneratorIDFor(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, business.Jakarta)
+	g := testGenerator(&now)
+	ctx := context.Background()
+
+	referenceID, err := g.ReferenceIDFor(ctx, "key1")
+	if err != nil || len(referenceID) != 15 {
+		t.Fatalf("ReferenceIDFor() = %s, %v, want 15 digits", referenceID, err)
+	}
+	if retry, err := g.ReferenceIDFor(ctx, "KEY1"); err != nil || retry != referenceID {
+		t.Errorf("ReferenceIDFor() retried = %s, %v, want %s", retry, err, referenceID)
+	}
+
+	formNumber, err := g.FormNumberFor(ctx, "key1")
+	if err != nil || len(formNumber) != 16 {
+		t.Fatalf("FormNumberFor() = %s, %v, want 16 digits", formNumber, err)
+	}
+	if other, err := g.FormNumberFor(ctx, "key2"); err != nil || other == formNumber {
+		t.Errorf("FormNumberFor() of another key = %s, %v, want another one than %s", other, err, formNumber)
+	}
+}
@@ -0,0 +1,316 @@
+package idgen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ianeinser/bca-api-go/business"
+)
+
+//SequenceStore keeps the counters and the issued identifiers of a Generator, grouped in scopes such as a corporate and a day
+type SequenceStore interface {
+	// Next increments the counter of scope and returns its new value, the first being 1
+	Next(ctx context.Context, scope string) (uint64, error)
+	// Get returns the value recorded under key in scope
+	Get(ctx context.Context, scope, key string) (string, bool, error)
+	// Claim records value under key in scope unless a value is already recorded there, and returns the value of key.
+	// A returned value different from value means that key was claimed before
+	Claim(ctx context.Context, scope, key, value string) (string, error)
+}
+
+//MemorySequenceStore is a SequenceStore held in memory, its counters restart when the process stops
+type MemorySequenceStore struct {
+	mu       sync.Mutex
+	counters map[string]uint64
+	values   map[string]map[string]string
+}
+
+//NewMemorySequenceStore is used to initialize new MemorySequenceStore
+func NewMemorySequenceStore() *MemorySequenceStore {
+	return &MemorySequenceStore{counters: map[string]uint64{}, values: map[string]map[string]string{}}
+}
+
+//Next increments the counter of scope
+func (m *MemorySequenceStore) Next(ctx context.Context, scope string) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counters == nil {
+		m.counters = map[string]uint64{}
+	}
+	m.counters[scope]++
+	return m.counters[scope], nil
+}
+
+// Get returns the value recorded under key in scope
+func (m *MemorySequenceStore) Get(ctx context.Context, scope, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.values[scope][key]
+	return value, ok, nil
+}
+
+//Claim records value under key in scope unless a value is already recorded there
+func (m *MemorySequenceStore) Claim(ctx context.Context, scope, key, value string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.values[scope][key]; ok {
+		return existing, nil
+	}
+	m.set(scope, key, value)
+	return value, nil
+}
+
+//set records value under key in scope. m.mu must be held
+func (m *MemorySequenceStore) set(scope, key, value string) {
+	if m.values == nil {
+		m.values = map[string]map[string]string{}
+	}
+	if m.values[scope] == nil {
+		m.values[scope] = map[string]string{}
+	}
+	m.values[scope][key] = value
+}
+
+//forget drops the counters and values of the scopes ending with a day before cutoff, and the values starting with
+//one, such as the TransactionIDs of TransactionIDFor. Days are yyyy-MM-dd. m.mu must be held
+func (m *MemorySequenceStore) forget(cutoff string) {
+	for scope := range m.counters {
+		if dayBefore(scope[strings.LastIndex(scope, "/")+1:], cutoff) {
+			delete(m.counters, scope)
+		}
+	}
+	for scope, values := range m.values {
+		if dayBefore(scope[strings.LastIndex(scope, "/")+1:], cutoff) {
+			delete(m.values, scope)
+			continue
+		}
+		for key, value := range values {
+			if i := strings.Index(value, "/"); i >= 0 && dayBefore(value[:i], cutoff) {
+				delete(values, key)
+			}
+		}
+	}
+}
+
+//dayBefore reports whether day is a yyyy-MM-dd day before cutoff
+func dayBefore(day, cutoff string) bool {
+	if _, err := time.Parse("2006-01-02", day); err != nil {
+		return false
+	}
+	return day < cutoff
+}
+
+//FileSequenceStore is a SequenceStore kept in a JSON lines file, every counter increment and claim appending one
+//synced line. Its state is also held in memory. Both grow with every identifier issued, Compact bounds them
+type FileSequenceStore struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	memory MemorySequenceStore
+}
+
+//sequenceRecord is a line of a FileSequenceStore, a counter value when Key is empty, otherwise a claim
+type sequenceRecord struct {
+	Scope   string
+	Counter uint64 `json:",omitempty"`
+	Key     string `json:",omitempty"`
+	Value   string `json:",omitempty"`
+}
+
+//OpenFileSequenceStore opens the sequence file at path, creating it when it does not exist. A last line cut short
+//by a crash is removed, any other invalid line is an error
+func OpenFileSequenceStore(path string) (*FileSequenceStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FileSequenceStore{path: path, file: file}
+	if err := f.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+//load reads back the records of the file
+func (f *FileSequenceStore) load() error {
+	r := bufio.NewReader(f.file)
+	var size int64
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				// The last write was interrupted, its partial line is dropped
+				return f.file.Truncate(size)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		size += int64(len(line))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record sequenceRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("idgen: line %d of %s: %v", n, f.file.Name(), err)
+		}
+		if record.Key == "" {
+			if f.memory.counters == nil {
+				f.memory.counters = map[string]uint64{}
+			}
+			f.memory.counters[record.Scope] = record.Counter
+		} else {
+			f.memory.set(record.Scope, record.Key, record.Value)
+		}
+	}
+}
+
+//Next increments the counter of scope
+func (f *FileSequenceStore) Next(ctx context.Context, scope string) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := f.memory.counters[scope] + 1
+	if err := f.append(sequenceRecord{Scope: scope, Counter: n}); err != nil {
+		return 0, err
+	}
+	return f.memory.Next(ctx, scope)
+}
+
+// Get returns the value recorded under key in scope
+func (f *FileSequenceStore) Get(ctx context.Context, scope, key string) (string, bool, error) {
+	return f.memory.Get(ctx, scope, key)
+}
+
+//Claim records value under key in scope unless a value is already recorded there
+func (f *FileSequenceStore) Claim(ctx context.Context, scope, key, value string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok, _ := f.memory.Get(ctx, scope, key); ok {
+		return existing, nil
+	}
+	if err := f.append(sequenceRecord{Scope: scope, Key: key, Value: value}); err != nil {
+		return "", err
+	}
+	return f.memory.Claim(ctx, scope, key, value)
+}
+
+//Compact forgets the identifiers of the days before the day of before in Jakarta, then rewrites the file with one line
+//per counter and claim, replacing it atomically. Forgotten are the per-day counters and TransactionIDs, and the
+//TransactionIDFor keys issued before that day, which get a new TransactionID when used again. ReferenceIDs and
+//FormNumbers are kept, as they must stay unique across days. Compact should run daily, keeping the days retries may still
+//happen, such as with time.Now().AddDate(0, 0, -30)
+func (f *FileSequenceStore) Compact(before time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.memory.mu.Lock()
+	f.memory.forget(before.In(business.Jakarta).Format("2006-01-02"))
+	records := make([]sequenceRecord, 0, len(f.memory.counters))
+	for scope, counter := range f.memory.counters {
+		records = append(records, sequenceRecord{Scope: scope, Counter: counter})
+	}
+	for scope, values := range f.memory.values {
+		for key, value := range values {
+			records = append(records, sequenceRecord{Scope: scope, Key: key, Value: value})
+		}
+	}
+	f.memory.mu.Unlock()
+	// A stable order keeps the rewritten file easy to compare
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Scope != records[j].Scope {
+			return records[i].Scope < records[j].Scope
+		}
+		return records[i].Key < records[j].Key
+	})
+
+	tmpPath := f.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err == nil {
+			_, err = w.Write(append(line, '\n'))
+		}
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	f.file.Close()
+	f.file = file
+	return nil
+}
+
+//Close closes the file
+func (f *FileSequenceStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+//append writes the record as one line and syncs it to disk. A failed write is truncated away so that the next
+//line does not follow a partial one. f.mu must be held
+func (f *FileSequenceStore) append(record sequenceRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	offset, err := f.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		if truncErr := f.file.Truncate(offset); truncErr != nil {
+			return fmt.Errorf("idgen: %v, then cannot truncate %s: %v", err, f.file.Name(), truncErr)
+		}
+		return err
+	}
+	return f.file.Sync()
+}
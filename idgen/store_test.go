@@ -0,0 +1,155 @@
+package idgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ianeinser/bca-api-go/business"
+)
+
+func TestFileSequenceStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.jsonl")
+	ctx := context.Background()
+
+	f, err := OpenFileSequenceStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileSequenceStore() = %v", err)
+	}
+	f.Next(ctx, "tx/A/2026-10-18")
+	f.Next(ctx, "tx/A/2026-10-18")
+	f.Claim(ctx, "tx/A/2026-10-18", "00000002", "seq:1")
+	if claimed, err := f.Claim(ctx, "tx/A/2026-10-18", "00000002", "seq:2"); err != nil || claimed != "seq:1" {
+		t.Errorf("Claim() of a claimed key = %s, %v, want seq:1", claimed, err)
+	}
+	f.Close()
+
+	f, err = OpenFileSequenceStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileSequenceStore() again = %v", err)
+	}
+	defer f.Close()
+	if n, err := f.Next(ctx, "tx/A/2026-10-18"); err != nil || n != 3 {
+		t.Errorf("Next() after reopening = %d, %v, want 3", n, err)
+	}
+	if value, ok, err := f.Get(ctx, "tx/A/2026-10-18", "00000002"); err != nil || !ok || value != "seq:1" {
+		t.Errorf("Get() after reopening = %s, %v, %v, want seq:1", value, ok, err)
+	}
+}
+
+func TestOpenFileSequenceStoreTornLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantNext uint64
+		wantErr  bool
+	}{
+		{
+			name:     "torn last line",
+			content:  "{\"Scope\":\"tx/A/2026-10-18\",\"Counter\":1}\n{\"Scope\":\"tx/A/2026-10-18\",\"Coun",
+			wantNext: 2,
+		},
+		{
+			name:     "complete last line without newline",
+			content:  "{\"Scope\":\"tx/A/2026-10-18\",\"Counter\":1}\n{\"Scope\":\"tx/A/2026-10-18\",\"Counter\":2}",
+			wantNext: 2,
+		},
+		{
+			name:    "invalid line before the last",
+			content: "{\"Scope\":\"tx/A/2026-10-18\",\"Coun\n{\"Scope\":\"tx/A/2026-10-18\",\"Counter\":2}\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ids.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := OpenFileSequenceStore(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenFileSequenceStore() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if n, err := f.Next(context.Background(), "tx/A/2026-10-18"); err != nil || n != tt.wantNext {
+				t.Errorf("Next() = %d, %v, want %d", n, err, tt.wantNext)
+			}
+			f.Close()
+
+			// The partial line was dropped, so the file reads back whole
+			f, err = OpenFileSequenceStore(path)
+			if err != nil {
+				t.Fatalf("OpenFileSequenceStore() again = %v", err)
+			}
+			defer f.Close()
+			if n, _ := f.Next(context.Background(), "tx/A/2026-10-18"); n != tt.wantNext+1 {
+				t.Errorf("Next() after reopening = %d, want %d", n, tt.wantNext+1)
+			}
+		})
+	}
+}
+
+func TestFileSequenceStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.jsonl")
+	ctx := context.Background()
+
+	f, err := OpenFileSequenceStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileSequenceStore() = %v", err)
+	}
+	defer func() { f.Close() }()
+
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, business.Jakarta)
+	g := NewGenerator("A", f)
+	g.Now = func() time.Time { return now }
+	for i := 0; i < 10; i++ {
+		g.TransactionID(ctx)
+	}
+	oldID, _, _ := g.TransactionIDFor(ctx, "old")
+	oldReference, _ := g.ReferenceIDFor(ctx, "old")
+	now = now.AddDate(0, 0, 1)
+	for i := 0; i < 10; i++ {
+		g.TransactionID(ctx)
+	}
+
+	if err := f.Compact(now); err != nil {
+		t.Fatalf("Compact() = %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	// The counter and 10 claims of the day remain, with the ReferenceID of "old" and its claim
+	if lines := strings.Count(string(content), "\n"); lines != 13 {
+		t.Errorf("Compact() left %d lines, want 13:\n%s", lines, content)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Compact() left its temporary file: %v", err)
+	}
+
+	// The store keeps working on the rewritten file, and reads it back
+	if transactionID, _, err := g.TransactionID(ctx); err != nil || transactionID != "00000011" {
+		t.Errorf("TransactionID() after Compact = %s, %v, want 00000011", transactionID, err)
+	}
+	f.Close()
+	f, err = OpenFileSequenceStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileSequenceStore() after Compact = %v", err)
+	}
+	g.Store = f
+
+	if transactionID, _, err := g.TransactionID(ctx); err != nil || transactionID != "00000012" {
+		t.Errorf("TransactionID() after reopening = %s, %v, want 00000012", transactionID, err)
+	}
+	if reference, err := g.ReferenceIDFor(ctx, "old"); err != nil || reference != oldReference {
+		t.Errorf("ReferenceIDFor() after Compact = %s, %v, want %s", reference, err, oldReference)
+	}
+	// The TransactionIDFor keys of forgotten days get a TransactionID of the day
+	if _, transactionDate, err := g.TransactionIDFor(ctx, "old"); err != nil || transactionDate != "2026-10-18" {
+		t.Errorf("TransactionIDFor() of a forgotten key = %s, %v, want a TransactionID of 2026-10-18 (was %s)", transactionDate, err, oldID)
+	}
+}
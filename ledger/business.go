@@ -0,0 +1,47 @@
+package ledger
+
+import (
+	"context"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/business"
+)
+
+//BusinessClient sends the transfers of a business.Client and records them in Store
+type BusinessClient struct {
+	Client *business.Client
+	Store  Store
+}
+
+//NewBusinessClient is used to initialize new ledger.BusinessClient
+func NewBusinessClient(client *business.Client, store Store) *BusinessClient {
+	return &BusinessClient{Client: client, Store: store}
+}
+
+//FundTransfer records the transfer before sending it with business.Client.FundTransfer, then records its outcome
+func (c *BusinessClient) FundTransfer(ctx context.Context, ptr_fundTransferRequest *bca.FundTransferRequest) (*bca.FundTransferResponse, error) {
+	request := *ptr_fundTransferRequest
+	entry, err := begin(ctx, c.Store, KindFundTransfer, BusinessKey(request.TransactionDate, request.TransactionID), request)
+	if err != nil {
+		return &bca.FundTransferResponse{}, err
+	}
+
+	ptr_response, err := c.Client.FundTransfer(ctx, ptr_fundTransferRequest)
+	response := *ptr_response
+	err = Finish(c.Store, entry, response, response.Status, response.Status == string(bca.TransferStatusFailed), err)
+	return ptr_response, err
+}
+
+//DomesticFundTransfer records the transfer before sending it with business.Client.DomesticFundTransfer, then records its outcome
+func (c *BusinessClient) DomesticFundTransfer(ctx context.Context, ptr_domesticFundTransferRequest *bca.DomesticFundTransferRequest) (*bca.DomesticFundTransferResponse, error) {
+	request := *ptr_domesticFundTransferRequest
+	entry, err := begin(ctx, c.Store, KindDomesticFundTransfer, BusinessKey(request.TransactionDate, request.TransactionID), request)
+	if err != nil {
+		return &bca.DomesticFundTransferResponse{}, err
+	}
+
+	ptr_response, err := c.Client.DomesticFundTransfer(ctx, ptr_domesticFundTransferRequest)
+	response := *ptr_response
+	err = Finish(c.Store, entry, response, response.Status, response.Status == string(bca.TransferStatusFailed), err)
+	return ptr_response, err
+}
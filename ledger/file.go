@@ -0,0 +1,136 @@
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+//FileStore is a Store kept in a JSON lines file. Every Create and Update appends the whole entry as one line
+//and syncs the file, the last line of an ID being its current state. The entries are also held in memory
+type FileStore struct {
+	mu   sync.Mutex
+	file *os.File
+	// memory indexes the entries read back and written since the file was opened
+	memory MemoryStore
+}
+
+//OpenFileStore opens the ledger file at path, creating it when it does not exist. A last line cut short by a crash
+//is removed, any other invalid line is an error
+func OpenFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FileStore{file: file}
+	if err := f.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+//load reads back the entries of the file
+func (f *FileStore) load() error {
+	r := bufio.NewReader(f.file)
+	var size int64
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				// The last write was interrupted, its partial line is dropped
+				return f.file.Truncate(size)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		size += int64(len(line))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("ledger: line %d of %s: %v", n, f.file.Name(), err)
+		}
+		if err := f.memory.Update(context.Background(), entry); errors.Is(err, ErrNotFound) {
+			f.memory.Create(context.Background(), entry)
+		}
+	}
+}
+
+//Create records a new entry
+func (f *FileStore) Create(ctx context.Context, entry Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.memory.Get(ctx, entry.ID); err == nil {
+		return ErrDuplicate
+	}
+	if err := f.append(entry); err != nil {
+		return err
+	}
+	return f.memory.Create(ctx, entry)
+}
+
+//Update replaces a recorded entry
+func (f *FileStore) Update(ctx context.Context, entry Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.memory.Get(ctx, entry.ID); err != nil {
+		return err
+	}
+	if err := f.append(entry); err != nil {
+		return err
+	}
+	return f.memory.Update(ctx, entry)
+}
+
+//Get returns a recorded entry
+func (f *FileStore) Get(ctx context.Context, id string) (Entry, error) {
+	return f.memory.Get(ctx, id)
+}
+
+//List returns the entries selected by filter in the order they were created
+func (f *FileStore) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	return f.memory.List(ctx, filter)
+}
+
+//Close closes the file
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+//append writes the entry as one line and syncs it to disk. A failed write is truncated away so that the next
+//line does not follow a partial one. f.mu must be held
+func (f *FileStore) append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	offset, err := f.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		if truncErr := f.file.Truncate(offset); truncErr != nil {
+			return fmt.Errorf("ledger: %v, then cannot truncate %s: %v", err, f.file.Name(), truncErr)
+		}
+		return err
+	}
+	return f.file.Sync()
+}
@@ -0,0 +1,108 @@
+package ledger
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/fire"
+)
+
+//redacted replaces the secrets of FIRe payloads in the ledger
+const redacted = "REDACTED"
+
+//FIReClient sends the transfers of a fire.Client and records them in Store
+type FIReClient struct {
+	Client *fire.Client
+	Store  Store
+}
+
+//NewFIReClient is used to initialize new ledger.FIReClient
+func NewFIReClient(client *fire.Client, store Store) *FIReClient {
+	return &FIReClient{Client: client, Store: store}
+}
+
+//TeleTransferToAccount records the transfer before sending it with fire.Client.TeleTransferToAccount, then records its outcome
+func (c *FIReClient) TeleTransferToAccount(ctx context.Context, ptr_ttAccountRequest *bca.TeleTransferAccountRequest) (*bca.TeleTransferAccountResponse, error) {
+	request := *ptr_ttAccountRequest
+	request.Authentication = redactAuth(request.Authentication)
+	entry, err := begin(ctx, c.Store, KindTeleTransferToAccount, request.TransactionDetails.FormNumber, request)
+	if err != nil {
+		return &bca.TeleTransferAccountResponse{}, err
+	}
+
+	ptr_response, err := c.Client.TeleTransferToAccount(ctx, ptr_ttAccountRequest)
+	response := *ptr_response
+	err = Finish(c.Store, entry, response, response.StatusTransaction, response.StatusTransaction != bca.StatusTransactionSuccess, err)
+	return ptr_response, err
+}
+
+//TeleTransferCashTransfer records the transfer before sending it with fire.Client.TeleTransferCashTransfer, then records its outcome
+func (c *FIReClient) TeleTransferCashTransfer(ctx context.Context, ptr_ttCashTransferRequest *bca.TeleTransferCashTransferRequest) (*bca.TeleTransferCashTransferResponse, error) {
+	request := *ptr_ttCashTransferRequest
+	request.Authentication = redactAuth(request.Authentication)
+	request.TransactionDetails.PIN = redact(request.TransactionDetails.PIN)
+	request.TransactionDetails.SecretAnswer = redact(request.TransactionDetails.SecretAnswer)
+	entry, err := begin(ctx, c.Store, KindTeleTransferCashTransfer, request.TransactionDetails.FormNumber, request)
+	if err != nil {
+		return &bca.TeleTransferCashTransferResponse{}, err
+	}
+
+	ptr_response, err := c.Client.TeleTransferCashTransfer(ctx, ptr_ttCashTransferRequest)
+	response := *ptr_response
+	response.TransactionDetails.PIN = redact(response.TransactionDetails.PIN)
+	err = Finish(c.Store, entry, response, response.StatusTransaction, response.StatusTransaction != bca.StatusTransactionSuccess, err)
+	return ptr_response, err
+}
+
+//TeleTransferAmendCashTransfer records the amendment before sending it with fire.Client.TeleTransferAmendCashTransfer,
+//then records its outcome. A cash transfer may be amended several times, so the Key is its FormNumber and the time of the call
+func (c *FIReClient) TeleTransferAmendCashTransfer(ctx context.Context, ptr_ttAmendCashTransferRequest *bca.TeleTransferAmendCashTransferRequest) (*bca.TeleTransferAmendCashTransferResponse, error) {
+	request := *ptr_ttAmendCashTransferRequest
+	request.Authentication = redactAuth(request.Authentication)
+	request.AmendmentDetails.TransactionDetails.SecretAnswer = redact(request.AmendmentDetails.TransactionDetails.SecretAnswer)
+	entry, err := begin(ctx, c.Store, KindTeleTransferAmendCashTransfer, attemptKey(request.TransactionDetails.FormNumber), request)
+	if err != nil {
+		return &bca.TeleTransferAmendCashTransferResponse{}, err
+	}
+
+	ptr_response, err := c.Client.TeleTransferAmendCashTransfer(ctx, ptr_ttAmendCashTransferRequest)
+	response := *ptr_response
+	response.AmendmentDetails.TransactionDetails.SecretAnswer = redact(response.AmendmentDetails.TransactionDetails.SecretAnswer)
+	err = Finish(c.Store, entry, response, response.StatusTransaction, response.StatusTransaction != bca.StatusTransactionSuccess, err)
+	return ptr_response, err
+}
+
+//TeleTransferCancelCashTransfer records the cancellation before sending it with fire.Client.TeleTransferCancelCashTransfer,
+//then records its outcome
+func (c *FIReClient) TeleTransferCancelCashTransfer(ctx context.Context, ptr_ttCancelCashTransferRequest *bca.TeleTransferCancelCashTransferRequest) (*bca.TeleTransferCancelCashTransferResponse, error) {
+	request := *ptr_ttCancelCashTransferRequest
+	request.Authentication = redactAuth(request.Authentication)
+	entry, err := begin(ctx, c.Store, KindTeleTransferCancelCashTransfer, attemptKey(request.TransactionDetails.FormNumber), request)
+	if err != nil {
+		return &bca.TeleTransferCancelCashTransferResponse{}, err
+	}
+
+	ptr_response, err := c.Client.TeleTransferCancelCashTransfer(ctx, ptr_ttCancelCashTransferRequest)
+	response := *ptr_response
+	err = Finish(c.Store, entry, response, response.StatusTransaction, response.StatusTransaction != bca.StatusTransactionSuccess, err)
+	return ptr_response, err
+}
+
+//attemptKey is the Key of calls that may be repeated for one FormNumber
+func attemptKey(formNumber string) string {
+	return formNumber + "/" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+func redactAuth(auth bca.Auth) bca.Auth {
+	auth.AccessCode = redact(auth.AccessCode)
+	return auth
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
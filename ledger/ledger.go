@@ -0,0 +1,188 @@
+//Package ledger keeps an audit trail of the calls that move money. Every transfer is written down before it is
+//sent to BCA and updated with its outcome when the call returns, so that transfers left in doubt by a crash can
+//be found and reported.
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//Kind is the call an entry records
+type Kind string
+
+//Kinds of entries
+const (
+	KindFundTransfer                   Kind = "FundTransfer"
+	KindDomesticFundTransfer           Kind = "DomesticFundTransfer"
+	KindTeleTransferToAccount          Kind = "TeleTransferToAccount"
+	KindTeleTransferCashTransfer       Kind = "TeleTransferCashTransfer"
+	KindTeleTransferAmendCashTransfer  Kind = "TeleTransferAmendCashTransfer"
+	KindTeleTransferCancelCashTransfer Kind = "TeleTransferCancelCashTransfer"
+)
+
+//Status is the outcome of the call of an entry
+type Status string
+
+//Statuses of entries
+const (
+	// StatusQueued is a transfer saved by an outbox that was not sent yet
+	StatusQueued Status = "QUEUED"
+	// StatusSending is written before the call, an entry left in it was interrupted before the call returned
+	StatusSending Status = "SENDING"
+	// StatusSucceeded is a call BCA accepted
+	StatusSucceeded Status = "SUCCEEDED"
+	// StatusFailed is a call BCA or Validate rejected, or a transfer its status inquiry reported Failed, no money moved
+	StatusFailed Status = "FAILED"
+	// StatusInDoubt is a call that returned an error without telling whether BCA applied it
+	StatusInDoubt Status = "IN_DOUBT"
+)
+
+//ErrNotFound is returned by a Store for an unknown entry ID
+var ErrNotFound = errors.New("ledger: entry not found")
+
+//ErrDuplicate is returned by Store.Create for an entry ID already recorded, such as a TransactionID used twice
+var ErrDuplicate = errors.New("ledger: duplicate entry")
+
+//ErrNotRecorded is returned, wrapped, when a call returned but its outcome could not be written to the Store.
+//The response returned with it is valid and the call must not be repeated
+var ErrNotRecorded = errors.New("ledger: call outcome not recorded")
+
+//Entry represents a money-moving call. Request and Response are the JSON payloads, with the FIRe AccessCode,
+//PIN and SecretAnswer redacted
+type Entry struct {
+	// ID is the Kind followed by Key
+	ID   string
+	Kind Kind
+	// Key identifies the transfer, the TransactionDate and TransactionID of business transfers or the FormNumber of FIRe ones
+	Key    string
+	Status Status
+	// BCAStatus is the Status or StatusTransaction of the response, or the BCA error code
+	BCAStatus string
+	Request   json.RawMessage
+	Response  json.RawMessage `json:",omitempty"`
+	Error     string          `json:",omitempty"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+//Filter selects the entries returned by Store.List, its zero value selects every entry
+type Filter struct {
+	Kind     Kind
+	Statuses []Status
+	// From and To bound CreatedAt, a zero time leaves that side open
+	From time.Time
+	To   time.Time
+}
+
+//Matches reports whether the entry is selected by the filter
+func (f Filter) Matches(entry Entry) bool {
+	if f.Kind != "" && entry.Kind != f.Kind {
+		return false
+	}
+	if !f.From.IsZero() && entry.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && entry.CreatedAt.After(f.To) {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if entry.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+//Store keeps the entries of the ledger
+type Store interface {
+	// Create records a new entry, ErrDuplicate is returned when its ID exists
+	Create(ctx context.Context, entry Entry) error
+	// Update replaces a recorded entry, ErrNotFound is returned when its ID does not exist
+	Update(ctx context.Context, entry Entry) error
+	Get(ctx context.Context, id string) (Entry, error)
+	// List returns the entries selected by filter in the order they were created
+	List(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+//NewEntry returns the entry of a call about to be sent, in StatusSending
+func NewEntry(kind Kind, key string, request interface{}) (Entry, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	now := time.Now()
+	return Entry{
+		ID:        string(kind) + "/" + key,
+		Kind:      kind,
+		Key:       key,
+		Status:    StatusSending,
+		Request:   payload,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+//BusinessKey is the Key of business transfers, a TransactionID being unique for its day only
+func BusinessKey(transactionDate, transactionID string) string {
+	return transactionDate + "/" + transactionID
+}
+
+//begin writes down a call before it is sent
+func begin(ctx context.Context, store Store, kind Kind, key string, request interface{}) (Entry, error) {
+	entry, err := NewEntry(kind, key, request)
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := store.Create(ctx, entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+//Finish records the outcome of the call of entry. rejected tells whether a response without error was refused by BCA.
+//The error of the call is returned, along with the failure to record its outcome wrapping ErrNotRecorded
+func Finish(store Store, entry Entry, response interface{}, bcaStatus string, rejected bool, callErr error) error {
+	entry.BCAStatus = bcaStatus
+	entry.UpdatedAt = time.Now()
+
+	var bcaErr *bca.Error
+	if errors.As(callErr, &bcaErr) {
+		entry.BCAStatus = bcaErr.ErrorCode
+	}
+	switch {
+	case callErr == nil && rejected:
+		entry.Status = StatusFailed
+	case callErr == nil:
+		entry.Status = StatusSucceeded
+	case errors.Is(callErr, bca.ErrTransferFailed), bca.Rejected(callErr):
+		entry.Status = StatusFailed
+	default:
+		// 5xx, 401 and 429 responses, or a status inquiry reporting the transfer Pending, do not tell whether BCA applied the call
+		entry.Status = StatusInDoubt
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	} else if payload, err := json.Marshal(response); err == nil {
+		entry.Response = payload
+	}
+
+	// The outcome is recorded even when ctx was cancelled during the call
+	err := store.Update(context.Background(), entry)
+	switch {
+	case err == nil:
+		return callErr
+	case callErr == nil:
+		return fmt.Errorf("%w: %v", ErrNotRecorded, err)
+	}
+	return bca.MultiError{callErr, fmt.Errorf("%w: %v", ErrNotRecorded, err)}
+}
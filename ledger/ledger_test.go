@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+func TestFinish(t *testing.T) {
+	serverError := &bca.Error{HTTPStatus: http.StatusBadGateway, ErrorCode: "ESB-99-999"}
+
+	tests := []struct {
+		name          string
+		rejected      bool
+		callErr       error
+		want          Status
+		wantBCAStatus string
+	}{
+		{name: "accepted", want: StatusSucceeded, wantBCAStatus: "Success"},
+		{name: "refused in the response", rejected: true, want: StatusFailed, wantBCAStatus: "Success"},
+		{name: "rejected by BCA", callErr: &bca.Error{HTTPStatus: http.StatusBadRequest, ErrorCode: bca.ErrInsufficientFunds.ErrorCode}, want: StatusFailed, wantBCAStatus: bca.ErrInsufficientFunds.ErrorCode},
+		{name: "rejected by Validate", callErr: bca.ErrValidation, want: StatusFailed, wantBCAStatus: "Success"},
+		{name: "inquiry reported failed", callErr: bca.MultiError{serverError, bca.ErrTransferFailed}, want: StatusFailed, wantBCAStatus: serverError.ErrorCode},
+		{name: "inquiry reported pending", callErr: bca.MultiError{serverError, bca.ErrTransferPending}, want: StatusInDoubt, wantBCAStatus: serverError.ErrorCode},
+		{name: "server error", callErr: serverError, want: StatusInDoubt, wantBCAStatus: serverError.ErrorCode},
+		{name: "unauthorized", callErr: &bca.Error{HTTPStatus: http.StatusUnauthorized, ErrorCode: bca.ErrExpiredToken.ErrorCode}, want: StatusInDoubt, wantBCAStatus: bca.ErrExpiredToken.ErrorCode},
+		{name: "too many requests", callErr: &bca.Error{HTTPStatus: http.StatusTooManyRequests}, want: StatusInDoubt},
+		{name: "cancelled", callErr: context.Canceled, want: StatusInDoubt, wantBCAStatus: "Success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			entry, err := begin(context.Background(), store, KindFundTransfer, "2026-10-18/00000001", nil)
+			if err != nil {
+				t.Fatalf("begin() = %v", err)
+			}
+
+			// The error of the call is returned as is once its outcome is recorded
+			if err := Finish(store, entry, nil, "Success", tt.rejected, tt.callErr); (err == nil) != (tt.callErr == nil) || err != nil && err.Error() != tt.callErr.Error() {
+				t.Errorf("Finish() = %v, want %v", err, tt.callErr)
+			}
+
+			got, _ := store.Get(context.Background(), entry.ID)
+			if got.Status != tt.want || got.BCAStatus != tt.wantBCAStatus {
+				t.Errorf("Status = %s, BCAStatus = %q, want %s, %q", got.Status, got.BCAStatus, tt.want, tt.wantBCAStatus)
+			}
+		})
+	}
+}
+
+func TestFinishNotRecorded(t *testing.T) {
+	tests := []struct {
+		name    string
+		callErr error
+	}{
+		{name: "accepted"},
+		{name: "server error", callErr: &bca.Error{HTTPStatus: http.StatusBadGateway}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The entry was never created, so the Store cannot update it
+			entry, _ := NewEntry(KindFundTransfer, "2026-10-18/00000001", nil)
+
+			err := Finish(NewMemoryStore(), entry, nil, "", false, tt.callErr)
+			if !errors.Is(err, ErrNotRecorded) {
+				t.Errorf("Finish() = %v, want ErrNotRecorded", err)
+			}
+			if tt.callErr != nil && !errors.Is(err, tt.callErr) {
+				t.Errorf("Finish() = %v, want it to keep %v", err, tt.callErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ledger
+
+import (
+	"context"
+	"sync"
+)
+
+//MemoryStore is a Store held in memory, its entries are lost when the process stops
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	order   []string
+}
+
+//NewMemoryStore is used to initialize new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]Entry{}}
+}
+
+//Create records a new entry
+func (m *MemoryStore) Create(ctx context.Context, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = map[string]Entry{}
+	}
+	if _, ok := m.entries[entry.ID]; ok {
+		return ErrDuplicate
+	}
+	m.entries[entry.ID] = entry
+	m.order = append(m.order, entry.ID)
+	return nil
+}
+
+//Update replaces a recorded entry
+func (m *MemoryStore) Update(ctx context.Context, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.entries[entry.ID]; !ok {
+		return ErrNotFound
+	}
+	m.entries[entry.ID] = entry
+	return nil
+}
+
+//Get returns a recorded entry
+func (m *MemoryStore) Get(ctx context.Context, id string) (Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[id]
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+	return entry, nil
+}
+
+//List returns the entries selected by filter in the order they were created
+func (m *MemoryStore) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var entries []Entry
+	for _, id := range m.order {
+		if entry := m.entries[id]; filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
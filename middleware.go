@@ -0,0 +1,44 @@
+package bca
+
+import (
+	"net/http"
+	"time"
+)
+
+//Exchange represents a single HTTP round trip with BCA API
+type Exchange struct {
+	// Request is the signed request. Its body may already be consumed, use Request.GetBody to read it again
+	Request    *http.Request
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+}
+
+//RoundTrip sends a signed request to BCA API and returns the raw response
+type RoundTrip func(req *http.Request) (*Exchange, error)
+
+//Middleware wraps a RoundTrip. It may inspect or change the request and response, or return without calling next
+type Middleware func(next RoundTrip) RoundTrip
+
+//Option is used to customize APIImplementation in NewAPI
+type Option func(*APIImplementation)
+
+//WithMiddleware appends middlewares to the chain of APIImplementation, the first one is the outermost
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *APIImplementation) {
+		c.Middlewares = append(append([]Middleware(nil), c.Middlewares...), middlewares...)
+	}
+}
+
+//RequestIDMiddleware sets the header to a new ID from generate on every request that does not carry it yet
+func RequestIDMiddleware(header string, generate func() string) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *http.Request) (*Exchange, error) {
+			if req.Header.Get(header) == "" {
+				req.Header.Set(header, generate())
+			}
+			return next(req)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package bca
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//StatementFileReader reads the entries of an offline statement file, in SWIFT MT940 format, one at a time so that
+//the file never has to be held in memory. A file may hold several statements, one per day, of the same account
+type StatementFileReader struct {
+	// Location is the time zone of the entry dates, time.Local when nil
+	Location *time.Location
+
+	r        *bufio.Reader
+	unread   *mt940Field
+	line     int
+	account  string
+	currency string
+	opening  Amount
+	closing  Amount
+	balance  Amount
+	opened   bool
+	// unclosed is set from the opening balance of a statement until its closing balance
+	unclosed bool
+}
+
+type mt940Field struct {
+	tag   string
+	lines []string
+	line  int
+}
+
+//NewStatementFileReader is used to initialize new StatementFileReader reading from r
+func NewStatementFileReader(r io.Reader) *StatementFileReader {
+	return &StatementFileReader{r: bufio.NewReader(r)}
+}
+
+//AccountNumber returns the account of the statement read so far
+func (sr *StatementFileReader) AccountNumber() string {
+	return sr.account
+}
+
+//Currency returns the currency of the statement read so far
+func (sr *StatementFileReader) Currency() string {
+	return sr.currency
+}
+
+//OpeningBalance returns the opening balance of the first statement of the file
+func (sr *StatementFileReader) OpeningBalance() Amount {
+	return sr.opening
+}
+
+//ClosingBalance returns the closing balance of the last statement read so far, it is final once Next returns io.EOF
+func (sr *StatementFileReader) ClosingBalance() Amount {
+	return sr.closing
+}
+
+//Next returns the next entry of the file with its running balance, or io.EOF after the last one.
+//A file ending before the closing balance of its last statement is truncated, Next then returns io.ErrUnexpectedEOF
+func (sr *StatementFileReader) Next() (StatementEntry, error) {
+	for {
+		field, err := sr.readField()
+		if err == io.EOF && sr.unclosed {
+			return StatementEntry{}, fmt.Errorf("bca: statement file line %d: no closing balance: %w", sr.line, io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return StatementEntry{}, err
+		}
+
+		switch field.tag {
+		case "25":
+			account := field.lines[0]
+			if i := strings.LastIndexByte(account, '/'); i >= 0 {
+				account = account[i+1:]
+			}
+			sr.account = strings.TrimSpace(account)
+		case "60F", "60M":
+			balance, err := sr.parseBalance(field)
+			if err != nil {
+				return StatementEntry{}, err
+			}
+			if !sr.opened {
+				sr.opening = balance
+				sr.opened = true
+			}
+			sr.balance = balance
+			sr.unclosed = true
+		case "62F", "62M":
+			balance, err := sr.parseBalance(field)
+			if err != nil {
+				return StatementEntry{}, err
+			}
+			sr.closing = balance
+			sr.unclosed = false
+		case "61":
+			entry, err := sr.parseEntry(field)
+			if err != nil {
+				return StatementEntry{}, err
+			}
+
+			// The narrative of the entry, if any, follows in a :86: field
+			next, err := sr.readField()
+			switch {
+			case err == io.EOF:
+			case err != nil:
+				return StatementEntry{}, err
+			case next.tag == "86":
+				entry.Name = next.lines[0]
+				entry.Trailer = strings.Join(next.lines[1:], " ")
+			default:
+				sr.unread = next
+			}
+
+			sr.balance = entry.Apply(sr.balance)
+			entry.Balance = sr.balance
+			return entry, nil
+		}
+	}
+}
+
+//readField returns the next tagged field along with its continuation lines, skipping the message envelope
+func (sr *StatementFileReader) readField() (*mt940Field, error) {
+	if field := sr.unread; field != nil {
+		sr.unread = nil
+		return field, nil
+	}
+
+	var field *mt940Field
+	for {
+		b, err := sr.r.Peek(1)
+		if err == io.EOF {
+			if field != nil {
+				return field, nil
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// A tag line ends the field being read and is left for the next call
+		if b[0] == ':' && field != nil {
+			return field, nil
+		}
+
+		line, err := sr.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		sr.line++
+		line = strings.TrimRight(line, "\r\n")
+
+		// Envelope blocks such as {1:...}{2:...}{4: precede the fields of a message, "-}" ends it
+		if strings.HasPrefix(line, "{") {
+			i := strings.Index(line, "{4:")
+			if i < 0 {
+				continue
+			}
+			line = line[i+3:]
+		}
+		if line == "-" || strings.HasPrefix(line, "-}") {
+			if field != nil {
+				return field, nil
+			}
+			continue
+		}
+
+		if field == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			tag, value, ok := splitTag(line)
+			if !ok {
+				return nil, fmt.Errorf("bca: statement file line %d: unexpected %q", sr.line, line)
+			}
+			field = &mt940Field{tag: tag, lines: []string{value}, line: sr.line}
+			continue
+		}
+		field.lines = append(field.lines, strings.TrimSpace(line))
+	}
+}
+
+//splitTag splits a field line such as ":60F:C200101IDR1000,00" into its tag and value
+func splitTag(line string) (tag, value string, ok bool) {
+	if !strings.HasPrefix(line, ":") {
+		return "", "", false
+	}
+	end := strings.IndexByte(line[1:], ':')
+	if end < 2 || end > 3 {
+		return "", "", false
+	}
+	return line[1 : end+1], strings.TrimSpace(line[end+2:]), true
+}
+
+//parseBalance parses a balance field: D/C mark, date YYMMDD, currency and amount
+func (sr *StatementFileReader) parseBalance(field *mt940Field) (Amount, error) {
+	value := field.lines[0]
+	if len(value) < 11 || (value[0] != 'C' && value[0] != 'D') {
+		return Amount{}, sr.fieldError(field)
+	}
+
+	sr.currency = value[7:10]
+	balance, err := parseFileAmount(value[10:], sr.currency)
+	if err != nil {
+		return Amount{}, sr.fieldError(field)
+	}
+	if value[0] == 'D' {
+		balance = balance.Neg()
+	}
+	return balance, nil
+}
+
+//parseEntry parses a :61: statement line: value date YYMMDD, optional entry date MMDD, D/C/RD/RC mark,
+//optional funds code, amount and the transaction type and references, which go to Trailer until a :86: is found
+func (sr *StatementFileReader) parseEntry(field *mt940Field) (StatementEntry, error) {
+	value := field.lines[0]
+	if len(value) < 6 {
+		return StatementEntry{}, sr.fieldError(field)
+	}
+
+	location := sr.Location
+	if location == nil {
+		location = time.Local
+	}
+	date, err := time.ParseInLocation("060102", value[:6], location)
+	if err != nil {
+		return StatementEntry{}, sr.fieldError(field)
+	}
+	rest := value[6:]
+	if len(rest) >= 4 && isDigits(rest[:4]) {
+		rest = rest[4:]
+	}
+
+	entry := StatementEntry{Date: date}
+	switch {
+	case strings.HasPrefix(rest, "RC"):
+		entry.Type, rest = TransactionTypeDebit, rest[2:]
+	case strings.HasPrefix(rest, "RD"):
+		entry.Type, rest = TransactionTypeCredit, rest[2:]
+	case strings.HasPrefix(rest, "C"):
+		entry.Type, rest = TransactionTypeCredit, rest[1:]
+	case strings.HasPrefix(rest, "D"):
+		entry.Type, rest = TransactionTypeDebit, rest[1:]
+	default:
+		return StatementEntry{}, sr.fieldError(field)
+	}
+	if rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+		rest = rest[1:]
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != ',' })
+	if end < 0 {
+		end = len(rest)
+	}
+	if entry.Amount, err = parseFileAmount(rest[:end], sr.currency); err != nil {
+		return StatementEntry{}, sr.fieldError(field)
+	}
+
+	entry.Trailer = strings.TrimSpace(strings.Join(append([]string{rest[end:]}, field.lines[1:]...), " "))
+	return entry, nil
+}
+
+func (sr *StatementFileReader) fieldError(field *mt940Field) error {
+	return fmt.Errorf("bca: statement file line %d: invalid :%s: field %q", field.line, field.tag, field.lines[0])
+}
+
+//parseFileAmount parses an amount with a decimal comma, such as "1500000,00"
+func parseFileAmount(s, currencyCode string) (Amount, error) {
+	if s == "" {
+		return Amount{}, fmt.Errorf("bca: invalid amount %q", s)
+	}
+	amount, err := ParseAmount(strings.Replace(s, ",", ".", 1))
+	if err != nil {
+		return Amount{}, err
+	}
+	if currencyCode == "" {
+		return amount, nil
+	}
+	return amount.ForCurrency(currencyCode)
+}
+
+func isDigits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package bca
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+//statementFile holds two daily statements of one account, each in its own message envelope
+const statementFile = "{1:F01CENAIDJAXXXX0000000000}{2:I940CENAIDJAXXXXN}{4:\r\n" +
+	":20:STMT261016\r\n" +
+	":25:CENAIDJA/0201245680\r\n" +
+	":28C:1/1\r\n" +
+	":60F:C261016IDR1000000,00\r\n" +
+	":61:2610161016C250000,00NTRFNONREF//00000001\r\n" +
+	":86:PT SUMBER MAKMUR\r\n" +
+	"INVOICE 1\r\n" +
+	"TRANSFER MASUK\r\n" +
+	":61:261016D100000,00NCHGNONREF\r\n" +
+	":62F:C261016IDR1150000,00\r\n" +
+	"-}\r\n" +
+	"{1:F01CENAIDJAXXXX0000000000}{2:I940CENAIDJAXXXXN}{4:\r\n" +
+	":20:STMT261017\r\n" +
+	":25:CENAIDJA/0201245680\r\n" +
+	":28C:2/1\r\n" +
+	":60F:C261017IDR1150000,00\r\n" +
+	":61:261017RD50000,00NTRF\r\n" +
+	":86:REVERSAL\r\n" +
+	":62F:C261017IDR1200000,00\r\n" +
+	"-}\r\n"
+
+func TestStatementFileReader(t *testing.T) {
+	sr := NewStatementFileReader(strings.NewReader(statementFile))
+	sr.Location = time.UTC
+
+	want := []StatementEntry{
+		{Date: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Type: TransactionTypeCredit, Amount: MustParseAmount("250000"), Name: "PT SUMBER MAKMUR", Trailer: "INVOICE 1 TRANSFER MASUK", Balance: MustParseAmount("1250000")},
+		{Date: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Type: TransactionTypeDebit, Amount: MustParseAmount("100000"), Trailer: "NCHGNONREF", Balance: MustParseAmount("1150000")},
+		{Date: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), Type: TransactionTypeCredit, Amount: MustParseAmount("50000"), Name: "REVERSAL", Balance: MustParseAmount("1200000")},
+	}
+
+	for i, w := range want {
+		entry, err := sr.Next()
+		if err != nil {
+			t.Fatalf("Next() entry %d = %v", i+1, err)
+		}
+		if !entry.Date.Equal(w.Date) || entry.Type != w.Type || !entry.Amount.Equal(w.Amount) || entry.Name != w.Name || entry.Trailer != w.Trailer || !entry.Balance.Equal(w.Balance) {
+			t.Errorf("entry %d = %+v, want %+v", i+1, entry, w)
+		}
+	}
+	if _, err := sr.Next(); err != io.EOF {
+		t.Fatalf("Next() after the last entry = %v, want io.EOF", err)
+	}
+
+	if sr.AccountNumber() != "0201245680" || sr.Currency() != "IDR" {
+		t.Errorf("account = %s %s, want 0201245680 IDR", sr.AccountNumber(), sr.Currency())
+	}
+	if sr.OpeningBalance().String() != "1000000.00" || sr.ClosingBalance().String() != "1200000.00" {
+		t.Errorf("balances = %s, %s, want 1000000.00, 1200000.00", sr.OpeningBalance(), sr.ClosingBalance())
+	}
+}
+
+func TestStatementFileReaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		entries int
+		wantErr error
+		// wantMessage is checked instead of wantErr when set
+		wantMessage string
+	}{
+		{
+			name:    "truncated before the closing balance",
+			file:    statementFile[:strings.Index(statementFile, ":62F:C261017")],
+			entries: 3,
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "truncated after the opening balance",
+			file:    statementFile[:strings.Index(statementFile, ":61:")],
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:        "truncated within an entry",
+			file:        statementFile[:strings.Index(statementFile, "C250000")],
+			wantMessage: "invalid :61: field",
+		},
+		{
+			name:    "empty",
+			wantErr: io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := NewStatementFileReader(strings.NewReader(tt.file))
+
+			entries := 0
+			var err error
+			for {
+				if _, err = sr.Next(); err != nil {
+					break
+				}
+				entries++
+			}
+			if entries != tt.entries {
+				t.Errorf("read %d entries, want %d", entries, tt.entries)
+			}
+
+			if tt.wantMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantMessage) {
+					t.Errorf("Next() = %v, want %q", err, tt.wantMessage)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Next() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+//Package outbox sends transfers at most once across crashes. A transfer is saved to a ledger.Store as an intent
+//before it is sent, and on restart the transfers whose outcome was lost are resolved by inquiring BCA instead of
+//being sent again.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/business"
+	"github.com/ianeinser/bca-api-go/fire"
+	"github.com/ianeinser/bca-api-go/ledger"
+)
+
+//ErrNotQueued is returned by Submit for an entry that was already sent
+var ErrNotQueued = errors.New("outbox: transfer is not queued")
+
+//ErrUnsupportedKind is returned for an entry of a kind the outbox does not send
+var ErrUnsupportedKind = errors.New("outbox: unsupported transfer kind")
+
+//errNotFoundYet is returned by the resolvers when BCA has no record of a transfer updated within NotFoundGrace
+var errNotFoundYet = errors.New("outbox: transfer not found yet")
+
+//DefaultNotFoundGrace is how long after its UpdatedAt a transfer BCA has no record of is left as it is, since BCA may
+//still be processing a call cut short by a crash or a timeout
+const DefaultNotFoundGrace = 15 * time.Minute
+
+//Outbox represents the transfers waiting to be sent, kept in Store. An Outbox must be the only one sending
+//the transfers of its Store
+type Outbox struct {
+	Store    ledger.Store
+	Business *business.Client
+	// FIRe sends the FIRe transfers with its own Auth, the Authentication of queued requests is not saved
+	FIRe *fire.Client
+	// NotFoundGrace is how long after its UpdatedAt Resolve waits before failing a transfer BCA has no record of,
+	// DefaultNotFoundGrace when zero
+	NotFoundGrace time.Duration
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+//New is used to initialize new Outbox, a client may be nil when its transfers are not queued
+func New(store ledger.Store, businessClient *business.Client, fireClient *fire.Client) *Outbox {
+	return &Outbox{Store: store, Business: businessClient, FIRe: fireClient}
+}
+
+//EnqueueFundTransfer saves the transfer, whose TransactionID and ReferenceID must be kept stable, to be sent by Submit.
+//ledger.ErrDuplicate is returned when its TransactionID was already queued for its TransactionDate
+func (o *Outbox) EnqueueFundTransfer(ctx context.Context, ptr_fundTransferRequest *bca.FundTransferRequest) (ledger.Entry, error) {
+	if o.Business == nil {
+		return ledger.Entry{}, fmt.Errorf("%w: outbox has no business client", ErrUnsupportedKind)
+	}
+
+	request := *ptr_fundTransferRequest
+	if err := request.Validate(); err != nil {
+		return ledger.Entry{}, err
+	}
+	return o.enqueue(ctx, ledger.KindFundTransfer, ledger.BusinessKey(request.TransactionDate, request.TransactionID), request)
+}
+
+//EnqueueDomesticFundTransfer saves the transfer, whose TransactionID and ReferenceID must be kept stable, to be sent by Submit.
+//ledger.ErrDuplicate is returned when its TransactionID was already queued for its TransactionDate
+func (o *Outbox) EnqueueDomesticFundTransfer(ctx context.Context, ptr_domesticFundTransferRequest *bca.DomesticFundTransferRequest) (ledger.Entry, error) {
+	if o.Business == nil {
+		return ledger.Entry{}, fmt.Errorf("%w: outbox has no business client", ErrUnsupportedKind)
+	}
+
+	request := *ptr_domesticFundTransferRequest
+	if err := request.Validate(); err != nil {
+		return ledger.Entry{}, err
+	}
+	return o.enqueue(ctx, ledger.KindDomesticFundTransfer, ledger.BusinessKey(request.TransactionDate, request.TransactionID), request)
+}
+
+//EnqueueTeleTransferToAccount saves the transfer, whose FormNumber must be kept stable, to be sent by Submit.
+//Cash transfers cannot be queued since their PIN and SecretAnswer are not saved
+func (o *Outbox) EnqueueTeleTransferToAccount(ctx context.Context, ptr_ttAccountRequest *bca.TeleTransferAccountRequest) (ledger.Entry, error) {
+	if o.FIRe == nil {
+		return ledger.Entry{}, fmt.Errorf("%w: outbox has no FIRe client", ErrUnsupportedKind)
+	}
+
+	request := *ptr_ttAccountRequest
+	request.Authentication = o.FIRe.Auth()
+	if err := request.Validate(); err != nil {
+		return ledger.Entry{}, err
+	}
+	request.Authentication = bca.Auth{}
+	return o.enqueue(ctx, ledger.KindTeleTransferToAccount, request.TransactionDetails.FormNumber, request)
+}
+
+func (o *Outbox) enqueue(ctx context.Context, kind ledger.Kind, key string, request interface{}) (ledger.Entry, error) {
+	entry, err := ledger.NewEntry(kind, key, request)
+	if err != nil {
+		return ledger.Entry{}, err
+	}
+	entry.Status = ledger.StatusQueued
+	if err := o.Store.Create(ctx, entry); err != nil {
+		return ledger.Entry{}, err
+	}
+	return entry, nil
+}
+
+//Submit sends a queued transfer. It is marked as sending before the call, so a transfer is never sent twice
+//even when the process stops before its outcome is recorded
+func (o *Outbox) Submit(ctx context.Context, id string) (ledger.Entry, error) {
+	o.mu.Lock()
+	entry, err := o.Store.Get(ctx, id)
+	if err == nil && entry.Status != ledger.StatusQueued {
+		err = fmt.Errorf("%w: %s is %s", ErrNotQueued, id, entry.Status)
+	}
+	if err == nil {
+		entry.Status = ledger.StatusSending
+		entry.UpdatedAt = o.now()
+		err = o.Store.Update(ctx, entry)
+	}
+	o.mu.Unlock()
+	if err != nil {
+		return entry, err
+	}
+
+	callErr := o.send(ctx, entry)
+	if updated, err := o.Store.Get(context.Background(), id); err == nil {
+		entry = updated
+	}
+	return entry, callErr
+}
+
+//send calls BCA for the request of entry and records its outcome
+func (o *Outbox) send(ctx context.Context, entry ledger.Entry) error {
+	switch entry.Kind {
+	case ledger.KindFundTransfer:
+		var request bca.FundTransferRequest
+		if err := json.Unmarshal(entry.Request, &request); err != nil {
+			return ledger.Finish(o.Store, entry, nil, "", false, err)
+		}
+		ptr_response, err := o.Business.FundTransfer(ctx, &request)
+		response := *ptr_response
+		return ledger.Finish(o.Store, entry, response, response.Status, response.Status == string(bca.TransferStatusFailed), err)
+
+	case ledger.KindDomesticFundTransfer:
+		var request bca.DomesticFundTransferRequest
+		if err := json.Unmarshal(entry.Request, &request); err != nil {
+			return ledger.Finish(o.Store, entry, nil, "", false, err)
+		}
+		ptr_response, err := o.Business.DomesticFundTransfer(ctx, &request)
+		response := *ptr_response
+		return ledger.Finish(o.Store, entry, response, response.Status, response.Status == string(bca.TransferStatusFailed), err)
+
+	case ledger.KindTeleTransferToAccount:
+		var request bca.TeleTransferAccountRequest
+		if err := json.Unmarshal(entry.Request, &request); err != nil {
+			return ledger.Finish(o.Store, entry, nil, "", false, err)
+		}
+		ptr_response, err := o.FIRe.TeleTransferToAccount(ctx, &request)
+		response := *ptr_response
+		return ledger.Finish(o.Store, entry, response, response.StatusTransaction, response.StatusTransaction != bca.StatusTransactionSuccess, err)
+	}
+	return ledger.Finish(o.Store, entry, nil, "", false, fmt.Errorf("%w: %s", ErrUnsupportedKind, entry.Kind))
+}
+
+//Flush submits every queued transfer in the order they were queued. The first error is returned once all were tried
+func (o *Outbox) Flush(ctx context.Context) error {
+	entries, err := o.Store.List(ctx, ledger.Filter{Statuses: []ledger.Status{ledger.StatusQueued}})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, entry := range entries {
+		if _, err := o.Submit(ctx, entry.ID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return firstErr
+}
+
+//Resolve inquires BCA for a transfer left sending or in doubt. It succeeds or fails as BCA reports it, and fails
+//when BCA explicitly has no record of it once NotFoundGrace has passed since its UpdatedAt, so that it is never sent
+//again. It is left as it is when BCA has no record of it before then, and in doubt when BCA reports it pending or the
+//inquiry returns an error or an answer that is not understood, that error being returned
+func (o *Outbox) Resolve(ctx context.Context, id string) (ledger.Entry, error) {
+	entry, err := o.Store.Get(ctx, id)
+	if err != nil {
+		return entry, err
+	}
+	if entry.Status != ledger.StatusSending && entry.Status != ledger.StatusInDoubt {
+		return entry, nil
+	}
+
+	var status ledger.Status
+	var bcaStatus string
+	var response interface{}
+	switch entry.Kind {
+	case ledger.KindFundTransfer, ledger.KindDomesticFundTransfer:
+		status, bcaStatus, response, err = o.resolveTransfer(ctx, entry)
+	case ledger.KindTeleTransferToAccount:
+		status, bcaStatus, response, err = o.resolveFIRe(ctx, entry)
+	default:
+		return entry, fmt.Errorf("%w: %s", ErrUnsupportedKind, entry.Kind)
+	}
+	if err == errNotFoundYet {
+		return entry, nil
+	}
+	if err != nil {
+		if entry.Status == ledger.StatusInDoubt {
+			return entry, err
+		}
+		entry.Status = ledger.StatusInDoubt
+		entry.UpdatedAt = o.now()
+		if updateErr := o.Store.Update(ctx, entry); updateErr != nil {
+			return entry, bca.MultiError{err, updateErr}
+		}
+		return entry, err
+	}
+
+	entry.Status = status
+	entry.BCAStatus = bcaStatus
+	if payload, err := json.Marshal(response); err == nil && response != nil {
+		entry.Response = payload
+	}
+	entry.UpdatedAt = o.now()
+	return entry, o.Store.Update(ctx, entry)
+}
+
+func (o *Outbox) resolveTransfer(ctx context.Context, entry ledger.Entry) (ledger.Status, string, interface{}, error) {
+	var request struct {
+		TransactionID   string
+		TransactionDate string
+		TransferType    bca.TransferType
+	}
+	if err := json.Unmarshal(entry.Request, &request); err != nil {
+		return "", "", nil, err
+	}
+	transferType := request.TransferType
+	if entry.Kind == ledger.KindFundTransfer {
+		transferType = bca.TransferTypeBCA
+	}
+	transactionDate, err := time.Parse("2006-01-02", request.TransactionDate)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	ptr_status, err := o.Business.InquiryTransferStatus(ctx, &bca.InquiryTransferStatusRequest{
+		TransactionID:   request.TransactionID,
+		TransactionDate: transactionDate,
+		TransferType:    transferType,
+	})
+	var bcaErr *bca.Error
+	if o.Business.TransferNotFound(err) && errors.As(err, &bcaErr) {
+		if o.notFoundYet(entry) {
+			return "", "", nil, errNotFoundYet
+		}
+		return ledger.StatusFailed, bcaErr.ErrorCode, nil, nil
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	switch (*ptr_status).StatusCode {
+	case bca.TransferStatusSuccess:
+		return ledger.StatusSucceeded, string((*ptr_status).StatusCode), *ptr_status, nil
+	case bca.TransferStatusFailed:
+		return ledger.StatusFailed, string((*ptr_status).StatusCode), *ptr_status, nil
+	}
+	return ledger.StatusInDoubt, string((*ptr_status).StatusCode), *ptr_status, nil
+}
+
+func (o *Outbox) resolveFIRe(ctx context.Context, entry ledger.Entry) (ledger.Status, string, interface{}, error) {
+	var request bca.TeleTransferAccountRequest
+	if err := json.Unmarshal(entry.Request, &request); err != nil {
+		return "", "", nil, err
+	}
+
+	ptr_inquiry, err := o.FIRe.InquiryTransaction(ctx, &bca.InquiryTransactionRequest{
+		TransactionDetails: bca.TransactionInquiryTransactionRequest{
+			InquiryBy:    bca.InquiryByFormNumber,
+			InquiryValue: request.TransactionDetails.FormNumber,
+		},
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	inquiry := *ptr_inquiry
+	switch {
+	case inquiry.StatusTransaction == bca.StatusTransactionSuccess:
+	case o.FIRe.TransactionNotFound(ptr_inquiry):
+		if o.notFoundYet(entry) {
+			return "", "", nil, errNotFoundYet
+		}
+		return ledger.StatusFailed, inquiry.StatusTransaction, nil, nil
+	default:
+		return "", "", nil, fmt.Errorf("outbox: inquiry of FormNumber %s answered %s %s", request.TransactionDetails.FormNumber, inquiry.StatusTransaction, inquiry.StatusMessage)
+	}
+
+	// BCA found the transfer, it only failed when it reports it cancelled
+	if state, _ := fire.CashTransferStatus(inquiry.StatusMessage); state == fire.CashTransferCancelled {
+		return ledger.StatusFailed, inquiry.StatusTransaction, inquiry, nil
+	}
+	return ledger.StatusSucceeded, inquiry.StatusTransaction, inquiry, nil
+}
+
+//notFoundYet reports whether entry was updated within NotFoundGrace
+func (o *Outbox) notFoundYet(entry ledger.Entry) bool {
+	grace := o.NotFoundGrace
+	if grace == 0 {
+		grace = DefaultNotFoundGrace
+	}
+	return o.now().Before(entry.UpdatedAt.Add(grace))
+}
+
+func (o *Outbox) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
+}
+
+//Recover resolves every transfer left sending or in doubt, such as after a restart, then submits the queued ones
+//unless ctx is done. The first error is returned once all were tried
+func (o *Outbox) Recover(ctx context.Context) error {
+	entries, err := o.Store.List(ctx, ledger.Filter{Statuses: []ledger.Status{ledger.StatusSending, ledger.StatusInDoubt}})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, entry := range entries {
+		// Cash transfers recorded by the ledger clients in the same Store are left to fire.CashTransferTracker
+		if entry.Kind != ledger.KindFundTransfer && entry.Kind != ledger.KindDomesticFundTransfer && entry.Kind != ledger.KindTeleTransferToAccount {
+			continue
+		}
+		if _, err := o.Resolve(ctx, entry.ID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	if err := o.Flush(ctx); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
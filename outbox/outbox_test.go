@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+	"github.com/ianeinser/bca-api-go/business"
+	"github.com/ianeinser/bca-api-go/fire"
+	"github.com/ianeinser/bca-api-go/ledger"
+)
+
+func TestRecover(t *testing.T) {
+	notFound := *bca.ErrTransactionNotFound
+	notFound.HTTPStatus = http.StatusNotFound
+
+	fundTransfer := bca.FundTransferRequest{TransactionID: "00000001", TransactionDate: "2026-10-18"}
+	toAccount := bca.TeleTransferAccountRequest{TransactionDetails: bca.TransactionAccountRequest{FormNumber: "FORM1"}}
+
+	tests := []struct {
+		name string
+		kind ledger.Kind
+		from ledger.Status
+		// age is the time since the UpdatedAt of the entry
+		age      time.Duration
+		path     string
+		response bcatest.Response
+		want     ledger.Status
+		wantErr  bool
+	}{
+		{
+			name:     "transfer succeeded",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusSending,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Body: bca.InquiryTransferStatusResponse{StatusCode: bca.TransferStatusSuccess}},
+			want:     ledger.StatusSucceeded,
+		},
+		{
+			name:     "transfer failed",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusInDoubt,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Body: bca.InquiryTransferStatusResponse{StatusCode: bca.TransferStatusFailed}},
+			want:     ledger.StatusFailed,
+		},
+		{
+			name:     "transfer pending",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusSending,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Body: bca.InquiryTransferStatusResponse{StatusCode: bca.TransferStatusPending}},
+			want:     ledger.StatusInDoubt,
+		},
+		{
+			name:     "transfer not found",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusSending,
+			age:      DefaultNotFoundGrace,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Err: &notFound},
+			want:     ledger.StatusFailed,
+		},
+		{
+			name:     "transfer not found yet",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusSending,
+			age:      DefaultNotFoundGrace - time.Second,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Err: &notFound},
+			want:     ledger.StatusSending,
+		},
+		{
+			name:     "transfer unauthorized",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusSending,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusUnauthorized, ErrorCode: bca.ErrExpiredToken.ErrorCode}},
+			want:     ledger.StatusInDoubt,
+			wantErr:  true,
+		},
+		{
+			name:     "transfer too many requests",
+			kind:     ledger.KindFundTransfer,
+			from:     ledger.StatusInDoubt,
+			path:     "/banking/corporates/transfers/status/00000001",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusTooManyRequests}},
+			want:     ledger.StatusInDoubt,
+			wantErr:  true,
+		},
+		{
+			name:     "FIRe succeeded",
+			kind:     ledger.KindTeleTransferToAccount,
+			from:     ledger.StatusSending,
+			path:     "/fire/transactions",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionSuccess}},
+			want:     ledger.StatusSucceeded,
+		},
+		{
+			name:     "FIRe cancelled",
+			kind:     ledger.KindTeleTransferToAccount,
+			from:     ledger.StatusSending,
+			path:     "/fire/transactions",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionSuccess, StatusMessage: "Cancelled"}},
+			want:     ledger.StatusFailed,
+		},
+		{
+			name:     "FIRe not found",
+			kind:     ledger.KindTeleTransferToAccount,
+			from:     ledger.StatusInDoubt,
+			age:      time.Hour,
+			path:     "/fire/transactions",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionNotFound}},
+			want:     ledger.StatusFailed,
+		},
+		{
+			name:     "FIRe not found yet",
+			kind:     ledger.KindTeleTransferToAccount,
+			from:     ledger.StatusInDoubt,
+			path:     "/fire/transactions",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: bca.StatusTransactionNotFound}},
+			want:     ledger.StatusInDoubt,
+		},
+		{
+			name:     "FIRe not understood",
+			kind:     ledger.KindTeleTransferToAccount,
+			from:     ledger.StatusSending,
+			path:     "/fire/transactions",
+			response: bcatest.Response{Body: bca.InquiryTransactionResponse{StatusTransaction: "0003", StatusMessage: "Invalid authentication"}},
+			want:     ledger.StatusInDoubt,
+			wantErr:  true,
+		},
+		{
+			name:     "FIRe server error",
+			kind:     ledger.KindTeleTransferToAccount,
+			from:     ledger.StatusSending,
+			path:     "/fire/transactions",
+			response: bcatest.Response{Err: &bca.Error{HTTPStatus: http.StatusBadGateway}},
+			want:     ledger.StatusInDoubt,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			method := "GET"
+			if tt.kind == ledger.KindTeleTransferToAccount {
+				method = "POST"
+			}
+			recorder.Respond(method, tt.path, tt.response)
+
+			fireClient := fire.Client{
+				Client:      recorder,
+				CorporateID: "IBSTT01",
+				AccessCode:  "q1w2e3r4",
+				BranchCode:  "IBSTT0101",
+				UserID:      "IBSTT0101",
+				LocalID:     "40115",
+			}
+			o := New(ledger.NewMemoryStore(), &business.Client{Client: recorder}, &fireClient)
+			now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+			o.Now = func() time.Time { return now }
+
+			var request interface{} = fundTransfer
+			key := ledger.BusinessKey(fundTransfer.TransactionDate, fundTransfer.TransactionID)
+			if tt.kind == ledger.KindTeleTransferToAccount {
+				request, key = toAccount, toAccount.TransactionDetails.FormNumber
+			}
+			entry, err := ledger.NewEntry(tt.kind, key, request)
+			if err != nil {
+				t.Fatalf("NewEntry() = %v", err)
+			}
+			entry.Status = tt.from
+			entry.UpdatedAt = now.Add(-tt.age)
+			if err := o.Store.Create(context.Background(), entry); err != nil {
+				t.Fatalf("Create() = %v", err)
+			}
+
+			if err := o.Recover(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Recover() = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, _ := o.Store.Get(context.Background(), entry.ID)
+			if got.Status != tt.want {
+				t.Errorf("Status = %s, want %s", got.Status, tt.want)
+			}
+			// A transfer being resolved is never sent again
+			if calls := len(recorder.Calls()); calls != 1 {
+				t.Errorf("called BCA %d times, want 1", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+//Statuses of the rows of an exported Result
+const (
+	StatusMatched           = "MATCHED"
+	StatusUnmatchedExpected = "UNMATCHED_EXPECTED"
+	StatusUnmatchedBank     = "UNMATCHED_BANK"
+	StatusAmbiguous         = "AMBIGUOUS"
+)
+
+var csvHeader = []string{
+	"Status", "Group",
+	"ExpectedID", "ExpectedAmount", "ExpectedReference",
+	"BankSource", "BankID", "BankDate", "BankType", "BankAmount", "BankName", "BankReference",
+	"Difference",
+}
+
+//WriteJSON writes the result as an indented JSON document
+func (r Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+//WriteCSV writes the result as CSV with a header row. A match is one row with both sides, an unmatched entry one row
+//with its side only, and every expected payment and bank entry of an ambiguous group one row sharing the group number
+func (r Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, match := range r.Matched {
+		exp, bank := match.Expected, match.Bank
+		if err := cw.Write(csvRow(StatusMatched, "", &exp, &bank, match.Difference.String())); err != nil {
+			return err
+		}
+	}
+	for _, exp := range r.UnmatchedExpected {
+		exp := exp
+		if err := cw.Write(csvRow(StatusUnmatchedExpected, "", &exp, nil, "")); err != nil {
+			return err
+		}
+	}
+	for _, bank := range r.UnmatchedBank {
+		bank := bank
+		if err := cw.Write(csvRow(StatusUnmatchedBank, "", nil, &bank, "")); err != nil {
+			return err
+		}
+	}
+	for n, group := range r.Ambiguous {
+		groupNumber := strconv.Itoa(n + 1)
+		for _, exp := range group.Expected {
+			exp := exp
+			if err := cw.Write(csvRow(StatusAmbiguous, groupNumber, &exp, nil, "")); err != nil {
+				return err
+			}
+		}
+		for _, bank := range group.Bank {
+			bank := bank
+			if err := cw.Write(csvRow(StatusAmbiguous, groupNumber, nil, &bank, "")); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvRow(status, group string, ptr_expected *Expected, ptr_bank *BankEntry, difference string) []string {
+	row := make([]string, 0, len(csvHeader))
+	row = append(row, status, group)
+
+	if ptr_expected != nil {
+		row = append(row, (*ptr_expected).ID, (*ptr_expected).Amount.String(), (*ptr_expected).Reference)
+	} else {
+		row = append(row, "", "", "")
+	}
+
+	if ptr_bank != nil {
+		date := ""
+		if !(*ptr_bank).Date.IsZero() {
+			date = (*ptr_bank).Date.Format(time.RFC3339)
+		}
+		row = append(row, (*ptr_bank).Source, (*ptr_bank).ID, date, string((*ptr_bank).Type),
+			(*ptr_bank).Amount.String(), (*ptr_bank).Name, (*ptr_bank).Reference)
+	} else {
+		row = append(row, "", "", "", "", "", "", "")
+	}
+
+	return append(row, difference)
+}
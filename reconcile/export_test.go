@@ -0,0 +1,63 @@
+package reconcile
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+func exportResult() Result {
+	invoice := Expected{ID: "INV1", Amount: bca.MustParseAmount("150000"), Reference: "INV1"}
+	entry := BankEntry{Source: SourceVA, ID: "REQ1", Date: time.Date(2026, 10, 16, 13, 45, 0, 0, time.UTC), Type: bca.TransactionTypeCredit, Amount: bca.MustParseAmount("149000"), Name: "Budi, Santoso", Reference: "INV1"}
+	return Result{
+		Matched:           []Match{{Expected: invoice, Bank: entry, Difference: bca.MustParseAmount("-1000")}},
+		UnmatchedExpected: []Expected{{ID: "INV2", Amount: bca.MustParseAmount("50000")}},
+		UnmatchedBank:     []BankEntry{{Source: SourceStatement, ID: "3", Type: bca.TransactionTypeCredit, Amount: bca.MustParseAmount("75000")}},
+		Ambiguous: []Ambiguous{{
+			Expected: []Expected{{ID: "INV3", Amount: bca.MustParseAmount("20000")}},
+			Bank:     []BankEntry{{Source: SourceStatement, ID: "4", Amount: bca.MustParseAmount("20000")}, {Source: SourceStatement, ID: "5", Amount: bca.MustParseAmount("20000")}},
+		}},
+	}
+}
+
+func TestResultWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportResult().WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() = %v", err)
+	}
+
+	want := "Status,Group,ExpectedID,ExpectedAmount,ExpectedReference,BankSource,BankID,BankDate,BankType,BankAmount,BankName,BankReference,Difference\n" +
+		"MATCHED,,INV1,150000,INV1,VA,REQ1,2026-10-16T13:45:00Z,C,149000,\"Budi, Santoso\",INV1,-1000\n" +
+		"UNMATCHED_EXPECTED,,INV2,50000,,,,,,,,,\n" +
+		"UNMATCHED_BANK,,,,,STATEMENT,3,,C,75000,,,\n" +
+		"AMBIGUOUS,1,INV3,20000,,,,,,,,,\n" +
+		"AMBIGUOUS,1,,,,STATEMENT,4,,,20000,,,\n" +
+		"AMBIGUOUS,1,,,,STATEMENT,5,,,20000,,,\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestResultWriteJSON(t *testing.T) {
+	result := exportResult()
+
+	var buf bytes.Buffer
+	if err := result.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() = %v", err)
+	}
+
+	var got Result
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got.Matched) != 1 || len(got.UnmatchedExpected) != 1 || len(got.UnmatchedBank) != 1 || len(got.Ambiguous) != 1 || len(got.Ambiguous[0].Bank) != 2 {
+		t.Fatalf("WriteJSON() = %s, want every row of the result", buf.String())
+	}
+	match := got.Matched[0]
+	if match.Expected.ID != "INV1" || match.Bank.ID != "REQ1" || !match.Bank.Date.Equal(result.Matched[0].Bank.Date) || !match.Difference.Equal(bca.MustParseAmount("-1000")) {
+		t.Errorf("Matched = %+v, want %+v", match, result.Matched[0])
+	}
+}
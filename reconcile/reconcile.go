@@ -0,0 +1,307 @@
+//Package reconcile matches the transactions reported by BCA, from account statements or Virtual Account
+//payment statuses, against the payments a business expects such as its invoices.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//VATransactionDateLayout is the layout of the TransactionDate of Virtual Account payment statuses
+const VATransactionDateLayout = "02/01/2006 15:04:05"
+
+//Sources of bank entries
+const (
+	SourceStatement = "STATEMENT"
+	SourceVA        = "VA"
+)
+
+//Expected represents a payment expected on the account, such as an invoice
+type Expected struct {
+	ID     string
+	Amount bca.Amount
+	// Reference is looked for in the reference of bank entries, an empty Reference matches on amount and date only
+	Reference string
+	// From and To bound the date of the payment, a zero time leaves that side open
+	From time.Time
+	To   time.Time
+	// Type restricts the direction of the payment, empty matches both
+	Type bca.TransactionType
+}
+
+//BankEntry represents a transaction reported by BCA
+type BankEntry struct {
+	Source string
+	// ID identifies the entry within its source, such as the RequestID of a VA payment
+	ID string
+	// Date is zero when it is not known, such as for pending statement entries
+	Date   time.Time
+	Type   bca.TransactionType
+	Amount bca.Amount
+	Name   string
+	// Reference is the free text searched for the reference of expected payments
+	Reference string
+}
+
+//Rules represents how expected payments are matched to bank entries
+type Rules struct {
+	// AmountTolerance is the largest accepted difference between the expected and the bank amount
+	AmountTolerance bca.Amount
+	// DateSlack widens the date window of expected payments on both sides
+	DateSlack time.Duration
+	// RequireReference rejects expected payments without a Reference instead of matching them on amount and date only
+	RequireReference bool
+	// MatchReference reports whether a bank reference contains the expected reference. When nil, both are compared
+	// without case, spaces and punctuation
+	MatchReference func(expected, bank string) bool
+}
+
+//Match represents an expected payment matched to a single bank entry
+type Match struct {
+	Expected Expected
+	Bank     BankEntry
+	// Difference is the bank amount minus the expected amount
+	Difference bca.Amount
+}
+
+//Ambiguous represents expected payments and bank entries that match each other in more than one way
+type Ambiguous struct {
+	Expected []Expected
+	Bank     []BankEntry
+}
+
+//Result represents the outcome of Reconcile
+type Result struct {
+	Matched           []Match
+	UnmatchedExpected []Expected
+	UnmatchedBank     []BankEntry
+	Ambiguous         []Ambiguous
+}
+
+//FromStatement converts the entries of a typed account statement. The ID of an entry is its position in the statement,
+//from 1, and its reference is its name and trailer
+func FromStatement(entries []bca.StatementEntry) []BankEntry {
+	bank := make([]BankEntry, 0, len(entries))
+	for n, entry := range entries {
+		bank = append(bank, BankEntry{
+			Source:    SourceStatement,
+			ID:        strconv.Itoa(n + 1),
+			Date:      entry.Date,
+			Type:      entry.Type,
+			Amount:    entry.Amount,
+			Name:      entry.Name,
+			Reference: strings.TrimSpace(entry.Name + " " + entry.Trailer),
+		})
+	}
+	return bank
+}
+
+//FromAccountStatement converts the rows of an AccountStatementResponse of the period from start to end
+func FromAccountStatement(ptr_accountStatementResponse *bca.AccountStatementResponse, start, end time.Time) ([]BankEntry, error) {
+	entries := make([]bca.StatementEntry, 0, len((*ptr_accountStatementResponse).Data))
+	for _, row := range (*ptr_accountStatementResponse).Data {
+		entry, err := bca.NewStatementEntry(row, start, end)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return FromStatement(entries), nil
+}
+
+//FromVAPayments converts the successful payments of VA payment statuses into credits of their paid amount.
+//Their date is read in location, time.Local when nil, and their reference is the payment reference, customer number and request ID.
+//A payment whose TransactionDate is not in VATransactionDateLayout is an error, as its date could not be matched
+func FromVAPayments(payments []bca.VAInquiryStatusPaymentResponse, location *time.Location) ([]BankEntry, error) {
+	if location == nil {
+		location = time.Local
+	}
+
+	var bank []BankEntry
+	for _, payment := range payments {
+		if payment.PaymentFlagStatus != bca.PaymentFlagStatusSuccess {
+			continue
+		}
+
+		date, err := time.ParseInLocation(VATransactionDateLayout, payment.TransactionDate, location)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile: VA payment %s: %w", payment.RequestID, err)
+		}
+		bank = append(bank, BankEntry{
+			Source:    SourceVA,
+			ID:        payment.RequestID,
+			Date:      date,
+			Type:      bca.TransactionTypeCredit,
+			Amount:    payment.PaidAmount,
+			Name:      payment.CustomerName,
+			Reference: strings.Join([]string{payment.Reference, payment.CompanyCode + payment.CustomerNumber, payment.RequestID}, " "),
+		})
+	}
+	return bank, nil
+}
+
+//Reconcile matches expected payments to bank entries. A pair is matched when each is the only candidate of the other,
+//candidates with the exact amount being preferred over those within tolerance. Expected payments and bank entries
+//competing for each other are reported as Ambiguous, in groups connected by their candidates
+func Reconcile(expected []Expected, bank []BankEntry, rules Rules) Result {
+	matchReference := rules.MatchReference
+	if matchReference == nil {
+		matchReference = containsReference
+	}
+
+	// candidates[i] are the indexes of the bank entries expected[i] may be matched to
+	candidates := make([]map[int]bool, len(expected))
+	for i, exp := range expected {
+		candidates[i] = map[int]bool{}
+		if exp.Reference == "" && rules.RequireReference {
+			continue
+		}
+
+		exact := false
+		for j, entry := range bank {
+			if !rules.accepts(exp, entry, matchReference) {
+				continue
+			}
+			if entry.Amount.Equal(exp.Amount) && !exact {
+				exact = true
+				candidates[i] = map[int]bool{}
+			}
+			if exact && !entry.Amount.Equal(exp.Amount) {
+				continue
+			}
+			candidates[i][j] = true
+		}
+	}
+
+	// claimedBy[j] are the indexes of the expected payments that may be matched to bank[j]
+	claimedBy := make([]map[int]bool, len(bank))
+	for j := range bank {
+		claimedBy[j] = map[int]bool{}
+	}
+	for i := range expected {
+		for j := range candidates[i] {
+			claimedBy[j][i] = true
+		}
+	}
+
+	var result Result
+	matched := make([]bool, len(expected))
+	taken := make([]bool, len(bank))
+	for i := range expected {
+		if len(candidates[i]) != 1 {
+			continue
+		}
+		j := only(candidates[i])
+		if len(claimedBy[j]) != 1 {
+			continue
+		}
+
+		result.Matched = append(result.Matched, Match{
+			Expected:   expected[i],
+			Bank:       bank[j],
+			Difference: bank[j].Amount.Sub(expected[i].Amount),
+		})
+		matched[i] = true
+		taken[j] = true
+	}
+
+	// The remaining candidates are grouped by connected components
+	visitedExpected := make([]bool, len(expected))
+	visitedBank := make([]bool, len(bank))
+	for i := range expected {
+		if matched[i] || visitedExpected[i] {
+			continue
+		}
+		if len(candidates[i]) == 0 {
+			result.UnmatchedExpected = append(result.UnmatchedExpected, expected[i])
+			continue
+		}
+
+		var group Ambiguous
+		var expectedIndexes, bankIndexes []int
+		queue := []int{i}
+		visitedExpected[i] = true
+		for len(queue) > 0 {
+			e := queue[0]
+			queue = queue[1:]
+			expectedIndexes = append(expectedIndexes, e)
+			for j := range candidates[e] {
+				if visitedBank[j] {
+					continue
+				}
+				visitedBank[j] = true
+				bankIndexes = append(bankIndexes, j)
+				for k := range claimedBy[j] {
+					if !visitedExpected[k] && !matched[k] {
+						visitedExpected[k] = true
+						queue = append(queue, k)
+					}
+				}
+			}
+		}
+
+		sort.Ints(expectedIndexes)
+		sort.Ints(bankIndexes)
+		for _, e := range expectedIndexes {
+			group.Expected = append(group.Expected, expected[e])
+		}
+		for _, j := range bankIndexes {
+			group.Bank = append(group.Bank, bank[j])
+		}
+		result.Ambiguous = append(result.Ambiguous, group)
+	}
+
+	for j := range bank {
+		if !taken[j] && !visitedBank[j] {
+			result.UnmatchedBank = append(result.UnmatchedBank, bank[j])
+		}
+	}
+	return result
+}
+
+//accepts reports whether entry may be the payment of exp
+func (rules Rules) accepts(exp Expected, entry BankEntry, matchReference func(expected, bank string) bool) bool {
+	if exp.Type != "" && exp.Type != entry.Type {
+		return false
+	}
+	if entry.Amount.Sub(exp.Amount).Abs().Cmp(rules.AmountTolerance.Abs()) > 0 {
+		return false
+	}
+	if !entry.Date.IsZero() {
+		if !exp.From.IsZero() && entry.Date.Before(exp.From.Add(-rules.DateSlack)) {
+			return false
+		}
+		if !exp.To.IsZero() && entry.Date.After(exp.To.Add(rules.DateSlack)) {
+			return false
+		}
+	}
+	return exp.Reference == "" || matchReference(exp.Reference, entry.Reference)
+}
+
+//containsReference reports whether bank contains expected, ignoring case, spaces and punctuation
+func containsReference(expected, bank string) bool {
+	expected = normalizeReference(expected)
+	return expected != "" && strings.Contains(normalizeReference(bank), expected)
+}
+
+func normalizeReference(s string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+func only(set map[int]bool) int {
+	for k := range set {
+		return k
+	}
+	return -1
+}
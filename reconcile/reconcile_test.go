@@ -0,0 +1,183 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+//ids returns the IDs of expected payments and bank entries, "expected ID=bank ID" for matches
+func ids(result Result) (matched, unmatchedExpected, unmatchedBank, ambiguous string) {
+	var m, ue, ub, a []string
+	for _, match := range result.Matched {
+		m = append(m, match.Expected.ID+"="+match.Bank.ID)
+	}
+	for _, exp := range result.UnmatchedExpected {
+		ue = append(ue, exp.ID)
+	}
+	for _, bank := range result.UnmatchedBank {
+		ub = append(ub, bank.ID)
+	}
+	for _, group := range result.Ambiguous {
+		var g []string
+		for _, exp := range group.Expected {
+			g = append(g, exp.ID)
+		}
+		for _, bank := range group.Bank {
+			g = append(g, bank.ID)
+		}
+		a = append(a, strings.Join(g, ","))
+	}
+	return strings.Join(m, " "), strings.Join(ue, " "), strings.Join(ub, " "), strings.Join(a, " ")
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name                  string
+		expected              []Expected
+		bank                  []BankEntry
+		rules                 Rules
+		wantMatched           string
+		wantUnmatchedExpected string
+		wantUnmatchedBank     string
+		wantAmbiguous         string
+	}{
+		{
+			name:        "exact amount and reference",
+			expected:    []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000"), Reference: "inv-1"}},
+			bank:        []BankEntry{{ID: "1", Amount: bca.MustParseAmount("150000"), Reference: "TRF INV1 PT SUMBER"}},
+			wantMatched: "INV1=1",
+		},
+		{
+			name:     "exact amount preferred over tolerance",
+			expected: []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000")}},
+			bank: []BankEntry{
+				{ID: "1", Amount: bca.MustParseAmount("149000")},
+				{ID: "2", Amount: bca.MustParseAmount("150000")},
+			},
+			rules:             Rules{AmountTolerance: bca.MustParseAmount("2000")},
+			wantMatched:       "INV1=2",
+			wantUnmatchedBank: "1",
+		},
+		{
+			name:        "within tolerance",
+			expected:    []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000")}},
+			bank:        []BankEntry{{ID: "1", Amount: bca.MustParseAmount("149000")}},
+			rules:       Rules{AmountTolerance: bca.MustParseAmount("2000")},
+			wantMatched: "INV1=1",
+		},
+		{
+			name:     "same amount without reference",
+			expected: []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000")}, {ID: "INV2", Amount: bca.MustParseAmount("150000")}},
+			bank: []BankEntry{
+				{ID: "1", Amount: bca.MustParseAmount("150000")},
+				{ID: "2", Amount: bca.MustParseAmount("150000")},
+				{ID: "3", Amount: bca.MustParseAmount("99000")},
+			},
+			wantUnmatchedBank: "3",
+			wantAmbiguous:     "INV1,INV2,1,2",
+		},
+		{
+			name:     "references tell them apart",
+			expected: []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000"), Reference: "INV1"}, {ID: "INV2", Amount: bca.MustParseAmount("150000"), Reference: "INV2"}},
+			bank: []BankEntry{
+				{ID: "1", Amount: bca.MustParseAmount("150000"), Reference: "INV2"},
+				{ID: "2", Amount: bca.MustParseAmount("150000"), Reference: "INV1"},
+			},
+			wantMatched: "INV1=2 INV2=1",
+		},
+		{
+			name:     "date window with slack",
+			expected: []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000"), From: day(10), To: day(12)}},
+			bank: []BankEntry{
+				{ID: "1", Date: day(8), Amount: bca.MustParseAmount("150000")},
+				{ID: "2", Date: day(13), Amount: bca.MustParseAmount("150000")},
+			},
+			rules:             Rules{DateSlack: 24 * time.Hour},
+			wantMatched:       "INV1=2",
+			wantUnmatchedBank: "1",
+		},
+		{
+			name:                  "direction",
+			expected:              []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000"), Type: bca.TransactionTypeCredit}},
+			bank:                  []BankEntry{{ID: "1", Type: bca.TransactionTypeDebit, Amount: bca.MustParseAmount("150000")}},
+			wantUnmatchedExpected: "INV1",
+			wantUnmatchedBank:     "1",
+		},
+		{
+			name:                  "reference required",
+			expected:              []Expected{{ID: "INV1", Amount: bca.MustParseAmount("150000")}},
+			bank:                  []BankEntry{{ID: "1", Amount: bca.MustParseAmount("150000")}},
+			rules:                 Rules{RequireReference: true},
+			wantUnmatchedExpected: "INV1",
+			wantUnmatchedBank:     "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, unmatchedExpected, unmatchedBank, ambiguous := ids(Reconcile(tt.expected, tt.bank, tt.rules))
+			if matched != tt.wantMatched {
+				t.Errorf("Matched = %q, want %q", matched, tt.wantMatched)
+			}
+			if unmatchedExpected != tt.wantUnmatchedExpected {
+				t.Errorf("UnmatchedExpected = %q, want %q", unmatchedExpected, tt.wantUnmatchedExpected)
+			}
+			if unmatchedBank != tt.wantUnmatchedBank {
+				t.Errorf("UnmatchedBank = %q, want %q", unmatchedBank, tt.wantUnmatchedBank)
+			}
+			if ambiguous != tt.wantAmbiguous {
+				t.Errorf("Ambiguous = %q, want %q", ambiguous, tt.wantAmbiguous)
+			}
+		})
+	}
+}
+
+func TestFromVAPayments(t *testing.T) {
+	location := time.FixedZone("WIB", 7*60*60)
+	paid := bca.VAInquiryStatusPaymentResponse{
+		CompanyCode:       "12345",
+		CustomerNumber:    "0001",
+		CustomerName:      "Budi Santoso",
+		RequestID:         "REQ1",
+		TransactionDate:   "16/10/2026 13:45:00",
+		PaymentFlagStatus: bca.PaymentFlagStatusSuccess,
+		Reference:         "INV1",
+		PaidAmount:        bca.MustParseAmount("150000"),
+	}
+	failed := paid
+	failed.RequestID, failed.PaymentFlagStatus = "REQ2", bca.PaymentFlagStatusFailed
+
+	bank, err := FromVAPayments([]bca.VAInquiryStatusPaymentResponse{paid, failed}, location)
+	if err != nil {
+		t.Fatalf("FromVAPayments() = %v", err)
+	}
+	if len(bank) != 1 {
+		t.Fatalf("got %d entries, want the successful payment only", len(bank))
+	}
+	want := BankEntry{
+		Source:    SourceVA,
+		ID:        "REQ1",
+		Date:      time.Date(2026, 10, 16, 13, 45, 0, 0, location),
+		Type:      bca.TransactionTypeCredit,
+		Amount:    bca.MustParseAmount("150000"),
+		Name:      "Budi Santoso",
+		Reference: "INV1 123450001 REQ1",
+	}
+	if got := bank[0]; got.Source != want.Source || got.ID != want.ID || !got.Date.Equal(want.Date) || got.Type != want.Type ||
+		!got.Amount.Equal(want.Amount) || got.Name != want.Name || got.Reference != want.Reference {
+		t.Errorf("entry = %+v, want %+v", got, want)
+	}
+
+	malformed := paid
+	malformed.TransactionDate = "2026-10-16T13:45:00"
+	if _, err := FromVAPayments([]bca.VAInquiryStatusPaymentResponse{paid, malformed}, location); err == nil || !strings.Contains(err.Error(), "REQ1") {
+		t.Errorf("FromVAPayments() with a malformed date = %v, want an error of REQ1", err)
+	}
+}
@@ -0,0 +1,127 @@
+package bca
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+//RetryPolicy represents how failed BCA calls are retried. Timeouts, 5xx and 429 responses are retried
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is the fraction of the backoff that is randomized, between 0 and 1
+	Jitter float64
+}
+
+//OutcomeResolver is used before retrying a call that moves money. It checks with BCA whether the failed
+//attempt was applied anyway, and fills v with the outcome when it was. It returns an error when BCA does not
+//explicitly tell, or tells that the transfer failed, which is returned along with the error of the failed attempt.
+type OutcomeResolver func(ctx context.Context, v interface{}) (applied bool, err error)
+
+//NewRetryPolicy is used to initialize new RetryPolicy with sensible defaults
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+//Backoff returns how long to wait after the given failed attempt, starting from 1
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= multiplier
+		if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+			backoff = float64(p.MaxBackoff)
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+//Retryable reports whether err is worth another attempt: a timeout, a 5xx or a 429 response
+func (p *RetryPolicy) Retryable(err error) bool {
+	var bcaErr *Error
+	if errors.As(err, &bcaErr) {
+		return bcaErr.HTTPStatus >= 500 || bcaErr.HTTPStatus == 429
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+//retry invokes call according to c.RetryPolicy. Calls that are not idempotent are only sent again
+//when resolve confirms that the previous attempt was not applied by BCA.
+func (c *APIImplementation) retry(ctx context.Context, idempotent bool, resolve OutcomeResolver, v interface{}, call func() error) error {
+	policy := c.RetryPolicy
+	maxAttempts := policy.maxAttempts()
+	if !idempotent && resolve == nil {
+		maxAttempts = 1
+	}
+
+	// v must not keep the error fields of a failed attempt once the call succeeds
+	response, hasError := v.(errorResponse)
+
+	for attempt := 1; ; attempt++ {
+		if hasError {
+			response.resetError()
+		}
+		err := call()
+		if err == nil || attempt >= maxAttempts || ctx.Err() != nil || !policy.Retryable(err) {
+			return err
+		}
+
+		backoff := policy.Backoff(attempt)
+		if c.LogLevel > 1 {
+			c.Logger.Printf("Attempt %d failed, retrying in %v: %v\n", attempt, backoff, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		if !idempotent {
+			applied, resolveErr := resolve(ctx, v)
+			if resolveErr != nil {
+				if c.LogLevel > 0 {
+					c.Logger.Println("Cannot resolve outcome of failed attempt: ", resolveErr)
+				}
+				return MultiError{err, resolveErr}
+			}
+			if applied {
+				if hasError {
+					response.resetError()
+				}
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package bca
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	policy := NewRetryPolicy(3)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "server error", err: &Error{HTTPStatus: http.StatusInternalServerError}, want: true},
+		{name: "bad gateway", err: &Error{HTTPStatus: http.StatusBadGateway}, want: true},
+		{name: "too many requests", err: &Error{HTTPStatus: http.StatusTooManyRequests}, want: true},
+		{name: "bad request", err: &Error{HTTPStatus: http.StatusBadRequest}},
+		{name: "unauthorized", err: &Error{HTTPStatus: http.StatusUnauthorized}},
+		{name: "timeout", err: &net.DNSError{IsTimeout: true}, want: true},
+		{name: "connection refused", err: &net.DNSError{}},
+		{name: "other", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Retryable(tt.err); got != tt.want {
+			t.Errorf("%s: Retryable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 5 * time.Second},
+		{attempt: 10, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Backoff(1); got < time.Second/2 || got > 3*time.Second/2 {
+			t.Fatalf("Backoff(1) with jitter = %v, want within 50%% of 1s", got)
+		}
+	}
+}
+
+//statusServer answers the calls with statuses in order, the last one being repeated
+type statusServer struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    int
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	status := s.statuses[len(s.statuses)-1]
+	if s.calls < len(s.statuses) {
+		status = s.statuses[s.calls]
+	}
+	s.calls++
+	s.mu.Unlock()
+
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		w.Write([]byte(`{"TransactionID":"00000001","Status":"Success"}`))
+		return
+	}
+	w.Write([]byte(`{"ErrorCode":"ESB-99-999","ErrorMessage":{"Indonesian":"Sistem sedang tidak tersedia","English":"System unavailable"}}`))
+}
+
+func testAPI(t *testing.T, server *statusServer, maxAttempts int) APIImplementation {
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	policy := NewRetryPolicy(maxAttempts)
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = time.Millisecond
+	return NewAPI(Config{URL: ts.URL, RetryPolicy: policy})
+}
+
+func TestCallContextRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success", statuses: []int{http.StatusOK}, wantCalls: 1},
+		{name: "retried until success", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, wantCalls: 3},
+		{name: "attempts exhausted", statuses: []int{http.StatusBadGateway}, wantCalls: 3, wantErr: true},
+		{name: "rejection is not retried", statuses: []int{http.StatusBadRequest}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &statusServer{statuses: tt.statuses}
+			api := testAPI(t, server, 3)
+
+			var response FundTransferResponse
+			if err := api.CallContext(context.Background(), "GET", "/banking/corporates/transfers", "token", nil, nil, &response); (err != nil) != tt.wantErr {
+				t.Errorf("CallContext() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if server.calls != tt.wantCalls {
+				t.Errorf("sent %d times, want %d", server.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCallNonIdempotentContextResolves(t *testing.T) {
+	errInquiry := errors.New("inquiry failed")
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		resolve    OutcomeResolver
+		wantCalls  int
+		wantErr    error
+		wantStatus string
+	}{
+		{
+			name:      "not resolved is never sent again",
+			statuses:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantCalls: 1,
+			wantErr:   &Error{ErrorCode: "ESB-99-999"},
+		},
+		{
+			name:     "not applied is sent again",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			resolve: func(ctx context.Context, v interface{}) (bool, error) {
+				return false, nil
+			},
+			wantCalls:  2,
+			wantStatus: "Success",
+		},
+		{
+			name:     "applied is not sent again",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			resolve: func(ctx context.Context, v interface{}) (bool, error) {
+				(*v.(*FundTransferResponse)).Status = "Resolved"
+				return true, nil
+			},
+			wantCalls:  1,
+			wantStatus: "Resolved",
+		},
+		{
+			name:     "inquiry error is returned with the failed attempt",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			resolve: func(ctx context.Context, v interface{}) (bool, error) {
+				return false, errInquiry
+			},
+			wantCalls: 1,
+			wantErr:   errInquiry,
+		},
+		{
+			name:     "rejection is not resolved",
+			statuses: []int{http.StatusBadRequest},
+			resolve: func(ctx context.Context, v interface{}) (bool, error) {
+				return true, nil
+			},
+			wantCalls: 1,
+			wantErr:   &Error{ErrorCode: "ESB-99-999"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &statusServer{statuses: tt.statuses}
+			api := testAPI(t, server, 3)
+
+			var response FundTransferResponse
+			err := api.CallNonIdempotentContext(context.Background(), "POST", "/banking/corporates/transfers", "token", nil, []byte(`{}`), &response, tt.resolve)
+			if tt.wantErr == nil && err != nil || !errors.Is(err, tt.wantErr) {
+				t.Errorf("CallNonIdempotentContext() = %v, want %v", err, tt.wantErr)
+			}
+			if server.calls != tt.wantCalls {
+				t.Errorf("sent %d times, want %d", server.calls, tt.wantCalls)
+			}
+			if response.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", response.Status, tt.wantStatus)
+			}
+			// A resolved call returns the outcome alone, without the error of the failed attempt
+			if err == nil && response.Error != (Error{}) {
+				t.Errorf("Error = %+v, want it reset", response.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package bca
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//MaxAccountStatementDays is the longest period, in days, of an AccountStatementRequest
+const MaxAccountStatementDays = 31
+
+//PendingTransactionDate is the TransactionDate of statement entries that are not booked yet
+const PendingTransactionDate = "PEND"
+
+//TransactionType represents the direction of an account statement entry
+type TransactionType string
+
+//Transaction types of account statement entries
+const (
+	TransactionTypeDebit  TransactionType = "D"
+	TransactionTypeCredit TransactionType = "C"
+)
+
+//StatementEntry represents an account statement entry with typed fields
+type StatementEntry struct {
+	// Date is zero for pending entries
+	Date       time.Time
+	Pending    bool
+	BranchCode string
+	Type       TransactionType
+	Amount     Amount
+	Name       string
+	Trailer    string
+	// Balance is the running balance after the entry, pending entries do not change it
+	Balance Amount
+}
+
+//Statement represents the typed account statement of a period
+type Statement struct {
+	AccountNumber string
+	Currency      string
+	StartDate     time.Time
+	EndDate       time.Time
+	StartBalance  Amount
+	EndBalance    Amount
+	Entries       []StatementEntry
+}
+
+//ParseStatementDate parses the TransactionDate of an entry, either "dd/MM" or "PEND", of a statement from start to end.
+//The year is taken from the period
+func ParseStatementDate(transactionDate string, start, end time.Time) (date time.Time, pending bool, err error) {
+	transactionDate = strings.TrimSpace(transactionDate)
+	if strings.EqualFold(transactionDate, PendingTransactionDate) {
+		return time.Time{}, true, nil
+	}
+
+	var day, month int
+	if _, err := fmt.Sscanf(transactionDate, "%d/%d", &day, &month); err != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false, fmt.Errorf("bca: invalid statement date %q", transactionDate)
+	}
+
+	first := truncateDay(start)
+	last := truncateDay(end)
+	for year := first.Year(); year <= last.Year(); year++ {
+		date = time.Date(year, time.Month(month), day, 0, 0, 0, 0, start.Location())
+		if date.Day() == day && !date.Before(first) && !date.After(last) {
+			return date, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("bca: statement date %q outside of %s - %s", transactionDate, first.Format("2006-01-02"), last.Format("2006-01-02"))
+}
+
+//NewStatementEntry converts an AccountStatement row of a statement from start to end, Balance is left zero
+func NewStatementEntry(row AccountStatement, start, end time.Time) (StatementEntry, error) {
+	date, pending, err := ParseStatementDate(row.TransactionDate, start, end)
+	if err != nil {
+		return StatementEntry{}, err
+	}
+
+	transactionType := TransactionType(strings.ToUpper(strings.TrimSpace(row.TransactionType)))
+	if transactionType != TransactionTypeDebit && transactionType != TransactionTypeCredit {
+		return StatementEntry{}, fmt.Errorf("bca: invalid statement transaction type %q", row.TransactionType)
+	}
+
+	return StatementEntry{
+		Date:       date,
+		Pending:    pending,
+		BranchCode: row.BranchCode,
+		Type:       transactionType,
+		Amount:     row.TransactionAmount,
+		Name:       row.TransactionName,
+		Trailer:    row.Trailer,
+	}, nil
+}
+
+//Apply returns balance after the entry, credits add to it and debits subtract from it
+func (e StatementEntry) Apply(balance Amount) Amount {
+	if e.Pending {
+		return balance
+	}
+	if e.Type == TransactionTypeCredit {
+		return balance.Add(e.Amount)
+	}
+	return balance.Sub(e.Amount)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,35 @@
+package bca
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+//TokenSource is used by service clients to obtain a valid OAuth 2.0 access token for each call
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+//TokenInvalidator is implemented by a TokenSource caching its token, which service clients drop when BCA rejects it
+//before its expiry, such as after it was revoked
+type TokenInvalidator interface {
+	Invalidate()
+}
+
+//TokenRejected reports whether err is BCA refusing the access token of a call, a 401 response or ErrExpiredToken
+func TokenRejected(err error) bool {
+	var bcaErr *Error
+	if errors.As(err, &bcaErr) && bcaErr.HTTPStatus == http.StatusUnauthorized {
+		return true
+	}
+	return errors.Is(err, ErrExpiredToken)
+}
+
+//StaticTokenSource is a TokenSource that always returns the same access token
+type StaticTokenSource string
+
+//Token returns the static access token
+func (s StaticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(s), nil
+}
@@ -13,19 +13,114 @@ type DetailsBill struct {
 	BillNumber    string
 }
 
+//MaxInquiryStatusPaymentRows is the maximum number of transactions BCA returns for one payment status inquiry
+const MaxInquiryStatusPaymentRows = 10
+
 //VAInquiryStatusPaymentResponse represents Virtual Account transaction info
 type VAInquiryStatusPaymentResponse struct {
+	CompanyCode       string
+	CustomerNumber    string
+	CustomerName      string
 	DetailBills       []DetailsBill
 	RequestID         string
 	TransactionDate   string
-	PaymentFlagStatus string
+	PaymentFlagStatus PaymentFlagStatus
+	PaymentFlagReason ErrorLang
 	Reference         string
-	TotalAmount       float64 `json:",string"`
-	PaidAmount        float64 `json:",string"`
+	CurrencyCode      string
+	TotalAmount       Amount
+	PaidAmount        Amount
 }
 
-//VAInquiryStatusPaymentResponse represents Virtual Account payment status response message
+//InquiryStatusPaymentResponse represents Virtual Account payment status response message
 type InquiryStatusPaymentResponse struct {
 	Error
-	TransactionData []InquiryStatusPaymentResponse
+	TransactionData []VAInquiryStatusPaymentResponse
+}
+
+//Status of bill presentment and payment flag responses sent by the biller to BCA
+const (
+	InquiryStatusSuccess = "00"
+	InquiryStatusFailed  = "01"
+
+	FlagStatusSuccess  = "00"
+	FlagStatusRejected = "01"
+	FlagStatusTimeout  = "02"
+)
+
+//FlagAdvice values of PaymentFlagRequest. An advice is a payment flag BCA sends again for a payment it already completed,
+//after the biller did not answer the first one in time
+const (
+	FlagAdviceYes = "Y"
+	FlagAdviceNo  = "N"
+)
+
+//BillDetail represents a bill in bill presentment and payment flag messages
+type BillDetail struct {
+	BillDescription ErrorLang `json:",omitempty"`
+	BillAmount      Amount
+	BillNumber      string
+	BillSubCompany  string
+	BillReference   string `json:",omitempty"`
+}
+
+//BillPresentmentRequest represents bill inquiry request message sent by BCA to the biller
+type BillPresentmentRequest struct {
+	CompanyCode     string
+	CustomerNumber  string
+	RequestID       string
+	ChannelType     string
+	TransactionDate string
+	AdditionalData  string
+}
+
+//BillPresentmentResponse represents bill inquiry response message sent by the biller to BCA
+type BillPresentmentResponse struct {
+	CompanyCode    string
+	CustomerNumber string
+	RequestID      string
+	InquiryStatus  string
+	InquiryReason  ErrorLang
+	CustomerName   string
+	CurrencyCode   string
+	TotalAmount    Amount
+	SubCompany     string
+	DetailBills    []BillDetail
+	FreeTexts      []ErrorLang
+	AdditionalData string
+}
+
+//PaymentFlagRequest represents payment flag request message sent by BCA to the biller
+type PaymentFlagRequest struct {
+	CompanyCode     string
+	CustomerNumber  string
+	RequestID       string
+	ChannelType     string
+	CustomerName    string
+	CurrencyCode    string
+	PaidAmount      Amount
+	TotalAmount     Amount
+	SubCompany      string
+	TransactionDate string
+	Reference       string
+	DetailBills     []BillDetail
+	FlagAdvice      string
+	AdditionalData  string
+}
+
+//PaymentFlagResponse represents payment flag response message sent by the biller to BCA
+type PaymentFlagResponse struct {
+	CompanyCode       string
+	CustomerNumber    string
+	RequestID         string
+	PaymentFlagStatus string
+	PaymentFlagReason ErrorLang
+	CustomerName      string
+	CurrencyCode      string
+	PaidAmount        Amount
+	TotalAmount       Amount
+	TransactionDate   string
+	DetailBills       []BillDetail
+	FreeTexts         []ErrorLang
+	AdditionalData    string
 }
@@ -9,22 +9,44 @@ import (
 
 //Client is used to invoke BCA Virtual Account API
 type Client struct {
-	Client      bca.APIImplementation
+	Client      bca.API
 	AccessToken string
+	TokenSource bca.TokenSource
 	CompanyCode string
 }
 
 //NewClient is used to initialize new va.Client
 func NewClient(config bca.Config) Client {
+	api := bca.NewAPI(config)
 	return Client{
 		CompanyCode: config.CompanyCode,
-		Client:      bca.NewAPI(config),
+		Client:      &api,
 	}
 }
 
+//accessToken returns the token from TokenSource when it is set, otherwise the static AccessToken
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.TokenSource == nil {
+		return c.AccessToken, nil
+	}
+	return c.TokenSource.Token(ctx)
+}
+
+//checkToken drops the token cached by TokenSource when BCA rejected it, so that the next call fetches a new one
+func (c *Client) checkToken(err error) error {
+	if invalidator, ok := c.TokenSource.(bca.TokenInvalidator); ok && bca.TokenRejected(err) {
+		invalidator.Invalidate()
+	}
+	return err
+}
+
 //VAInquiryStatusPayment is used to see the list of payment status that are owned by the customers. The data will be automatically queried between D-day (hari H) until D-2 day (H-2 / the day before yesterday), with maximum records returned are 10 rows
-func (c *Client) VAInquiryStatusPayment(ctx context.Context, ptr_vaInquiryStatusPaymentRequest *bca.InquiryStatusPaymentRequest) (*bca.VAInquiryStatusPaymentResponse, error) {
-	var inquiryStatusPaymentResponse bca.VAInquiryStatusPaymentResponse
+func (c *Client) VAInquiryStatusPayment(ctx context.Context, ptr_vaInquiryStatusPaymentRequest *bca.InquiryStatusPaymentRequest) (*bca.InquiryStatusPaymentResponse, error) {
+	var inquiryStatusPaymentResponse bca.InquiryStatusPaymentResponse
+	if err := (*ptr_vaInquiryStatusPaymentRequest).Validate(); err != nil {
+		return &inquiryStatusPaymentResponse, err
+	}
+
 	path := "/va/payments"
 
 	v := url.Values{}
@@ -41,7 +63,12 @@ func (c *Client) VAInquiryStatusPayment(ctx context.Context, ptr_vaInquiryStatus
 
 	path += "?" + v.Encode()
 
-	if err := c.Client.Call("GET", path, c.AccessToken, nil, nil, &inquiryStatusPaymentResponse); err != nil {
+	accessToken, err := c.accessToken(ctx)
+	if err != nil {
+		return &inquiryStatusPaymentResponse, err
+	}
+
+	if err := c.checkToken(c.Client.CallContext(ctx, "GET", path, accessToken, nil, nil, &inquiryStatusPaymentResponse)); err != nil {
 		return &inquiryStatusPaymentResponse, err
 	}
 	return &inquiryStatusPaymentResponse, nil
@@ -0,0 +1,106 @@
+package va
+
+import (
+	"context"
+	"errors"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//ErrNoPaymentStatusQuery is returned by the iterator of PaymentStatuses called without customer numbers and request IDs,
+//as BCA needs either of them to inquire payment statuses
+var ErrNoPaymentStatusQuery = errors.New("va: PaymentStatuses needs customer numbers or request IDs")
+
+//PaymentStatusIterator walks the Virtual Account payment statuses of a company code, see Client.PaymentStatuses
+type PaymentStatusIterator struct {
+	ctx       context.Context
+	client    *Client
+	queries   []bca.InquiryStatusPaymentRequest
+	buf       []bca.VAInquiryStatusPaymentResponse
+	seen      map[string]bool
+	current   bca.VAInquiryStatusPaymentResponse
+	truncated bool
+	err       error
+}
+
+//PaymentStatuses returns an iterator over the payment statuses of companyCode, or of c.CompanyCode when empty.
+//Every customer number is inquired first, then every request ID that was not returned yet. Without customer
+//numbers and request IDs nothing is inquired and Err returns ErrNoPaymentStatusQuery. Each inquiry only covers
+//D-day until D-2 and returns at most 10 rows, so pass request IDs to reach payments beyond that cap
+func (c *Client) PaymentStatuses(ctx context.Context, companyCode string, customerNumbers []string, requestIDs []string) *PaymentStatusIterator {
+	if companyCode == "" {
+		companyCode = c.CompanyCode
+	}
+
+	it := &PaymentStatusIterator{
+		ctx:    ctx,
+		client: c,
+		seen:   map[string]bool{},
+	}
+	for _, customerNumber := range customerNumbers {
+		it.queries = append(it.queries, bca.InquiryStatusPaymentRequest{CompanyCode: companyCode, CustomerNumber: customerNumber})
+	}
+	for _, requestID := range requestIDs {
+		it.queries = append(it.queries, bca.InquiryStatusPaymentRequest{CompanyCode: companyCode, RequestID: requestID})
+	}
+	if len(it.queries) == 0 {
+		it.err = ErrNoPaymentStatusQuery
+	}
+	return it
+}
+
+//Next advances to the next payment status, it returns false when there are no more or an error occurred
+func (it *PaymentStatusIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil || len(it.queries) == 0 {
+			return false
+		}
+
+		query := it.queries[0]
+		it.queries = it.queries[1:]
+
+		// Request IDs that were already returned by an earlier inquiry are not inquired again
+		if query.RequestID != "" && it.seen[query.RequestID] {
+			continue
+		}
+
+		ptr_response, err := it.client.VAInquiryStatusPayment(it.ctx, &query)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len((*ptr_response).TransactionData) >= bca.MaxInquiryStatusPaymentRows {
+			it.truncated = true
+		}
+
+		// Payments without RequestID cannot be told apart, so they are all returned
+		for _, payment := range (*ptr_response).TransactionData {
+			if payment.RequestID != "" {
+				if it.seen[payment.RequestID] {
+					continue
+				}
+				it.seen[payment.RequestID] = true
+			}
+			it.buf = append(it.buf, payment)
+		}
+	}
+
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	return true
+}
+
+//PaymentStatus returns the current payment status
+func (it *PaymentStatusIterator) PaymentStatus() bca.VAInquiryStatusPaymentResponse {
+	return it.current
+}
+
+//Err returns the error that stopped the iteration, if any
+func (it *PaymentStatusIterator) Err() error {
+	return it.err
+}
+
+//Truncated reports whether an inquiry returned the maximum number of rows, so some payments may be missing
+func (it *PaymentStatusIterator) Truncated() bool {
+	return it.truncated
+}
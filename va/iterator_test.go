@@ -0,0 +1,123 @@
+package va
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	bca "github.com/ianeinser/bca-api-go"
+	"github.com/ianeinser/bca-api-go/bcatest"
+)
+
+//payments returns a payment status response of the given request IDs
+func payments(requestIDs ...string) bcatest.Response {
+	var response bca.InquiryStatusPaymentResponse
+	for _, requestID := range requestIDs {
+		response.TransactionData = append(response.TransactionData, bca.VAInquiryStatusPaymentResponse{CompanyCode: "12345", RequestID: requestID})
+	}
+	return bcatest.Response{Body: response}
+}
+
+func TestPaymentStatuses(t *testing.T) {
+	var full []string
+	for i := 1; i <= bca.MaxInquiryStatusPaymentRows; i++ {
+		full = append(full, fmt.Sprintf("REQ%02d", i))
+	}
+
+	tests := []struct {
+		name            string
+		customerNumbers []string
+		requestIDs      []string
+		responses       map[string]bcatest.Response
+		want            []string
+		wantCalls       int
+		wantTruncated   bool
+		wantErr         error
+	}{
+		{
+			name:            "customer numbers then request IDs",
+			customerNumbers: []string{"0001", "0002"},
+			requestIDs:      []string{"REQ02", "REQ04"},
+			responses: map[string]bcatest.Response{
+				"CustomerNumber=0001": payments("REQ01", "REQ02"),
+				"CustomerNumber=0002": payments("REQ03", "REQ01"),
+				"RequestId=REQ04":     payments("REQ04"),
+			},
+			want: []string{"REQ01", "REQ02", "REQ03", "REQ04"},
+			// REQ02 was returned for customer number 0001, so it is not inquired again
+			wantCalls: 3,
+		},
+		{
+			name:            "truncated",
+			customerNumbers: []string{"0001"},
+			requestIDs:      []string{"REQ11"},
+			responses: map[string]bcatest.Response{
+				"CustomerNumber=0001": payments(full...),
+				"RequestId=REQ11":     payments("REQ11"),
+			},
+			want:          append(append([]string{}, full...), "REQ11"),
+			wantCalls:     2,
+			wantTruncated: true,
+		},
+		{
+			name:            "payments without request ID",
+			customerNumbers: []string{"0001", "0002"},
+			responses: map[string]bcatest.Response{
+				"CustomerNumber=0001": payments("", ""),
+				"CustomerNumber=0002": payments(""),
+			},
+			want:      []string{"", "", ""},
+			wantCalls: 2,
+		},
+		{
+			name:    "company code alone",
+			wantErr: ErrNoPaymentStatusQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := bcatest.NewRecorder()
+			for query, response := range tt.responses {
+				recorder.Respond("GET", "/va/payments?CompanyCode=12345&"+query, response)
+			}
+			c := Client{Client: recorder, CompanyCode: "12345"}
+
+			var got []string
+			it := c.PaymentStatuses(context.Background(), "", tt.customerNumbers, tt.requestIDs)
+			for it.Next() {
+				got = append(got, it.PaymentStatus().RequestID)
+			}
+
+			if it.Err() != tt.wantErr {
+				t.Fatalf("Err() = %v, want %v", it.Err(), tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("request IDs = %v, want %v", got, tt.want)
+			}
+			if calls := len(recorder.Calls()); calls != tt.wantCalls {
+				t.Errorf("inquired %d times, want %d", calls, tt.wantCalls)
+			}
+			if it.Truncated() != tt.wantTruncated {
+				t.Errorf("Truncated() = %v, want %v", it.Truncated(), tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestPaymentStatusesInvalidQuery(t *testing.T) {
+	recorder := bcatest.NewRecorder()
+	c := Client{Client: recorder, CompanyCode: "12345"}
+
+	// The query is checked by InquiryStatusPaymentRequest.Validate before anything is sent
+	it := c.PaymentStatuses(context.Background(), "", []string{"0001/2"}, nil)
+	if it.Next() {
+		t.Fatal("Next() = true, want false")
+	}
+	if err, ok := it.Err().(bca.ValidationError); !ok || err[0].Field != "CustomerNumber" {
+		t.Errorf("Err() = %v, want a ValidationError of CustomerNumber", it.Err())
+	}
+	if calls := len(recorder.Calls()); calls != 0 {
+		t.Errorf("inquired %d times, want 0", calls)
+	}
+}
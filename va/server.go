@@ -0,0 +1,334 @@
+package va
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//Bill represents the bills of a customer number returned by BillProvider
+type Bill struct {
+	CustomerName   string
+	CurrencyCode   string
+	TotalAmount    bca.Amount
+	SubCompany     string
+	DetailBills    []bca.BillDetail
+	FreeTexts      []bca.ErrorLang
+	AdditionalData string
+}
+
+//BillProvider is implemented by the biller application to look up bills and record payments.
+//Return a *Rejection to refuse a request with a reason shown to the customer. A payment flag with FlagAdvice
+//bca.FlagAdviceYes was already completed by BCA, so RecordPayment should record it rather than reject it
+type BillProvider interface {
+	LookupBill(ctx context.Context, ptr_billPresentmentRequest *bca.BillPresentmentRequest) (*Bill, error)
+	RecordPayment(ctx context.Context, ptr_paymentFlagRequest *bca.PaymentFlagRequest) error
+}
+
+//Rejection is returned by BillProvider to refuse a bill presentment or payment flag with a bilingual reason
+type Rejection struct {
+	Reason bca.ErrorLang
+}
+
+//Error returns the English reason of the rejection
+func (r *Rejection) Error() string {
+	return "va: rejected: " + r.Reason.English
+}
+
+//Reject is used to initialize new Rejection
+func Reject(indonesian, english string) *Rejection {
+	return &Rejection{Reason: bca.ErrorLang{Indonesian: indonesian, English: english}}
+}
+
+//Common rejections of BillProvider
+var (
+	ErrBillNotFound   = Reject("Tagihan tidak ditemukan", "Bill not found")
+	ErrBillPaid       = Reject("Tagihan sudah dibayar", "Bill already paid")
+	ErrAmountMismatch = Reject("Jumlah pembayaran tidak sesuai", "Paid amount does not match")
+)
+
+//ErrServerNotConfigured is answered with HTTP 500 by the handlers of a Server without Provider or Store, such as a
+//zero value Server not initialized with NewServer
+var ErrServerNotConfigured = errors.New("va: Server has no Provider or Store, initialize it with NewServer")
+
+var (
+	reasonSuccess     = bca.ErrorLang{Indonesian: "Sukses", English: "Success"}
+	reasonSystemError = bca.ErrorLang{Indonesian: "Sistem sedang mengalami gangguan", English: "System is unavailable"}
+	reasonCompanyCode = bca.ErrorLang{Indonesian: "Kode perusahaan tidak valid", English: "Invalid company code"}
+	errInvalidRequest = &bca.Error{ErrorCode: "ESB-14-002", ErrorMessage: bca.ErrorLang{Indonesian: "Permintaan tidak valid", English: "Invalid request"}}
+)
+
+//PaymentFlagStore keeps the responses of handled payment flags, so a repeated flag is answered without recording the payment again
+type PaymentFlagStore interface {
+	Load(ctx context.Context, key string) (*bca.PaymentFlagResponse, bool, error)
+	Save(ctx context.Context, key string, ptr_paymentFlagResponse *bca.PaymentFlagResponse) error
+}
+
+//MemoryPaymentFlagStore is a PaymentFlagStore kept in memory
+type MemoryPaymentFlagStore struct {
+	mu        sync.Mutex
+	responses map[string]bca.PaymentFlagResponse
+}
+
+//Load returns the stored response of a payment flag
+func (m *MemoryPaymentFlagStore) Load(ctx context.Context, key string) (*bca.PaymentFlagResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	response, ok := m.responses[key]
+	return &response, ok, nil
+}
+
+//Save stores the response of a payment flag
+func (m *MemoryPaymentFlagStore) Save(ctx context.Context, key string, ptr_paymentFlagResponse *bca.PaymentFlagResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.responses == nil {
+		m.responses = map[string]bca.PaymentFlagResponse{}
+	}
+	m.responses[key] = *ptr_paymentFlagResponse
+	return nil
+}
+
+//Server handles the bill presentment and payment flag callbacks that BCA sends to the biller
+type Server struct {
+	CompanyCode string
+	Provider    BillProvider
+	// Verifier checks the signature of every callback, nil disables the check
+	Verifier *bca.SignatureVerifier
+	Store    PaymentFlagStore
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+//NewServer is used to initialize new va.Server verifying callbacks with the API key and secret of config
+func NewServer(config bca.Config, provider BillProvider) *Server {
+	return &Server{
+		CompanyCode: config.CompanyCode,
+		Provider:    provider,
+		Verifier:    bca.NewSignatureVerifier(config.APIKey, config.APISecret),
+		Store:       &MemoryPaymentFlagStore{},
+	}
+}
+
+//BillPresentmentHandler returns the http.Handler of the bill presentment (inquiry) callback
+func (s *Server) BillPresentmentHandler() http.Handler {
+	return s.verified(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.configured(w) {
+			return
+		}
+
+		var request bca.BillPresentmentRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, errInvalidRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.BillPresentment(r.Context(), &request))
+	}))
+}
+
+//PaymentFlagHandler returns the http.Handler of the payment flag callback
+func (s *Server) PaymentFlagHandler() http.Handler {
+	return s.verified(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.configured(w) {
+			return
+		}
+
+		var request bca.PaymentFlagRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || !validFlagAdvice(request.FlagAdvice) {
+			writeJSON(w, http.StatusBadRequest, errInvalidRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.PaymentFlag(r.Context(), &request))
+	}))
+}
+
+//BillPresentment answers a bill presentment request with the bill from Provider
+func (s *Server) BillPresentment(ctx context.Context, ptr_billPresentmentRequest *bca.BillPresentmentRequest) *bca.BillPresentmentResponse {
+	request := *ptr_billPresentmentRequest
+	response := bca.BillPresentmentResponse{
+		CompanyCode:    request.CompanyCode,
+		CustomerNumber: request.CustomerNumber,
+		RequestID:      request.RequestID,
+		InquiryStatus:  bca.InquiryStatusFailed,
+		DetailBills:    []bca.BillDetail{},
+		FreeTexts:      []bca.ErrorLang{},
+		AdditionalData: request.AdditionalData,
+	}
+
+	if s.CompanyCode != "" && request.CompanyCode != s.CompanyCode {
+		response.InquiryReason = reasonCompanyCode
+		return &response
+	}
+	if s.Provider == nil {
+		response.InquiryReason = reasonSystemError
+		return &response
+	}
+
+	ptr_bill, err := s.Provider.LookupBill(ctx, ptr_billPresentmentRequest)
+	if err != nil {
+		response.InquiryReason = reason(err)
+		return &response
+	}
+
+	bill := *ptr_bill
+	response.InquiryStatus = bca.InquiryStatusSuccess
+	response.InquiryReason = reasonSuccess
+	response.CustomerName = bill.CustomerName
+	response.CurrencyCode = bill.CurrencyCode
+	response.TotalAmount = bill.TotalAmount
+	response.SubCompany = bill.SubCompany
+	if bill.DetailBills != nil {
+		response.DetailBills = bill.DetailBills
+	}
+	if bill.FreeTexts != nil {
+		response.FreeTexts = bill.FreeTexts
+	}
+	if bill.AdditionalData != "" {
+		response.AdditionalData = bill.AdditionalData
+	}
+	return &response
+}
+
+//PaymentFlag records a payment with Provider. A repeated payment flag with the same company code,
+//customer number and request ID is answered with the first response and is not recorded again
+func (s *Server) PaymentFlag(ctx context.Context, ptr_paymentFlagRequest *bca.PaymentFlagRequest) *bca.PaymentFlagResponse {
+	request := *ptr_paymentFlagRequest
+	response := bca.PaymentFlagResponse{
+		CompanyCode:       request.CompanyCode,
+		CustomerNumber:    request.CustomerNumber,
+		RequestID:         request.RequestID,
+		PaymentFlagStatus: bca.FlagStatusRejected,
+		CustomerName:      request.CustomerName,
+		CurrencyCode:      request.CurrencyCode,
+		PaidAmount:        request.PaidAmount,
+		TotalAmount:       request.TotalAmount,
+		TransactionDate:   request.TransactionDate,
+		DetailBills:       request.DetailBills,
+		FreeTexts:         []bca.ErrorLang{},
+		AdditionalData:    request.AdditionalData,
+	}
+	if response.DetailBills == nil {
+		response.DetailBills = []bca.BillDetail{}
+	}
+
+	if s.CompanyCode != "" && request.CompanyCode != s.CompanyCode {
+		response.PaymentFlagReason = reasonCompanyCode
+		return &response
+	}
+	if s.Provider == nil || s.Store == nil {
+		response.PaymentFlagReason = reasonSystemError
+		return &response
+	}
+
+	key := request.CompanyCode + "/" + request.CustomerNumber + "/" + request.RequestID
+	release, err := s.acquire(ctx, key)
+	if err != nil {
+		response.PaymentFlagReason = reasonSystemError
+		return &response
+	}
+	defer release()
+
+	if ptr_stored, ok, err := s.Store.Load(ctx, key); err != nil {
+		response.PaymentFlagReason = reasonSystemError
+		return &response
+	} else if ok {
+		return ptr_stored
+	}
+
+	if err := s.Provider.RecordPayment(ctx, ptr_paymentFlagRequest); err != nil {
+		response.PaymentFlagReason = reason(err)
+
+		// Only rejections are final, other failures may succeed when BCA sends the flag again
+		var rejection *Rejection
+		if errors.As(err, &rejection) {
+			if err := s.Store.Save(ctx, key, &response); err != nil {
+				response.PaymentFlagReason = reasonSystemError
+			}
+		}
+		return &response
+	}
+
+	response.PaymentFlagStatus = bca.FlagStatusSuccess
+	response.PaymentFlagReason = reasonSuccess
+
+	// The payment is recorded, so it is reported as successful even if the response cannot be stored
+	_ = s.Store.Save(ctx, key, &response)
+	return &response
+}
+
+//acquire serializes the handling of payment flags with the same key, it gives up waiting when ctx is done
+func (s *Server) acquire(ctx context.Context, key string) (func(), error) {
+	for {
+		s.mu.Lock()
+		if s.inflight == nil {
+			s.inflight = map[string]chan struct{}{}
+		}
+		wait, busy := s.inflight[key]
+		if !busy {
+			done := make(chan struct{})
+			s.inflight[key] = done
+			s.mu.Unlock()
+
+			return func() {
+				s.mu.Lock()
+				delete(s.inflight, key)
+				s.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+//configured answers with HTTP 500 and ErrServerNotConfigured when the server misses its Provider or Store
+func (s *Server) configured(w http.ResponseWriter) bool {
+	if s.Provider != nil && s.Store != nil {
+		return true
+	}
+	writeJSON(w, http.StatusInternalServerError, &bca.Error{
+		ErrorMessage: bca.ErrorLang{Indonesian: reasonSystemError.Indonesian, English: ErrServerNotConfigured.Error()},
+	})
+	return false
+}
+
+//verified checks every request with the Verifier of the server at the time of the request
+func (s *Server) verified(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Verifier == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s.Verifier.Middleware(next, nil).ServeHTTP(w, r)
+	})
+}
+
+func validFlagAdvice(flagAdvice string) bool {
+	return flagAdvice == "" || flagAdvice == bca.FlagAdviceYes || flagAdvice == bca.FlagAdviceNo
+}
+
+func reason(err error) bca.ErrorLang {
+	var rejection *Rejection
+	if errors.As(err, &rejection) {
+		return rejection.Reason
+	}
+	return reasonSystemError
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,209 @@
+package va
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bca "github.com/ianeinser/bca-api-go"
+)
+
+//testProvider knows the bill of customer number 0001 and records payments, failing with err
+type testProvider struct {
+	mu       sync.Mutex
+	err      error
+	recorded int
+	// block, when set, holds RecordPayment until it is closed
+	block chan struct{}
+}
+
+func (p *testProvider) LookupBill(ctx context.Context, ptr_billPresentmentRequest *bca.BillPresentmentRequest) (*Bill, error) {
+	if (*ptr_billPresentmentRequest).CustomerNumber != "0001" {
+		return nil, ErrBillNotFound
+	}
+	return &Bill{CustomerName: "Budi Santoso", CurrencyCode: "IDR", TotalAmount: bca.MustParseAmount("150000")}, nil
+}
+
+func (p *testProvider) RecordPayment(ctx context.Context, ptr_paymentFlagRequest *bca.PaymentFlagRequest) error {
+	if p.block != nil {
+		<-p.block
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.recorded++
+	return p.err
+}
+
+func testServer(provider BillProvider) *Server {
+	s := NewServer(bca.Config{CompanyCode: "12345", APIKey: "key", APISecret: "secret"}, provider)
+	s.Verifier = nil
+	return s
+}
+
+func TestServerBillPresentment(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    bca.BillPresentmentRequest
+		wantStatus string
+		wantReason bca.ErrorLang
+	}{
+		{name: "bill", request: bca.BillPresentmentRequest{CompanyCode: "12345", CustomerNumber: "0001"}, wantStatus: bca.InquiryStatusSuccess, wantReason: reasonSuccess},
+		{name: "no bill", request: bca.BillPresentmentRequest{CompanyCode: "12345", CustomerNumber: "0002"}, wantStatus: bca.InquiryStatusFailed, wantReason: ErrBillNotFound.Reason},
+		{name: "other company", request: bca.BillPresentmentRequest{CompanyCode: "54321", CustomerNumber: "0001"}, wantStatus: bca.InquiryStatusFailed, wantReason: reasonCompanyCode},
+	}
+
+	s := testServer(&testProvider{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr_response := s.BillPresentment(context.Background(), &tt.request)
+			if (*ptr_response).InquiryStatus != tt.wantStatus || (*ptr_response).InquiryReason != tt.wantReason {
+				t.Errorf("BillPresentment() = %s %+v, want %s %+v", (*ptr_response).InquiryStatus, (*ptr_response).InquiryReason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestServerPaymentFlag(t *testing.T) {
+	errUnavailable := errors.New("database unavailable")
+
+	tests := []struct {
+		name         string
+		err          error
+		wantStatus   string
+		wantReason   bca.ErrorLang
+		wantRecorded int
+	}{
+		{name: "recorded once", wantStatus: bca.FlagStatusSuccess, wantReason: reasonSuccess, wantRecorded: 1},
+		{name: "rejection is final", err: ErrBillPaid, wantStatus: bca.FlagStatusRejected, wantReason: ErrBillPaid.Reason, wantRecorded: 1},
+		{name: "failure is retried", err: errUnavailable, wantStatus: bca.FlagStatusRejected, wantReason: reasonSystemError, wantRecorded: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &testProvider{err: tt.err}
+			s := testServer(provider)
+			request := bca.PaymentFlagRequest{CompanyCode: "12345", CustomerNumber: "0001", RequestID: "REQ1"}
+
+			for i := 0; i < 2; i++ {
+				ptr_response := s.PaymentFlag(context.Background(), &request)
+				if (*ptr_response).PaymentFlagStatus != tt.wantStatus || (*ptr_response).PaymentFlagReason != tt.wantReason {
+					t.Errorf("PaymentFlag() = %s %+v, want %s %+v", (*ptr_response).PaymentFlagStatus, (*ptr_response).PaymentFlagReason, tt.wantStatus, tt.wantReason)
+				}
+			}
+			if provider.recorded != tt.wantRecorded {
+				t.Errorf("recorded %d payments, want %d", provider.recorded, tt.wantRecorded)
+			}
+		})
+	}
+}
+
+func TestServerPaymentFlagConcurrent(t *testing.T) {
+	provider := &testProvider{block: make(chan struct{})}
+	s := testServer(provider)
+	request := bca.PaymentFlagRequest{CompanyCode: "12345", CustomerNumber: "0001", RequestID: "REQ1"}
+
+	var wg sync.WaitGroup
+	responses := make([]*bca.PaymentFlagResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = s.PaymentFlag(context.Background(), &request)
+		}(i)
+	}
+	close(provider.block)
+	wg.Wait()
+
+	for i, ptr_response := range responses {
+		if (*ptr_response).PaymentFlagStatus != bca.FlagStatusSuccess {
+			t.Errorf("PaymentFlag() in goroutine %d = %s, want success", i, (*ptr_response).PaymentFlagStatus)
+		}
+	}
+	if provider.recorded != 1 {
+		t.Errorf("recorded %d payments, want 1", provider.recorded)
+	}
+}
+
+func TestServerPaymentFlagCancelled(t *testing.T) {
+	provider := &testProvider{block: make(chan struct{})}
+	defer close(provider.block)
+	s := testServer(provider)
+	request := bca.PaymentFlagRequest{CompanyCode: "12345", CustomerNumber: "0001", RequestID: "REQ1"}
+
+	go s.PaymentFlag(context.Background(), &request)
+	for {
+		s.mu.Lock()
+		busy := len(s.inflight) == 1
+		s.mu.Unlock()
+		if busy {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A flag waiting for the same key gives up with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ptr_response := s.PaymentFlag(ctx, &request)
+	if (*ptr_response).PaymentFlagStatus != bca.FlagStatusRejected || (*ptr_response).PaymentFlagReason != reasonSystemError {
+		t.Errorf("PaymentFlag() = %s %+v, want a system error", (*ptr_response).PaymentFlagStatus, (*ptr_response).PaymentFlagReason)
+	}
+}
+
+func TestServerPaymentFlagHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		server     func() *Server
+		wantStatus int
+	}{
+		{name: "payment flag", body: `{"CompanyCode":"12345","CustomerNumber":"0001","RequestID":"REQ1","FlagAdvice":"N"}`, server: func() *Server { return testServer(&testProvider{}) }, wantStatus: http.StatusOK},
+		{name: "advice", body: `{"CompanyCode":"12345","CustomerNumber":"0001","RequestID":"REQ1","FlagAdvice":"Y"}`, server: func() *Server { return testServer(&testProvider{}) }, wantStatus: http.StatusOK},
+		{name: "unknown flag advice", body: `{"CompanyCode":"12345","CustomerNumber":"0001","RequestID":"REQ1","FlagAdvice":"X"}`, server: func() *Server { return testServer(&testProvider{}) }, wantStatus: http.StatusBadRequest},
+		{name: "malformed", body: `{`, server: func() *Server { return testServer(&testProvider{}) }, wantStatus: http.StatusBadRequest},
+		{name: "not configured", body: `{}`, server: func() *Server { return &Server{} }, wantStatus: http.StatusInternalServerError},
+		{name: "unsigned", body: `{}`, server: func() *Server { return NewServer(bca.Config{APIKey: "key", APISecret: "secret"}, &testProvider{}) }, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.server().PaymentFlagHandler().ServeHTTP(w, httptest.NewRequest("POST", "/va/payment-flag", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestServerVerifierReadPerRequest(t *testing.T) {
+	s := testServer(&testProvider{})
+	handler := s.BillPresentmentHandler()
+
+	// The Verifier set after the handler was created still checks its requests
+	s.Verifier = bca.NewSignatureVerifier("key", "secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/va/bills", strings.NewReader(`{}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	timestamp := time.Now().Format(time.RFC3339)
+	body := `{"CompanyCode":"12345","CustomerNumber":"0001","RequestID":"REQ1"}`
+	req := httptest.NewRequest("POST", "/va/bills", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-BCA-Key", "key")
+	req.Header.Set("X-BCA-Timestamp", timestamp)
+	req.Header.Set("X-BCA-Signature", bca.Signature("secret", "POST", "/va/bills", "token", body, timestamp))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Budi Santoso") {
+		t.Errorf("status = %d, want %d with the bill: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
@@ -0,0 +1,626 @@
+package bca
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//ErrValidation matches, with errors.Is, every ValidationError
+var ErrValidation = errors.New("bca: invalid request")
+
+//FieldError represents a request field BCA would reject
+type FieldError struct {
+	// Field is the path of the field, such as "TransactionDetails.Amount"
+	Field  string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+//ValidationError holds every FieldError of a request, it is returned by the Validate methods of request messages
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	reasons := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		reasons = append(reasons, fieldError.Error())
+	}
+	return "bca: invalid request: " + strings.Join(reasons, "; ")
+}
+
+//Is reports whether target is ErrValidation
+func (e ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+//Field lengths documented by BCA
+const (
+	maxCorporateIDLength      = 10
+	accountNumberLength       = 10
+	maxTransactionIDLength    = 8
+	maxReferenceIDLength      = 15
+	maxRemarkLength           = 18
+	maxDomesticAccountLength  = 34
+	maxDomesticBankCodeLength = 8
+	maxBeneficiaryNameLength  = 35
+	maxEmailLength            = 50
+	maxOfflineStatementDays   = 7
+	maxCompanyCodeLength      = 5
+	maxCustomerNumberLength   = 18
+	maxVARequestIDLength      = 30
+	maxFIReTextLength         = 35
+	maxFIRePostalCodeLength   = 10
+	maxFIReMobileLength       = 15
+	maxFIReBankCodeLength     = 11
+	maxFIReFormNumberLength   = 16
+	maxFIRePINLength          = 6
+	maxFIRePurposeCodeLength  = 3
+)
+
+//Validate reports every field of the request BCA would reject
+func (r BalanceInformationRequest) Validate() error {
+	var v validator
+	v.corporateID("CorporateID", r.CorporateID)
+	accountNumbers := strings.Split(r.AccountNumber, ",")
+	if len(accountNumbers) > MaxBalanceInformationAccounts {
+		v.add("AccountNumber", fmt.Sprintf("more than %d accounts", MaxBalanceInformationAccounts))
+	}
+	for _, accountNumber := range accountNumbers {
+		v.accountNumber("AccountNumber", accountNumber)
+	}
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r AccountStatementRequest) Validate() error {
+	var v validator
+	v.corporateID("CorporateID", r.CorporateID)
+	v.accountNumber("AccountNumber", r.AccountNumber)
+	v.period("EndDate", r.StartDate, r.EndDate, MaxAccountStatementDays)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r FundTransferRequest) Validate() error {
+	var v validator
+	v.corporateID("CorporateID", r.CorporateID)
+	v.accountNumber("SourceAccountNumber", r.SourceAccountNumber)
+	v.transactionID("TransactionID", r.TransactionID)
+	v.date("TransactionDate", r.TransactionDate)
+	v.referenceID("ReferenceID", r.ReferenceID)
+	v.oneOf("CurrencyCode", r.CurrencyCode, "IDR")
+	v.amount("Amount", r.Amount, "IDR")
+	v.accountNumber("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber)
+	v.maxLength("Remark1", r.Remark1, maxRemarkLength)
+	v.maxLength("Remark2", r.Remark2, maxRemarkLength)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject. BeneficiaryCustType and BeneficiaryCustResidence
+//are only required for LLG and RTG transfers
+func (r DomesticFundTransferRequest) Validate() error {
+	var v validator
+	v.transactionID("TransactionID", r.TransactionID)
+	v.date("TransactionDate", r.TransactionDate)
+	v.referenceID("ReferenceID", r.ReferenceID)
+	v.accountNumber("SourceAccountNumber", r.SourceAccountNumber)
+	v.required("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber)
+	v.maxLength("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber, maxDomesticAccountLength)
+	v.numeric("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber)
+	v.required("BeneficiaryBankCode", r.BeneficiaryBankCode)
+	v.maxLength("BeneficiaryBankCode", r.BeneficiaryBankCode, maxDomesticBankCodeLength)
+	v.alphanumeric("BeneficiaryBankCode", r.BeneficiaryBankCode)
+	v.required("BeneficiaryName", r.BeneficiaryName)
+	v.maxLength("BeneficiaryName", r.BeneficiaryName, maxBeneficiaryNameLength)
+	v.amount("Amount", r.Amount, "IDR")
+	v.oneOf("TransferType", string(r.TransferType), string(TransferTypeLLG), string(TransferTypeRTG), string(TransferTypeONL))
+	v.domesticBank("BeneficiaryBankCode", r.BeneficiaryBankCode, r.TransferType)
+	if r.Amount.Sign() > 0 && r.TransferType != "" && r.TransferType != TransferTypeBCA && !TransferTypeAccepts(r.TransferType, r.Amount) {
+		v.add("Amount", fmt.Sprintf("%s is outside the limits of %s transfers", r.Amount, r.TransferType))
+	}
+	if r.TransferType != TransferTypeONL || r.BeneficiaryCustType != "" {
+		v.oneOf("BeneficiaryCustType", string(r.BeneficiaryCustType), enumStrings(CustomerTypes())...)
+	}
+	if r.TransferType != TransferTypeONL || r.BeneficiaryCustResidence != "" {
+		v.oneOf("BeneficiaryCustResidence", string(r.BeneficiaryCustResidence), enumStrings(CustomerResidences())...)
+	}
+	v.oneOf("CurrencyCode", r.CurrencyCode, "IDR")
+	v.maxLength("Remark1", r.Remark1, maxRemarkLength)
+	v.maxLength("Remark2", r.Remark2, maxRemarkLength)
+	if r.BeneficiaryEmail != "" {
+		v.maxLength("BeneficiaryEmail", r.BeneficiaryEmail, maxEmailLength)
+		if at := strings.Index(r.BeneficiaryEmail, "@"); at < 1 || at == len(r.BeneficiaryEmail)-1 {
+			v.add("BeneficiaryEmail", "not an e-mail address")
+		}
+	}
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r AccountStatementOfflineRequest) Validate() error {
+	var v validator
+	v.accountNumber("AccountNumber", r.AccountNumber)
+	v.period("EndDate", r.StartDate, r.EndDate, maxOfflineStatementDays)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r InquiryTransferStatusRequest) Validate() error {
+	var v validator
+	v.transactionID("TransactionID", r.TransactionID)
+	if r.TransactionDate.IsZero() {
+		v.add("TransactionDate", "required")
+	}
+	v.oneOf("TransferType", string(r.TransferType), enumStrings(TransferTypes())...)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r InquiryDomesticAccountRequest) Validate() error {
+	var v validator
+	v.required("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber)
+	v.maxLength("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber, maxDomesticAccountLength)
+	v.numeric("BeneficiaryAccountNumber", r.BeneficiaryAccountNumber)
+	v.required("BeneficiaryBankCode", r.BeneficiaryBankCode)
+	v.maxLength("BeneficiaryBankCode", r.BeneficiaryBankCode, maxDomesticBankCodeLength)
+	v.alphanumeric("BeneficiaryBankCode", r.BeneficiaryBankCode)
+	v.domesticBank("BeneficiaryBankCode", r.BeneficiaryBankCode, "")
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r TeleTransferAccountRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+
+	sender := r.SenderDetails
+	v.person("SenderDetails", person{
+		FirstName: sender.FirstName, LastName: sender.LastName, DateOfBirth: sender.DateOfBirth,
+		Address1: sender.Address1, Address2: sender.Address2, City: sender.City, StateID: sender.StateID,
+		PostalCode: sender.PostalCode, CountryID: sender.CountryID, Mobile: sender.Mobile,
+		IdentificationType: sender.IdentificationType, IdentificationNumber: sender.IdentificationNumber,
+	})
+	v.required("SenderDetails.AccountNumber", sender.AccountNumber)
+	v.maxLength("SenderDetails.AccountNumber", sender.AccountNumber, maxDomesticAccountLength)
+	v.numeric("SenderDetails.AccountNumber", sender.AccountNumber)
+
+	beneficiary := r.BeneficiaryDetails
+	v.beneficiary("BeneficiaryDetails", person{
+		FirstName: beneficiary.Name, DateOfBirth: beneficiary.DateOfBirth,
+		Address1: beneficiary.Address1, Address2: beneficiary.Address2, City: beneficiary.City, StateID: beneficiary.StateID,
+		PostalCode: beneficiary.PostalCode, CountryID: beneficiary.CountryID, Mobile: beneficiary.Mobile,
+		IdentificationType: beneficiary.IdentificationType, IdentificationNumber: beneficiary.IdentificationNumber,
+	}, beneficiary.NationalityID, beneficiary.Occupation)
+	v.bankCode("BeneficiaryDetails", beneficiary.BankCodeType, beneficiary.BankCodeValue)
+	if beneficiary.BankCountryID != "" {
+		v.countryID("BeneficiaryDetails.BankCountryID", beneficiary.BankCountryID)
+	}
+	v.maxLength("BeneficiaryDetails.BankAddress", beneficiary.BankAddress, maxFIReTextLength)
+	v.maxLength("BeneficiaryDetails.BankCity", beneficiary.BankCity, maxFIReTextLength)
+	v.required("BeneficiaryDetails.AccountNumber", beneficiary.AccountNumber)
+	v.maxLength("BeneficiaryDetails.AccountNumber", beneficiary.AccountNumber, maxDomesticAccountLength)
+	v.alphanumeric("BeneficiaryDetails.AccountNumber", beneficiary.AccountNumber)
+
+	transaction := r.TransactionDetails
+	v.fireTransaction("TransactionDetails", transaction.CurrencyID, transaction.Amount, transaction.PurposeCode,
+		transaction.Description1, transaction.Description2, transaction.DetailOfCharges, transaction.SourceOfFund)
+	v.formNumber("TransactionDetails.FormNumber", transaction.FormNumber)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r InquiryAccountRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+	v.bankCode("BeneficiaryDetails", r.BeneficiaryDetails.BankCodeType, r.BeneficiaryDetails.BankCodeValue)
+	v.required("BeneficiaryDetails.AccountNumber", r.BeneficiaryDetails.AccountNumber)
+	v.maxLength("BeneficiaryDetails.AccountNumber", r.BeneficiaryDetails.AccountNumber, maxDomesticAccountLength)
+	v.alphanumeric("BeneficiaryDetails.AccountNumber", r.BeneficiaryDetails.AccountNumber)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r InquiryAccountBalanceRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+	v.required("FIDetails.AccountNumber", r.FIDetails.AccountNumber)
+	v.maxLength("FIDetails.AccountNumber", r.FIDetails.AccountNumber, maxDomesticAccountLength)
+	v.numeric("FIDetails.AccountNumber", r.FIDetails.AccountNumber)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r InquiryTransactionRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+	v.oneOf("TransactionDetails.InquiryBy", r.TransactionDetails.InquiryBy, InquiryByReferenceNumber, InquiryByFormNumber)
+	v.required("TransactionDetails.InquiryValue", r.TransactionDetails.InquiryValue)
+	v.maxLength("TransactionDetails.InquiryValue", r.TransactionDetails.InquiryValue, maxFIReTextLength)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r TeleTransferCashTransferRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+
+	sender := r.SenderDetails
+	v.person("SenderDetails", person{
+		FirstName: sender.FirstName, LastName: sender.LastName, DateOfBirth: sender.DateOfBirth,
+		Address1: sender.Address1, Address2: sender.Address2, City: sender.City, StateID: sender.StateID,
+		PostalCode: sender.PostalCode, CountryID: sender.CountryID, Mobile: sender.Mobile,
+		IdentificationType: sender.IdentificationType, IdentificationNumber: sender.IdentificationNumber,
+	})
+
+	beneficiary := r.BeneficiaryDetails
+	v.beneficiary("BeneficiaryDetails", person{
+		FirstName: beneficiary.Name, DateOfBirth: beneficiary.DateOfBirth,
+		Address1: beneficiary.Address1, Address2: beneficiary.Address2, City: beneficiary.City, StateID: beneficiary.StateID,
+		PostalCode: beneficiary.PostalCode, CountryID: beneficiary.CountryID, Mobile: beneficiary.Mobile,
+		IdentificationType: beneficiary.IdentificationType, IdentificationNumber: beneficiary.IdentificationNumber,
+	}, beneficiary.NationalityID, beneficiary.Occupation)
+
+	transaction := r.TransactionDetails
+	v.required("TransactionDetails.PIN", transaction.PIN)
+	v.maxLength("TransactionDetails.PIN", transaction.PIN, maxFIRePINLength)
+	v.numeric("TransactionDetails.PIN", transaction.PIN)
+	v.maxLength("TransactionDetails.SecretQuestion", transaction.SecretQuestion, maxFIReTextLength)
+	v.maxLength("TransactionDetails.SecretAnswer", transaction.SecretAnswer, maxFIReTextLength)
+	v.fireTransaction("TransactionDetails", transaction.CurrencyID, transaction.Amount, transaction.PurposeCode,
+		transaction.Description1, transaction.Description2, transaction.DetailOfCharges, transaction.SourceOfFund)
+	v.formNumber("TransactionDetails.FormNumber", transaction.FormNumber)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject. Amendment fields are optional, only their length is checked
+func (r TeleTransferAmendCashTransferRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+
+	sender := r.AmendmentDetails.SenderDetails
+	v.maxLengths("AmendmentDetails.SenderDetails.", maxFIReTextLength,
+		"FirstName", sender.FirstName, "LastName", sender.LastName, "Address1", sender.Address1,
+		"Address2", sender.Address2, "City", sender.City, "IdentificationNumber", sender.IdentificationNumber)
+	beneficiary := r.AmendmentDetails.BeneficiaryDetails
+	v.maxLengths("AmendmentDetails.BeneficiaryDetails.", maxFIReTextLength,
+		"Name", beneficiary.Name, "Address1", beneficiary.Address1, "Address2", beneficiary.Address2,
+		"City", beneficiary.City, "IdentificationNumber", beneficiary.IdentificationNumber, "Occupation", beneficiary.Occupation)
+	transaction := r.AmendmentDetails.TransactionDetails
+	v.maxLengths("AmendmentDetails.TransactionDetails.", maxFIReTextLength,
+		"Description1", transaction.Description1, "Description2", transaction.Description2,
+		"SecretQuestion", transaction.SecretQuestion, "SecretAnswer", transaction.SecretAnswer)
+
+	v.formNumber("TransactionDetails.FormNumber", r.TransactionDetails.FormNumber)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject
+func (r TeleTransferCancelCashTransferRequest) Validate() error {
+	var v validator
+	v.auth("Authentication", r.Authentication)
+	v.formNumber("TransactionDetails.FormNumber", r.TransactionDetails.FormNumber)
+	v.currencyCode("TransactionDetails.CurrencyID", r.TransactionDetails.CurrencyID)
+	v.amount("TransactionDetails.Amount", r.TransactionDetails.Amount, r.TransactionDetails.CurrencyID)
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject, both fields may hold a comma separated list
+func (r ForeignExchangeRateRequest) Validate() error {
+	var v validator
+	if r.CurrencyCode != "" {
+		for _, currencyCode := range strings.Split(r.CurrencyCode, ",") {
+			v.currencyCode("CurrencyCode", currencyCode)
+		}
+	}
+	if r.RateType != "" {
+		for _, rateType := range strings.Split(r.RateType, ",") {
+			v.oneOf("RateType", rateType, enumStrings(RateTypes())...)
+		}
+	}
+	return v.err()
+}
+
+//Validate reports every field of the request BCA would reject, either CustomerNumber or RequestID is required
+func (r InquiryStatusPaymentRequest) Validate() error {
+	var v validator
+	v.required("CompanyCode", r.CompanyCode)
+	v.maxLength("CompanyCode", r.CompanyCode, maxCompanyCodeLength)
+	v.numeric("CompanyCode", r.CompanyCode)
+	if r.CustomerNumber == "" && r.RequestID == "" {
+		v.add("CustomerNumber", "required when RequestID is empty")
+	}
+	v.maxLength("CustomerNumber", r.CustomerNumber, maxCustomerNumberLength)
+	v.alphanumeric("CustomerNumber", r.CustomerNumber)
+	v.maxLength("RequestID", r.RequestID, maxVARequestIDLength)
+	return v.err()
+}
+
+//person holds the details shared by the senders and beneficiaries of FIRe transactions
+type person struct {
+	FirstName            string
+	LastName             string
+	DateOfBirth          string
+	Address1             string
+	Address2             string
+	City                 string
+	StateID              string
+	PostalCode           string
+	CountryID            string
+	Mobile               string
+	IdentificationType   IdentificationType
+	IdentificationNumber string
+}
+
+//validator collects the FieldError of a request
+type validator struct {
+	errors ValidationError
+}
+
+func (v *validator) add(field, reason string) {
+	v.errors = append(v.errors, FieldError{Field: field, Reason: reason})
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "required")
+	}
+}
+
+func (v *validator) maxLength(field, value string, max int) {
+	if n := len([]rune(value)); n > max {
+		v.add(field, fmt.Sprintf("%d characters, at most %d allowed", n, max))
+	}
+}
+
+//maxLengths checks the length of each field name and value pair, the names being prefixed with prefix
+func (v *validator) maxLengths(prefix string, max int, pairs ...string) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		v.maxLength(prefix+pairs[i], pairs[i+1], max)
+	}
+}
+
+func (v *validator) numeric(field, value string) {
+	for _, ch := range value {
+		if ch < '0' || ch > '9' {
+			v.add(field, "only digits allowed")
+			return
+		}
+	}
+}
+
+func (v *validator) alphanumeric(field, value string) {
+	for _, ch := range value {
+		if (ch < '0' || ch > '9') && (ch < 'A' || ch > 'Z') && (ch < 'a' || ch > 'z') {
+			v.add(field, "only letters and digits allowed")
+			return
+		}
+	}
+}
+
+func (v *validator) oneOf(field, value string, values ...string) {
+	for _, allowed := range values {
+		if value == allowed {
+			return
+		}
+	}
+	if value == "" {
+		v.add(field, "required")
+		return
+	}
+	v.add(field, fmt.Sprintf("%q is not one of %s", value, strings.Join(values, ", ")))
+}
+
+//code checks a required BCA code whose list of values may be incomplete, unknown values are only rejected in strict mode
+func (v *validator) code(field, value string, max int, values []EnumValue) {
+	if StrictEnums() {
+		v.oneOf(field, value, enumStrings(values)...)
+		return
+	}
+	v.required(field, value)
+	v.maxLength(field, value, max)
+}
+
+//domesticBank checks that the bank of code receives transferType, when given, according to DefaultBankDirectory.
+//Banks missing from the directory are only rejected in strict mode
+func (v *validator) domesticBank(field, code string, transferType TransferType) {
+	if code == "" {
+		return
+	}
+	bank, ok := DefaultBankDirectory().ByCode(code)
+	if !ok {
+		if StrictEnums() {
+			v.add(field, fmt.Sprintf("unknown bank code %q", code))
+		}
+		return
+	}
+	if transferType.Valid() && transferType != TransferTypeBCA && !bank.Supports(transferType) {
+		v.add("TransferType", fmt.Sprintf("%s does not receive %s transfers", bank.Name, transferType))
+	}
+}
+
+func (v *validator) corporateID(field, value string) {
+	v.required(field, value)
+	v.maxLength(field, value, maxCorporateIDLength)
+	v.alphanumeric(field, value)
+}
+
+func (v *validator) accountNumber(field, value string) {
+	if value == "" {
+		v.add(field, "required")
+		return
+	}
+	if len(value) != accountNumberLength {
+		v.add(field, fmt.Sprintf("%q is not a %d digit BCA account number", value, accountNumberLength))
+		return
+	}
+	v.numeric(field, value)
+}
+
+//referenceID checks a ReferenceID such as "12345/PO/2016"
+func (v *validator) referenceID(field, value string) {
+	v.required(field, value)
+	v.maxLength(field, value, maxReferenceIDLength)
+	for _, ch := range value {
+		if (ch < '0' || ch > '9') && (ch < 'A' || ch > 'Z') && (ch < 'a' || ch > 'z') && ch != '/' && ch != '-' {
+			v.add(field, "only letters, digits, / and - allowed")
+			return
+		}
+	}
+}
+
+func (v *validator) transactionID(field, value string) {
+	v.required(field, value)
+	v.maxLength(field, value, maxTransactionIDLength)
+	v.numeric(field, value)
+}
+
+func (v *validator) date(field, value string) {
+	if value == "" {
+		v.add(field, "required")
+		return
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		v.add(field, fmt.Sprintf("%q is not a yyyy-MM-dd date", value))
+	}
+}
+
+func (v *validator) period(field string, start, end time.Time, maxDays int) {
+	if start.IsZero() || end.IsZero() {
+		v.add(field, "StartDate and EndDate are required")
+		return
+	}
+	start, end = truncateDay(start), truncateDay(end)
+	if end.Before(start) {
+		v.add(field, "before StartDate")
+		return
+	}
+	if end.After(start.AddDate(0, 0, maxDays-1)) {
+		v.add(field, fmt.Sprintf("period longer than %d days", maxDays))
+	}
+}
+
+func (v *validator) currencyCode(field, value string) {
+	if value == "" {
+		v.add(field, "required")
+		return
+	}
+	if len(value) != 3 || strings.ToUpper(value) != value || !isLetters(value) {
+		v.add(field, fmt.Sprintf("%q is not an ISO 4217 currency code", value))
+	}
+}
+
+func (v *validator) countryID(field, value string) {
+	if value == "" {
+		v.add(field, "required")
+		return
+	}
+	if len(value) != 2 || strings.ToUpper(value) != value || !isLetters(value) {
+		v.add(field, fmt.Sprintf("%q is not an ISO 3166 country code", value))
+	}
+}
+
+func (v *validator) amount(field string, amount Amount, currencyCode string) {
+	if amount.Sign() <= 0 {
+		v.add(field, "must be positive")
+		return
+	}
+	if _, err := amount.ForCurrency(currencyCode); err != nil {
+		v.add(field, fmt.Sprintf("%s has more than %d decimals", amount, CurrencyScale(currencyCode)))
+	}
+}
+
+func (v *validator) auth(field string, auth Auth) {
+	v.required(field+".CorporateID", auth.CorporateID)
+	v.required(field+".AccessCode", auth.AccessCode)
+	v.required(field+".BranchCode", auth.BranchCode)
+	v.required(field+".UserID", auth.UserID)
+	v.required(field+".LocalID", auth.LocalID)
+}
+
+//person checks the details of a FIRe sender, FirstName holds the Name of a beneficiary
+func (v *validator) person(field string, p person) {
+	name := ".FirstName"
+	if strings.HasSuffix(field, "BeneficiaryDetails") {
+		name = ".Name"
+	}
+	v.required(field+name, p.FirstName)
+	v.maxLength(field+name, p.FirstName, maxFIReTextLength)
+	v.maxLength(field+".LastName", p.LastName, maxFIReTextLength)
+	if p.DateOfBirth != "" {
+		v.date(field+".DateOfBirth", p.DateOfBirth)
+	}
+	v.required(field+".Address1", p.Address1)
+	v.maxLength(field+".Address1", p.Address1, maxFIReTextLength)
+	v.maxLength(field+".Address2", p.Address2, maxFIReTextLength)
+	v.required(field+".City", p.City)
+	v.maxLength(field+".City", p.City, maxFIReTextLength)
+	v.maxLength(field+".StateID", p.StateID, maxFIReTextLength)
+	v.maxLength(field+".PostalCode", p.PostalCode, maxFIRePostalCodeLength)
+	v.countryID(field+".CountryID", p.CountryID)
+	v.maxLength(field+".Mobile", p.Mobile, maxFIReMobileLength)
+	v.numeric(field+".Mobile", strings.TrimPrefix(p.Mobile, "+"))
+	v.required(field+".IdentificationType", string(p.IdentificationType))
+	v.maxLength(field+".IdentificationType", string(p.IdentificationType), maxFIReTextLength)
+	v.required(field+".IdentificationNumber", p.IdentificationNumber)
+	v.maxLength(field+".IdentificationNumber", p.IdentificationNumber, maxFIReTextLength)
+}
+
+func (v *validator) beneficiary(field string, p person, nationalityID, occupation string) {
+	v.person(field, p)
+	if nationalityID != "" {
+		v.countryID(field+".NationalityID", nationalityID)
+	}
+	v.maxLength(field+".Occupation", occupation, maxFIReTextLength)
+}
+
+func (v *validator) bankCode(field string, codeType BankCodeType, value string) {
+	v.code(field+".BankCodeType", string(codeType), 3, BankCodeTypes())
+	v.required(field+".BankCodeValue", value)
+	v.maxLength(field+".BankCodeValue", value, maxFIReBankCodeLength)
+	v.alphanumeric(field+".BankCodeValue", value)
+}
+
+func (v *validator) fireTransaction(field, currencyID string, amount Amount, purposeCode PurposeCode, description1, description2 string, detailOfCharges DetailOfCharges, sourceOfFund string) {
+	v.currencyCode(field+".CurrencyID", currencyID)
+	v.amount(field+".Amount", amount, currencyID)
+	v.required(field+".PurposeCode", string(purposeCode))
+	v.maxLength(field+".PurposeCode", string(purposeCode), maxFIRePurposeCodeLength)
+	v.maxLength(field+".Description1", description1, maxFIReTextLength)
+	v.maxLength(field+".Description2", description2, maxFIReTextLength)
+	v.oneOf(field+".DetailOfCharges", string(detailOfCharges), enumStrings(DetailsOfCharges())...)
+	v.maxLength(field+".SourceOfFund", sourceOfFund, maxFIReTextLength)
+}
+
+func (v *validator) formNumber(field, value string) {
+	v.required(field, value)
+	v.maxLength(field, value, maxFIReFormNumberLength)
+	v.alphanumeric(field, value)
+}
+
+func isLetters(s string) bool {
+	for _, ch := range s {
+		if (ch < 'A' || ch > 'Z') && (ch < 'a' || ch > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+func enumStrings(values []EnumValue) []string {
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		strs = append(strs, value.Value)
+	}
+	return strs
+}
@@ -0,0 +1,359 @@
+package bca
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+//validatable is implemented by every request message
+type validatable interface {
+	Validate() error
+}
+
+var validAuth = Auth{CorporateID: "BCAAPI2016", AccessCode: "access", BranchCode: "0001", UserID: "USER1", LocalID: "40115"}
+
+func validTeleTransferAccountRequest() TeleTransferAccountRequest {
+	return TeleTransferAccountRequest{
+		Authentication: validAuth,
+		SenderDetails: SenderAccountRequest{
+			FirstName: "Budi", Address1: "Jl. Sudirman 1", City: "Jakarta", CountryID: "ID",
+			IdentificationType: "KTP", IdentificationNumber: "3171000000000001", AccountNumber: "0201245680",
+		},
+		BeneficiaryDetails: BeneficiaryAccountRequest{
+			Name: "Siti Aminah", Address1: "Jl. Thamrin 2", City: "Jakarta", CountryID: "ID",
+			IdentificationType: "KTP", IdentificationNumber: "3171000000000002",
+			BankCodeType: BankCodeTypeBIC, BankCodeValue: "BRINIDJA", AccountNumber: "8888801234",
+		},
+		TransactionDetails: TransactionAccountRequest{
+			CurrencyID: "IDR", Amount: MustParseAmount("100000"), PurposeCode: "011",
+			DetailOfCharges: DetailOfChargesSha, FormNumber: "FORM1",
+		},
+	}
+}
+
+func validTeleTransferCashTransferRequest() TeleTransferCashTransferRequest {
+	return TeleTransferCashTransferRequest{
+		Authentication: validAuth,
+		SenderDetails: SenderTeleTransferCashTransferRequest{
+			FirstName: "Budi", Address1: "Jl. Sudirman 1", City: "Jakarta", CountryID: "ID",
+			IdentificationType: "KTP", IdentificationNumber: "3171000000000001",
+		},
+		BeneficiaryDetails: BeneficiaryTeleTransferCashTransferRequest{
+			Name: "Siti Aminah", Address1: "Jl. Thamrin 2", City: "Jakarta", CountryID: "ID",
+			IdentificationType: "KTP", IdentificationNumber: "3171000000000002",
+		},
+		TransactionDetails: TransactionTeleTransferCashTransferRequest{
+			PIN: "123456", CurrencyID: "IDR", Amount: MustParseAmount("250000"), PurposeCode: "011",
+			DetailOfCharges: DetailOfChargesSha, FormNumber: "FORM1",
+		},
+	}
+}
+
+func validDomesticFundTransferRequest() DomesticFundTransferRequest {
+	return DomesticFundTransferRequest{
+		TransactionID:            "00000001",
+		TransactionDate:          "2026-10-18",
+		ReferenceID:              "12345/PO/2016",
+		SourceAccountNumber:      "0201245680",
+		BeneficiaryAccountNumber: "8888801234",
+		BeneficiaryBankCode:      "BRINIDJA",
+		BeneficiaryName:          "Siti Aminah",
+		Amount:                   MustParseAmount("100000"),
+		TransferType:             TransferTypeONL,
+		CurrencyCode:             "IDR",
+	}
+}
+
+//invalidFields returns the distinct fields of the FieldError of err
+func invalidFields(err error) string {
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) {
+		return ""
+	}
+	var fields []string
+	seen := map[string]bool{}
+	for _, fieldError := range validationErr {
+		if !seen[fieldError.Field] {
+			seen[fieldError.Field] = true
+			fields = append(fields, fieldError.Field)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestValidate(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		request validatable
+		// wantFields are the invalid fields, space separated, empty for a valid request
+		wantFields string
+	}{
+		{
+			name:    "balance information",
+			request: BalanceInformationRequest{CorporateID: "BCAAPI2016", AccountNumber: "0201245680,0201245681"},
+		},
+		{
+			name:       "balance information with a short account number",
+			request:    BalanceInformationRequest{AccountNumber: "0201245680,123"},
+			wantFields: "CorporateID AccountNumber",
+		},
+		{
+			name:       "balance information of too many accounts",
+			request:    BalanceInformationRequest{CorporateID: "BCAAPI2016", AccountNumber: strings.Repeat("0201245680,", MaxBalanceInformationAccounts) + "0201245680"},
+			wantFields: "AccountNumber",
+		},
+		{
+			name:    "account statement",
+			request: AccountStatementRequest{CorporateID: "BCAAPI2016", AccountNumber: "0201245680", StartDate: day(1), EndDate: day(31)},
+		},
+		{
+			name:       "account statement longer than 31 days",
+			request:    AccountStatementRequest{CorporateID: "BCAAPI2016", AccountNumber: "0201245680", StartDate: day(1), EndDate: day(31).AddDate(0, 0, 1)},
+			wantFields: "EndDate",
+		},
+		{
+			name:       "account statement ending before it starts",
+			request:    AccountStatementRequest{CorporateID: "BCAAPI2016", AccountNumber: "020124568A", StartDate: day(2), EndDate: day(1)},
+			wantFields: "AccountNumber EndDate",
+		},
+		{
+			name: "fund transfer",
+			request: FundTransferRequest{
+				CorporateID: "BCAAPI2016", SourceAccountNumber: "0201245680", TransactionID: "00000001", TransactionDate: "2026-10-18",
+				ReferenceID: "12345/PO/2016", CurrencyCode: "IDR", Amount: MustParseAmount("100000"), BeneficiaryAccountNumber: "0201245681",
+			},
+		},
+		{
+			name: "fund transfer in another currency",
+			request: FundTransferRequest{
+				CorporateID: "BCAAPI2016", SourceAccountNumber: "0201245680", TransactionID: "123456789", TransactionDate: "18/10/2026",
+				ReferenceID: "12345 PO", CurrencyCode: "USD", Amount: MustParseAmount("100.001"), BeneficiaryAccountNumber: "0201245681",
+				Remark1: strings.Repeat("x", maxRemarkLength+1),
+			},
+			wantFields: "TransactionID TransactionDate ReferenceID CurrencyCode Amount Remark1",
+		},
+		{
+			name:    "online domestic transfer",
+			request: validDomesticFundTransferRequest(),
+		},
+		{
+			name: "clearing domestic transfer without customer details",
+			request: func() DomesticFundTransferRequest {
+				r := validDomesticFundTransferRequest()
+				r.TransferType = TransferTypeLLG
+				return r
+			}(),
+			wantFields: "BeneficiaryCustType BeneficiaryCustResidence",
+		},
+		{
+			name: "online domestic transfer above the limit",
+			request: func() DomesticFundTransferRequest {
+				r := validDomesticFundTransferRequest()
+				r.Amount = MaxOnlineTransferAmount.Add(MustParseAmount("1"))
+				r.BeneficiaryEmail = "siti"
+				return r
+			}(),
+			wantFields: "Amount BeneficiaryEmail",
+		},
+		{
+			name:    "offline account statement",
+			request: AccountStatementOfflineRequest{AccountNumber: "0201245680", StartDate: day(1), EndDate: day(7)},
+		},
+		{
+			name:       "offline account statement longer than 7 days",
+			request:    AccountStatementOfflineRequest{AccountNumber: "0201245680", StartDate: day(1), EndDate: day(8)},
+			wantFields: "EndDate",
+		},
+		{
+			name:    "transfer status",
+			request: InquiryTransferStatusRequest{TransactionID: "00000001", TransactionDate: day(18), TransferType: TransferTypeBCA},
+		},
+		{
+			name:       "empty transfer status",
+			request:    InquiryTransferStatusRequest{},
+			wantFields: "TransactionID TransactionDate TransferType",
+		},
+		{
+			name:    "domestic account",
+			request: InquiryDomesticAccountRequest{BeneficiaryAccountNumber: "8888801234", BeneficiaryBankCode: "BRINIDJA"},
+		},
+		{
+			name:       "domestic account with a malformed bank code",
+			request:    InquiryDomesticAccountRequest{BeneficiaryAccountNumber: "8888-801234", BeneficiaryBankCode: "BRI.IDJA"},
+			wantFields: "BeneficiaryAccountNumber BeneficiaryBankCode",
+		},
+		{
+			name:    "transfer to an account",
+			request: validTeleTransferAccountRequest(),
+		},
+		{
+			name: "transfer to an account without authentication",
+			request: func() TeleTransferAccountRequest {
+				r := validTeleTransferAccountRequest()
+				r.Authentication = Auth{}
+				r.TransactionDetails.DetailOfCharges = ""
+				return r
+			}(),
+			wantFields: "Authentication.CorporateID Authentication.AccessCode Authentication.BranchCode Authentication.UserID Authentication.LocalID TransactionDetails.DetailOfCharges",
+		},
+		{
+			name: "transfer to an account with an incomplete beneficiary",
+			request: func() TeleTransferAccountRequest {
+				r := validTeleTransferAccountRequest()
+				r.BeneficiaryDetails.Name = ""
+				r.BeneficiaryDetails.CountryID = "IDN"
+				r.BeneficiaryDetails.IdentificationType = ""
+				r.BeneficiaryDetails.BankCodeType = ""
+				return r
+			}(),
+			wantFields: "BeneficiaryDetails.Name BeneficiaryDetails.CountryID BeneficiaryDetails.IdentificationType BeneficiaryDetails.BankCodeType",
+		},
+		{
+			name:    "account inquiry",
+			request: InquiryAccountRequest{Authentication: validAuth, BeneficiaryDetails: BeneficiaryInquiryAccountRequest{BankCodeType: BankCodeTypeBIC, BankCodeValue: "BRINIDJA", AccountNumber: "8888801234"}},
+		},
+		{
+			name:       "account inquiry without bank code",
+			request:    InquiryAccountRequest{Authentication: validAuth, BeneficiaryDetails: BeneficiaryInquiryAccountRequest{AccountNumber: "8888801234"}},
+			wantFields: "BeneficiaryDetails.BankCodeType BeneficiaryDetails.BankCodeValue",
+		},
+		{
+			name:    "account balance",
+			request: InquiryAccountBalanceRequest{Authentication: validAuth, FIDetails: FIInquiryAccountBalanceRequest{AccountNumber: "0201245680"}},
+		},
+		{
+			name:       "account balance of a malformed account",
+			request:    InquiryAccountBalanceRequest{Authentication: validAuth, FIDetails: FIInquiryAccountBalanceRequest{AccountNumber: "02012A"}},
+			wantFields: "FIDetails.AccountNumber",
+		},
+		{
+			name:    "transaction inquiry",
+			request: InquiryTransactionRequest{Authentication: validAuth, TransactionDetails: TransactionInquiryTransactionRequest{InquiryBy: InquiryByFormNumber, InquiryValue: "FORM1"}},
+		},
+		{
+			name:       "transaction inquiry by an unknown key",
+			request:    InquiryTransactionRequest{Authentication: validAuth, TransactionDetails: TransactionInquiryTransactionRequest{InquiryBy: "X"}},
+			wantFields: "TransactionDetails.InquiryBy TransactionDetails.InquiryValue",
+		},
+		{
+			name:    "cash transfer",
+			request: validTeleTransferCashTransferRequest(),
+		},
+		{
+			name: "cash transfer without PIN and purpose",
+			request: func() TeleTransferCashTransferRequest {
+				r := validTeleTransferCashTransferRequest()
+				r.SenderDetails.DateOfBirth = "1980-13-01"
+				r.TransactionDetails.PIN = "12345a"
+				r.TransactionDetails.PurposeCode = ""
+				r.TransactionDetails.FormNumber = "FORM-1"
+				return r
+			}(),
+			wantFields: "SenderDetails.DateOfBirth TransactionDetails.PIN TransactionDetails.PurposeCode TransactionDetails.FormNumber",
+		},
+		{
+			name: "cash transfer amendment",
+			request: TeleTransferAmendCashTransferRequest{
+				Authentication:     validAuth,
+				AmendmentDetails:   AmendmentTeleTransferAmendCashTransfer{BeneficiaryDetails: BeneficiaryTeleTransferAmendCashTransfer{Name: "Siti Aminah"}},
+				TransactionDetails: Transaction2TTAmendCashTransfer{FormNumber: "FORM1"},
+			},
+		},
+		{
+			name: "cash transfer amendment with a long name",
+			request: TeleTransferAmendCashTransferRequest{
+				Authentication:   validAuth,
+				AmendmentDetails: AmendmentTeleTransferAmendCashTransfer{BeneficiaryDetails: BeneficiaryTeleTransferAmendCashTransfer{Name: strings.Repeat("x", maxFIReTextLength+1)}},
+			},
+			wantFields: "AmendmentDetails.BeneficiaryDetails.Name TransactionDetails.FormNumber",
+		},
+		{
+			name: "cash transfer cancellation",
+			request: TeleTransferCancelCashTransferRequest{
+				Authentication:     validAuth,
+				TransactionDetails: TransactionTeleTransferCancelCashTransferRequest{FormNumber: "FORM1", Amount: MustParseAmount("250000"), CurrencyID: "IDR"},
+			},
+		},
+		{
+			name: "cash transfer cancellation with decimals in yen",
+			request: TeleTransferCancelCashTransferRequest{
+				Authentication:     validAuth,
+				TransactionDetails: TransactionTeleTransferCancelCashTransferRequest{FormNumber: "FORM1", Amount: MustParseAmount("2500.50"), CurrencyID: "JPY"},
+			},
+			wantFields: "TransactionDetails.Amount",
+		},
+		{
+			name:    "foreign exchange rate of every currency",
+			request: ForeignExchangeRateRequest{},
+		},
+		{
+			name:    "foreign exchange rates",
+			request: ForeignExchangeRateRequest{CurrencyCode: "USD,JPY", RateType: "erate,bn"},
+		},
+		{
+			name:       "foreign exchange rate of unknown codes",
+			request:    ForeignExchangeRateRequest{CurrencyCode: "USD,usd", RateType: "erate,xx"},
+			wantFields: "CurrencyCode RateType",
+		},
+		{
+			name:    "VA payment status by customer number",
+			request: InquiryStatusPaymentRequest{CompanyCode: "12345", CustomerNumber: "0001"},
+		},
+		{
+			name:    "VA payment status by request ID",
+			request: InquiryStatusPaymentRequest{CompanyCode: "12345", RequestID: "201507131507262221400000001975"},
+		},
+		{
+			name:       "VA payment status without customer number or request ID",
+			request:    InquiryStatusPaymentRequest{CompanyCode: "1234A"},
+			wantFields: "CompanyCode CustomerNumber",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if got := invalidFields(err); got != tt.wantFields {
+				t.Errorf("Validate() = %v, want invalid fields %q", err, tt.wantFields)
+			}
+			if tt.wantFields != "" && !errors.Is(err, ErrValidation) {
+				t.Errorf("Validate() = %v, want ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestValidateStrictEnums(t *testing.T) {
+	t.Cleanup(func() { SetStrictEnums(false) })
+
+	inquiry := InquiryAccountRequest{Authentication: validAuth, BeneficiaryDetails: BeneficiaryInquiryAccountRequest{BankCodeType: "NEW", BankCodeValue: "BRINIDJA", AccountNumber: "8888801234"}}
+	domestic := InquiryDomesticAccountRequest{BeneficiaryAccountNumber: "8888801234", BeneficiaryBankCode: "NEWBIDJA"}
+
+	tests := []struct {
+		name       string
+		request    validatable
+		strict     bool
+		wantFields string
+	}{
+		{name: "unknown bank code type", request: inquiry},
+		{name: "unknown bank code type in strict mode", request: inquiry, strict: true, wantFields: "BeneficiaryDetails.BankCodeType"},
+		{name: "bank missing from the directory", request: domestic},
+		{name: "bank missing from the directory in strict mode", request: domestic, strict: true, wantFields: "BeneficiaryBankCode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStrictEnums(tt.strict)
+			err := tt.request.Validate()
+			if got := invalidFields(err); got != tt.wantFields {
+				t.Errorf("Validate() = %v, want invalid fields %q", err, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package bca
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//DefaultMaxSkew is the default allowed difference between X-BCA-Timestamp and the local clock
+const DefaultMaxSkew = 5 * time.Minute
+
+//DefaultMaxBodySize is the default largest body read by SignatureVerifier.Verify
+const DefaultMaxBodySize = 1 << 20
+
+//Errors returned by SignatureVerifier.Verify
+var (
+	ErrMissingSignature  = errors.New("bca: missing X-BCA-Key, X-BCA-Timestamp or X-BCA-Signature header")
+	ErrUnknownAPIKey     = errors.New("bca: unknown X-BCA-Key")
+	ErrInvalidTimestamp  = errors.New("bca: X-BCA-Timestamp is malformed or outside the allowed skew")
+	ErrSignatureMismatch = errors.New("bca: X-BCA-Signature does not match the request")
+	ErrReplayedSignature = errors.New("bca: X-BCA-Signature was already used")
+	ErrBodyTooLarge      = errors.New("bca: request body is too large")
+)
+
+//SignatureVerifier verifies the X-BCA-Key, X-BCA-Timestamp and X-BCA-Signature headers of inbound requests from BCA,
+//such as Virtual Account bill presentment and payment flag callbacks
+type SignatureVerifier struct {
+	APIKey    string
+	APISecret string
+	// MaxSkew is the allowed difference between X-BCA-Timestamp and Now, DefaultMaxSkew when 0
+	MaxSkew time.Duration
+	// MaxBodySize is the largest body read to verify the signature, DefaultMaxBodySize when 0
+	MaxBodySize int64
+	// Now returns the current time, time.Now when nil
+	Now func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+	// expiries holds the signatures of seen ordered by expiry
+	expiries signatureExpiries
+}
+
+type signatureExpiry struct {
+	signature string
+	expiry    time.Time
+}
+
+//signatureExpiries is a heap.Interface of signatures ordered by expiry
+type signatureExpiries []signatureExpiry
+
+func (e signatureExpiries) Len() int            { return len(e) }
+func (e signatureExpiries) Less(i, j int) bool  { return e[i].expiry.Before(e[j].expiry) }
+func (e signatureExpiries) Swap(i, j int)       { e[i], e[j] = e[j], e[i] }
+func (e *signatureExpiries) Push(x interface{}) { *e = append(*e, x.(signatureExpiry)) }
+
+func (e *signatureExpiries) Pop() interface{} {
+	old := *e
+	last := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return last
+}
+
+//NewSignatureVerifier is used to initialize new SignatureVerifier
+func NewSignatureVerifier(apiKey, apiSecret string) *SignatureVerifier {
+	return &SignatureVerifier{
+		APIKey:      apiKey,
+		APISecret:   apiSecret,
+		MaxSkew:     DefaultMaxSkew,
+		MaxBodySize: DefaultMaxBodySize,
+	}
+}
+
+//Verify checks the signature headers of req against its method, sorted query, bearer token, body and timestamp.
+//A signature is only accepted once within the skew window. The body of req can still be read afterwards
+func (v *SignatureVerifier) Verify(req *http.Request) error {
+	apiKey := req.Header.Get("X-BCA-Key")
+	timestamp := req.Header.Get("X-BCA-Timestamp")
+	signature := req.Header.Get("X-BCA-Signature")
+	if apiKey == "" || timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+	if !hmac.Equal([]byte(apiKey), []byte(v.APIKey)) {
+		return ErrUnknownAPIKey
+	}
+
+	signedAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	now := v.now()
+	skew := now.Sub(signedAt)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > v.maxSkew() {
+		return ErrInvalidTimestamp
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(io.LimitReader(req.Body, v.maxBodySize()+1))
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		if int64(len(body)) > v.maxBodySize() {
+			return ErrBodyTooLarge
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	accessToken := strings.TrimSpace(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer"))
+	expected := generateSignature(v.APISecret, req.Method, req.URL.RequestURI(), accessToken, string(body), timestamp)
+	// The signature is hexadecimal, an upper case copy is the same signature
+	signature = strings.ToLower(signature)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrSignatureMismatch
+	}
+
+	return v.remember(signature, signedAt.Add(v.maxSkew()), now)
+}
+
+//Middleware returns a http.Handler that only passes verified requests to next.
+//Rejected requests are answered by onError, or with 401 and a BCA error message when onError is nil
+func (v *SignatureVerifier) Middleware(next http.Handler, onError func(w http.ResponseWriter, r *http.Request, err error)) http.Handler {
+	if onError == nil {
+		onError = writeVerificationError
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			onError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+//remember records signature until expiry and fails if it was already seen.
+//Only the expired signatures are visited to forget them, in the order they expire
+func (v *SignatureVerifier) remember(signature string, expiry, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.seen == nil {
+		v.seen = map[string]time.Time{}
+	}
+	for len(v.expiries) > 0 && now.After(v.expiries[0].expiry) {
+		delete(v.seen, heap.Pop(&v.expiries).(signatureExpiry).signature)
+	}
+
+	if _, ok := v.seen[signature]; ok {
+		return ErrReplayedSignature
+	}
+	v.seen[signature] = expiry
+	heap.Push(&v.expiries, signatureExpiry{signature: signature, expiry: expiry})
+	return nil
+}
+
+func (v *SignatureVerifier) now() time.Time {
+	if v.Now == nil {
+		return time.Now()
+	}
+	return v.Now()
+}
+
+func (v *SignatureVerifier) maxBodySize() int64 {
+	if v.MaxBodySize <= 0 {
+		return DefaultMaxBodySize
+	}
+	return v.MaxBodySize
+}
+
+func (v *SignatureVerifier) maxSkew() time.Duration {
+	if v.MaxSkew <= 0 {
+		return DefaultMaxSkew
+	}
+	return v.MaxSkew
+}
+
+func writeVerificationError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(&Error{
+		ErrorCode: ErrInvalidSignature.ErrorCode,
+		ErrorMessage: ErrorLang{
+			Indonesian: "Signature tidak valid",
+			English:    strings.TrimPrefix(err.Error(), "bca: "),
+		},
+	})
+}
@@ -0,0 +1,98 @@
+package bca
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignatureVerifierVerify(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	timestamp := now.Format("2006-01-02T15:04:05.000Z07:00")
+	body := `{"CompanyCode":"12345","RequestID":"201507131507262221400000001975"}`
+	signature := generateSignature("secret", "POST", "/va/payments", "token", body, timestamp)
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		timestamp string
+		signature string
+		body      string
+		wantErr   error
+	}{
+		{name: "valid", apiKey: "key", timestamp: timestamp, signature: signature, body: body},
+		{name: "replayed", apiKey: "key", timestamp: timestamp, signature: signature, body: body, wantErr: ErrReplayedSignature},
+		{name: "replayed in upper case", apiKey: "key", timestamp: timestamp, signature: strings.ToUpper(signature), body: body, wantErr: ErrReplayedSignature},
+		{name: "missing signature", apiKey: "key", timestamp: timestamp, body: body, wantErr: ErrMissingSignature},
+		{name: "unknown key", apiKey: "other", timestamp: timestamp, signature: signature, body: body, wantErr: ErrUnknownAPIKey},
+		{name: "malformed timestamp", apiKey: "key", timestamp: "18/10/2026", signature: signature, body: body, wantErr: ErrInvalidTimestamp},
+		{name: "stale timestamp", apiKey: "key", timestamp: now.Add(-time.Hour).Format(time.RFC3339), signature: signature, body: body, wantErr: ErrInvalidTimestamp},
+		{name: "tampered body", apiKey: "key", timestamp: timestamp, signature: signature, body: strings.Replace(body, "12345", "54321", 1), wantErr: ErrSignatureMismatch},
+		{name: "body too large", apiKey: "key", timestamp: timestamp, signature: signature, body: strings.Repeat(" ", 2048), wantErr: ErrBodyTooLarge},
+	}
+
+	v := NewSignatureVerifier("key", "secret")
+	v.MaxBodySize = 1024
+	v.Now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/va/payments", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("X-BCA-Key", tt.apiKey)
+			req.Header.Set("X-BCA-Timestamp", tt.timestamp)
+			req.Header.Set("X-BCA-Signature", tt.signature)
+
+			if err := v.Verify(req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifierForgetsExpiredSignatures(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	v := NewSignatureVerifier("key", "secret")
+
+	if err := v.remember("abc", now.Add(time.Minute), now); err != nil {
+		t.Fatalf("remember() = %v", err)
+	}
+	if err := v.remember("abc", now.Add(time.Minute), now); err != ErrReplayedSignature {
+		t.Errorf("remember() within expiry = %v, want ErrReplayedSignature", err)
+	}
+	if err := v.remember("abc", now.Add(3*time.Minute), now.Add(2*time.Minute)); err != nil {
+		t.Errorf("remember() after expiry = %v, want nil", err)
+	}
+}
+
+func TestSignatureVerifierForgetsInExpiryOrder(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	v := NewSignatureVerifier("key", "secret")
+
+	// Signatures arrive out of the order of their timestamps, within the skew window
+	expiries := map[string]time.Duration{"a": 3 * time.Minute, "b": time.Minute, "c": 2 * time.Minute}
+	for _, signature := range []string{"a", "b", "c"} {
+		if err := v.remember(signature, now.Add(expiries[signature]), now); err != nil {
+			t.Fatalf("remember(%s) = %v", signature, err)
+		}
+	}
+
+	if err := v.remember("d", now.Add(5*time.Minute), now.Add(90*time.Second)); err != nil {
+		t.Fatalf("remember(d) = %v", err)
+	}
+	if _, ok := v.seen["b"]; ok || len(v.seen) != 3 || len(v.expiries) != 3 {
+		t.Errorf("seen = %v, want b forgotten and a, c, d remembered", v.seen)
+	}
+	if err := v.remember("a", now.Add(3*time.Minute), now.Add(90*time.Second)); err != ErrReplayedSignature {
+		t.Errorf("remember(a) before its expiry = %v, want ErrReplayedSignature", err)
+	}
+
+	if err := v.remember("e", now.Add(6*time.Minute), now.Add(4*time.Minute)); err != nil {
+		t.Fatalf("remember(e) = %v", err)
+	}
+	if len(v.seen) != 2 || len(v.expiries) != 2 {
+		t.Errorf("seen = %v, want d and e remembered", v.seen)
+	}
+}